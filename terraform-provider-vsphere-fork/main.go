@@ -1,11 +1,16 @@
 package main
 
 import (
+	"os"
+
 	"github.com/hashicorp/terraform-plugin-sdk/plugin"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate-import" {
+		os.Exit(vsphere.RunImportGenerator(os.Args[2:], os.Stdout, os.Stderr))
+	}
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: vsphere.Provider})
 }
@@ -3,23 +3,30 @@ package vsphere
 import (
 	"context"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/vmware/govmomi/vapi/rest"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/propertycache"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/retry"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/pbm"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/session/cache"
+	"github.com/vmware/govmomi/sts"
 	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/debug"
@@ -40,6 +47,10 @@ type VSphereClient struct {
 
 	// The REST client used for tags and content library.
 	restClient *rest.Client
+
+	// How failed plan-time preflight checks are handled. One of the
+	// preflight.Mode* values.
+	preflightChecks string
 }
 
 // TagsManager returns the embedded tags manager used for tags, after determining
@@ -73,20 +84,45 @@ func (c *VSphereClient) TagsManager() (*tags.Manager, error) {
 	return tags.NewManager(c.restClient), nil
 }
 
+// The following are the authentication methods that the provider supports.
+// Only one can be configured at a time.
+const (
+	authMethodPassword      = "password"
+	authMethodSAMLToken     = "saml_token"
+	authMethodCertificate   = "client_certificate"
+	authMethodSessionCookie = "session_cookie"
+	authMethodCloneTicket   = "clone_ticket"
+)
+
 // Config holds the provider configuration, and delivers a populated
 // VSphereClient based off the contained settings.
 type Config struct {
-	InsecureFlag    bool
-	Debug           bool
-	Persist         bool
-	User            string
-	Password        string
-	VSphereServer   string
-	DebugPath       string
-	DebugPathRun    string
-	VimSessionPath  string
-	RestSessionPath string
-	KeepAlive       int
+	InsecureFlag          bool
+	Debug                 bool
+	Persist               bool
+	User                  string
+	Password              string
+	SAMLToken             string
+	ClientCertificatePath string
+	ClientKeyPath         string
+	SessionCookie         string
+	CloneTicket           string
+	RestSessionID         string
+	VSphereServer         string
+	DebugPath             string
+	DebugPathRun          string
+	VimSessionPath        string
+	RestSessionPath       string
+	KeepAlive             int
+	APIMaxRetries         int
+	APIRetryMinDelay      int
+	APIRetryMaxDelay      int
+	PropertyCache         bool
+	PreflightChecks       string
+
+	// The SAML token signer used for token and certificate authentication.
+	// This is shared between the SOAP and REST logins.
+	signer *sts.Signer
 }
 
 // NewConfig returns a new Config from a supplied ResourceData.
@@ -105,22 +141,78 @@ func NewConfig(d *schema.ResourceData) (*Config, error) {
 	}
 
 	c := &Config{
-		User:            d.Get("user").(string),
-		Password:        d.Get("password").(string),
-		InsecureFlag:    d.Get("allow_unverified_ssl").(bool),
-		VSphereServer:   server,
-		Debug:           d.Get("client_debug").(bool),
-		DebugPathRun:    d.Get("client_debug_path_run").(string),
-		DebugPath:       d.Get("client_debug_path").(string),
-		Persist:         d.Get("persist_session").(bool),
-		VimSessionPath:  d.Get("vim_session_path").(string),
-		RestSessionPath: d.Get("rest_session_path").(string),
-		KeepAlive:       d.Get("vim_keep_alive").(int),
+		User:                  d.Get("user").(string),
+		Password:              d.Get("password").(string),
+		SAMLToken:             d.Get("saml_token").(string),
+		ClientCertificatePath: d.Get("client_certificate_path").(string),
+		ClientKeyPath:         d.Get("client_key_path").(string),
+		SessionCookie:         d.Get("session_cookie").(string),
+		CloneTicket:           d.Get("clone_ticket").(string),
+		RestSessionID:         d.Get("rest_session_id").(string),
+		InsecureFlag:          d.Get("allow_unverified_ssl").(bool),
+		VSphereServer:         server,
+		Debug:                 d.Get("client_debug").(bool),
+		DebugPathRun:          d.Get("client_debug_path_run").(string),
+		DebugPath:             d.Get("client_debug_path").(string),
+		Persist:               d.Get("persist_session").(bool),
+		VimSessionPath:        d.Get("vim_session_path").(string),
+		RestSessionPath:       d.Get("rest_session_path").(string),
+		KeepAlive:             d.Get("vim_keep_alive").(int),
+		APIMaxRetries:         d.Get("api_max_retries").(int),
+		APIRetryMinDelay:      d.Get("api_retry_min_delay").(int),
+		APIRetryMaxDelay:      d.Get("api_retry_max_delay").(int),
+		PropertyCache:         d.Get("property_cache").(bool),
+		PreflightChecks:       d.Get("preflight_checks").(string),
+	}
+
+	if _, err := c.authMethod(); err != nil {
+		return nil, err
 	}
 
 	return c, nil
 }
 
+// authMethod returns the authentication method in use by the configuration,
+// validating that exactly one method has been configured.
+//
+// A client certificate on its own is used to request a holder-of-key token
+// from vCenter SSO as a solution user. When supplied with a SAML token, the
+// certificate is used to sign requests made with a holder-of-key token
+// instead.
+func (c *Config) authMethod() (string, error) {
+	if (c.ClientCertificatePath == "") != (c.ClientKeyPath == "") {
+		return "", errors.New("client_certificate_path and client_key_path must be set together")
+	}
+
+	var methods []string
+	if c.User != "" || c.Password != "" {
+		methods = append(methods, authMethodPassword)
+	}
+	switch {
+	case c.SAMLToken != "":
+		methods = append(methods, authMethodSAMLToken)
+	case c.ClientCertificatePath != "":
+		methods = append(methods, authMethodCertificate)
+	}
+	if c.SessionCookie != "" {
+		methods = append(methods, authMethodSessionCookie)
+	}
+	if c.CloneTicket != "" {
+		methods = append(methods, authMethodCloneTicket)
+	}
+
+	switch {
+	case len(methods) < 1:
+		return "", errors.New("one of user and password, saml_token, client_certificate_path and client_key_path, session_cookie, or clone_ticket must be provided")
+	case len(methods) > 1:
+		return "", fmt.Errorf("only one authentication method can be configured, got %s", strings.Join(methods, ", "))
+	}
+	if methods[0] == authMethodPassword && c.User == "" {
+		return "", errors.New("user must be provided with password")
+	}
+	return methods[0], nil
+}
+
 // vimURL returns a URL to pass to the VIM SOAP client.
 func (c *Config) vimURL() (*url.URL, error) {
 	u, err := url.Parse("https://" + c.VSphereServer + "/sdk")
@@ -128,14 +220,18 @@ func (c *Config) vimURL() (*url.URL, error) {
 		return nil, fmt.Errorf("Error parse url: %s", err)
 	}
 
-	u.User = url.UserPassword(c.User, c.Password)
+	if c.User != "" {
+		u.User = url.UserPassword(c.User, c.Password)
+	}
 
 	return u, nil
 }
 
 // Client returns a new client for accessing VMWare vSphere.
 func (c *Config) Client() (*VSphereClient, error) {
-	client := new(VSphereClient)
+	client := &VSphereClient{
+		preflightChecks: c.PreflightChecks,
+	}
 
 	u, err := c.vimURL()
 	if err != nil {
@@ -153,12 +249,32 @@ func (c *Config) Client() (*VSphereClient, error) {
 		return nil, err
 	}
 
+	client.vimClient.Client.RoundTripper = retry.NewSOAPRoundTripper(client.vimClient.Client.RoundTripper, client.vimClient.ServiceContent.PropertyCollector, c.retryPolicy())
+	if c.PropertyCache {
+		propertycache.Enable(client.vimClient.Client)
+	}
+
 	log.Printf("[DEBUG] VMWare vSphere Client configured for URL: %s", c.VSphereServer)
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	s := new(cache.Session)
-	if isEligibleRestEndpoint(client.vimClient) {
+	method, err := c.authMethod()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case (method == authMethodSessionCookie || method == authMethodCloneTicket) && c.RestSessionID == "":
+		// A SOAP session cookie or clone ticket cannot be exchanged for a REST
+		// session, so tags and content library are not available unless a REST
+		// session ID has been supplied as well.
+		log.Printf("[DEBUG] No REST session ID supplied with %s authentication, skipping REST client setup", method)
+	case isEligibleRestEndpoint(client.vimClient):
+		if method == authMethodSAMLToken || method == authMethodCertificate {
+			if _, err := c.tokenSigner(ctx, client.vimClient.Client); err != nil {
+				return nil, err
+			}
+		}
 		s, err = c.restURL()
 		if err != nil {
 			return nil, err
@@ -167,7 +283,8 @@ func (c *Config) Client() (*VSphereClient, error) {
 		if err != nil {
 			return nil, err
 		}
-	} else {
+		client.restClient.Client.Transport = retry.NewHTTPRoundTripper(client.restClient.Client.Transport, c.retryPolicy())
+	default:
 		// Just print a log message so that we know that tags are not available on
 		// this connection.
 		log.Printf("[DEBUG] Connected endpoint does not support REST API (%s)", viapi.ParseVersionFromClient(client.vimClient))
@@ -198,15 +315,24 @@ func (c *Config) Client() (*VSphereClient, error) {
 	return client, nil
 }
 
+// retryPolicy returns the policy used to retry API calls that fail with
+// transient faults.
+func (c *Config) retryPolicy() retry.Policy {
+	return retry.Policy{
+		MaxRetries: c.APIMaxRetries,
+		MinDelay:   time.Duration(c.APIRetryMinDelay) * time.Millisecond,
+		MaxDelay:   time.Duration(c.APIRetryMaxDelay) * time.Millisecond,
+	}
+}
+
 func (c *Config) restURL() (*cache.Session, error) {
 	u, err := url.Parse("https://" + c.VSphereServer)
 	if err != nil {
 		return nil, err
 	}
-	if err != nil {
-		return nil, err
+	if c.User != "" {
+		u.User = url.UserPassword(c.User, c.Password)
 	}
-	u.User = url.UserPassword(c.User, c.Password)
 	s := &cache.Session{
 		URL:      u,
 		Insecure: c.InsecureFlag,
@@ -221,6 +347,7 @@ func (c *Config) SavedRestSessionOrNew(s *cache.Session) (*rest.Client, error) {
 
 	s.DirREST = c.RestSessionPath
 	s.Passthrough = !c.Persist
+	s.LoginREST = c.restLogin
 	restClient := new(rest.Client)
 	err := s.Login(ctx, restClient, nil)
 	if err != nil {
@@ -284,6 +411,10 @@ func (c *Config) vimURLWithoutPassword() (*url.URL, error) {
 //
 // This is the same logic used as part of govmomi and is designed to be
 // consistent so that sessions can be shared if possible between both tools.
+//
+// The URL only identifies the user for password authentication. For other
+// authentication methods, the method and a hash of the credential are added
+// to the key, so that sessions of different identities are not shared.
 func (c *Config) sessionFile() (string, error) {
 	u, err := c.vimURLWithoutPassword()
 	if err != nil {
@@ -293,10 +424,33 @@ func (c *Config) sessionFile() (string, error) {
 	// Key session file off of full URI and insecure setting.
 	// Hash key to get a predictable, canonical format.
 	key := fmt.Sprintf("%s#insecure=%t", u.String(), c.InsecureFlag)
+	method, err := c.authMethod()
+	if err != nil {
+		return "", err
+	}
+	if method != authMethodPassword {
+		key = fmt.Sprintf("%s#auth=%s#credential=%x", key, method, sha256.Sum256([]byte(c.sessionCredential(method))))
+	}
 	name := fmt.Sprintf("%040x", sha1.Sum([]byte(key)))
 	return name, nil
 }
 
+// sessionCredential returns the credential that identifies the session of an
+// authentication method other than password.
+func (c *Config) sessionCredential(method string) string {
+	switch method {
+	case authMethodSAMLToken:
+		return c.SAMLToken + "#" + c.ClientCertificatePath
+	case authMethodCertificate:
+		return c.ClientCertificatePath + "#" + c.ClientKeyPath
+	case authMethodSessionCookie:
+		return c.SessionCookie
+	case authMethodCloneTicket:
+		return c.CloneTicket
+	}
+	return ""
+}
+
 // restSessionFile is takes the session file name generated by sessionFile and
 // then prefixes the REST client session path to it.
 func (c *Config) restSessionFile() (string, error) {
@@ -461,7 +615,7 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	}
 	if client == nil {
 		log.Printf("[DEBUG] Creating new SOAP API session on endpoint %s", c.VSphereServer)
-		client, err = newClientWithKeepAlive(ctx, u, c.InsecureFlag, c.KeepAlive)
+		client, err = newClientWithKeepAlive(ctx, u, c.InsecureFlag, c.KeepAlive, c.vimLogin)
 		if err != nil {
 			return nil, fmt.Errorf("error setting up new vSphere SOAP client: %s", err)
 		}
@@ -470,7 +624,10 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	return client, nil
 }
 
-func newClientWithKeepAlive(ctx context.Context, u *url.URL, insecure bool, keepAlive int) (*govmomi.Client, error) {
+// newClientWithKeepAlive creates a new SOAP client with a keep alive handler.
+// If a login function is supplied, it is used to authenticate the session,
+// otherwise the user information in the URL is used if present.
+func newClientWithKeepAlive(ctx context.Context, u *url.URL, insecure bool, keepAlive int, login func(context.Context, *govmomi.Client) error) (*govmomi.Client, error) {
 	soapClient := soap.NewClient(u, insecure)
 	vimClient, err := vim25.NewClient(ctx, soapClient)
 	if err != nil {
@@ -485,8 +642,13 @@ func newClientWithKeepAlive(ctx context.Context, u *url.URL, insecure bool, keep
 	k := session.KeepAlive(c.Client.RoundTripper, time.Duration(keepAlive)*time.Minute)
 	c.Client.RoundTripper = k
 
-	// Only login if the URL contains user information.
-	if u.User != nil {
+	switch {
+	case login != nil:
+		if err := login(ctx, c); err != nil {
+			return nil, err
+		}
+	case u.User != nil:
+		// Only login if the URL contains user information.
 		err = c.Login(ctx, u.User)
 		if err != nil {
 			return nil, err
@@ -496,6 +658,124 @@ func newClientWithKeepAlive(ctx context.Context, u *url.URL, insecure bool, keep
 	return c, nil
 }
 
+// vimLogin authenticates a new SOAP client session with the configured
+// authentication method.
+func (c *Config) vimLogin(ctx context.Context, client *govmomi.Client) error {
+	method, err := c.authMethod()
+	if err != nil {
+		return err
+	}
+	switch method {
+	case authMethodSAMLToken, authMethodCertificate:
+		signer, err := c.tokenSigner(ctx, client.Client)
+		if err != nil {
+			return err
+		}
+		header := soap.Header{Security: signer}
+		if err := client.SessionManager.LoginByToken(client.Client.WithHeader(ctx, header)); err != nil {
+			return fmt.Errorf("error logging in with SAML token: %s", err)
+		}
+		return nil
+	case authMethodSessionCookie:
+		client.Client.Jar.SetCookies(client.URL(), []*http.Cookie{
+			{
+				Name:  soap.SessionCookieName,
+				Value: c.SessionCookie,
+			},
+		})
+		us, err := client.SessionManager.UserSession(ctx)
+		if err != nil {
+			return fmt.Errorf("error validating session cookie: %s", err)
+		}
+		if us == nil {
+			return errors.New("session_cookie does not refer to an authenticated session")
+		}
+		return nil
+	case authMethodCloneTicket:
+		if err := client.SessionManager.CloneSession(ctx, c.CloneTicket); err != nil {
+			return fmt.Errorf("error logging in with clone ticket: %s", err)
+		}
+		return nil
+	}
+	return client.Login(ctx, url.UserPassword(c.User, c.Password))
+}
+
+// restLogin authenticates a new REST client session with the configured
+// authentication method. SAML token and certificate authentication re-use
+// the signer created for the SOAP session, while session cookie and clone
+// ticket authentication require a REST session ID to be supplied.
+func (c *Config) restLogin(ctx context.Context, client *rest.Client) error {
+	method, err := c.authMethod()
+	if err != nil {
+		return err
+	}
+	switch method {
+	case authMethodSAMLToken, authMethodCertificate:
+		if c.signer == nil {
+			return errors.New("SAML token signer not initialized before REST login")
+		}
+		return client.LoginByToken(client.WithSigner(ctx, c.signer))
+	case authMethodSessionCookie, authMethodCloneTicket:
+		client.SessionID(c.RestSessionID)
+		s, err := client.Session(ctx)
+		if err != nil {
+			return fmt.Errorf("error validating REST session ID: %s", err)
+		}
+		if s == nil {
+			return errors.New("rest_session_id does not refer to an authenticated session")
+		}
+		return nil
+	}
+	return client.Login(ctx, url.UserPassword(c.User, c.Password))
+}
+
+// tokenSigner returns the signer used to authenticate with a SAML token,
+// creating it on first use. If a SAML token is configured, it is used
+// directly, signed with the client certificate if one is supplied for a
+// holder-of-key token. Otherwise, a holder-of-key token is issued by vCenter
+// SSO for the solution user represented by the client certificate.
+func (c *Config) tokenSigner(ctx context.Context, client *vim25.Client) (*sts.Signer, error) {
+	if c.signer != nil {
+		return c.signer, nil
+	}
+	var cert *tls.Certificate
+	if c.ClientCertificatePath != "" {
+		kp, err := tls.LoadX509KeyPair(c.ClientCertificatePath, c.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+		cert = &kp
+	}
+
+	if c.SAMLToken != "" {
+		c.signer = &sts.Signer{
+			Token:       c.SAMLToken,
+			Certificate: cert,
+		}
+		return c.signer, nil
+	}
+	if cert == nil {
+		return nil, errors.New("one of saml_token or client_certificate_path must be provided for token authentication")
+	}
+
+	client.SetCertificate(*cert)
+	tokens, err := sts.NewClient(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("error creating SSO client: %s", err)
+	}
+	req := sts.TokenRequest{
+		Certificate: cert,
+		Delegatable: true,
+		Renewable:   true,
+	}
+	signer, err := tokens.Issue(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error issuing SAML token for client certificate: %s", err)
+	}
+	c.signer = signer
+	return c.signer, nil
+}
+
 func restSessionValid(client *rest.Client) bool {
 	url := client.URL().String() + "/com/vmware/cis/session?~action=get"
 	resp, err := client.Post(url, "", nil)
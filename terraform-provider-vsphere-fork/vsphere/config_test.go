@@ -222,3 +222,154 @@ func TestNewConfig(t *testing.T) {
 		t.Fatalf("expected %#v, got %#v", expected, actual)
 	}
 }
+
+func TestNewConfig_samlToken(t *testing.T) {
+	r := &schema.Resource{Schema: Provider().(*schema.Provider).Schema}
+	d := r.Data(nil)
+	d.Set("saml_token", "<saml2:Assertion/>")
+	d.Set("vsphere_server", "vsphere.foo.internal")
+
+	actual, err := NewConfig(d)
+	if err != nil {
+		t.Fatalf("error creating new configuration: %s", err)
+	}
+	method, err := actual.authMethod()
+	if err != nil {
+		t.Fatalf("error getting authentication method: %s", err)
+	}
+	if method != authMethodSAMLToken {
+		t.Fatalf("expected authentication method %q, got %q", authMethodSAMLToken, method)
+	}
+	u, err := actual.vimURL()
+	if err != nil {
+		t.Fatalf("error getting VIM URL: %s", err)
+	}
+	if u.User != nil {
+		t.Fatalf("expected no user information in VIM URL, got %q", u.User)
+	}
+}
+
+func TestConfigAuthMethod(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   Config
+		expected string
+		err      bool
+	}{
+		{
+			name:     "password",
+			config:   Config{User: "foo", Password: "bar"},
+			expected: authMethodPassword,
+		},
+		{
+			name:     "bearer token",
+			config:   Config{SAMLToken: "token"},
+			expected: authMethodSAMLToken,
+		},
+		{
+			name:     "holder-of-key token",
+			config:   Config{SAMLToken: "token", ClientCertificatePath: "./cert.pem", ClientKeyPath: "./key.pem"},
+			expected: authMethodSAMLToken,
+		},
+		{
+			name:     "certificate",
+			config:   Config{ClientCertificatePath: "./cert.pem", ClientKeyPath: "./key.pem"},
+			expected: authMethodCertificate,
+		},
+		{
+			name:     "session cookie",
+			config:   Config{SessionCookie: "cookie"},
+			expected: authMethodSessionCookie,
+		},
+		{
+			name:     "clone ticket",
+			config:   Config{CloneTicket: "ticket"},
+			expected: authMethodCloneTicket,
+		},
+		{
+			name:   "none",
+			config: Config{},
+			err:    true,
+		},
+		{
+			name:   "password without user",
+			config: Config{Password: "bar"},
+			err:    true,
+		},
+		{
+			name:   "certificate without key",
+			config: Config{ClientCertificatePath: "./cert.pem"},
+			err:    true,
+		},
+		{
+			name:   "password and token",
+			config: Config{User: "foo", Password: "bar", SAMLToken: "token"},
+			err:    true,
+		},
+		{
+			name:   "cookie and ticket",
+			config: Config{SessionCookie: "cookie", CloneTicket: "ticket"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.config.authMethod()
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got method %q", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigSessionFile(t *testing.T) {
+	files := make(map[string]string)
+	for name, auth := range map[string]Config{
+		"password":        {User: "foo", Password: "bar"},
+		"other password":  {User: "foo", Password: "baz"},
+		"other user":      {User: "qux", Password: "bar"},
+		"token":           {SAMLToken: "token"},
+		"other token":     {SAMLToken: "other-token"},
+		"certificate":     {ClientCertificatePath: "./cert.pem", ClientKeyPath: "./key.pem"},
+		"session cookie":  {SessionCookie: "cookie"},
+		"clone ticket":    {CloneTicket: "ticket"},
+		"other ticket":    {CloneTicket: "other-ticket"},
+		"same as session": {SessionCookie: "ticket"},
+	} {
+		c := auth
+		c.VSphereServer = "vcenter.example.com"
+		f, err := c.sessionFile()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		files[name] = f
+	}
+
+	// Password sessions are keyed off of the user only, for compatibility with
+	// govc.
+	if files["password"] != files["other password"] {
+		t.Fatal("expected password sessions of the same user to share a session file")
+	}
+	for _, pair := range [][2]string{
+		{"password", "other user"},
+		{"token", "other token"},
+		{"token", "certificate"},
+		{"clone ticket", "other ticket"},
+		{"clone ticket", "same as session"},
+		{"password", "session cookie"},
+	} {
+		if files[pair[0]] == files[pair[1]] {
+			t.Fatalf("expected %s and %s to use different session files", pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereComputeClusterPlacement() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereComputeClusterPlacementRead,
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The managed object ID of the DRS-enabled cluster to request a placement from.",
+			},
+			"num_cpus": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "The number of virtual CPUs of the virtual machine to place.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"memory": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1024,
+				Description:  "The memory of the virtual machine to place, in MB.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"disk_sizes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The sizes of the disks of the virtual machine to place, in GB.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+			},
+			"network_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The managed object IDs of the networks the virtual machine to place is connected to. Only hosts with access to all of these networks are considered.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     string(types.VirtualMachineGuestOsIdentifierOtherGuest64),
+				Description: "The guest ID of the virtual machine to place.",
+			},
+			"host_system_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Restrict the placement to these hosts. By default, all hosts in the cluster are considered.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"datastore_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Restrict the placement to these datastores. By default, all datastores available to the cluster are considered.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the recommended host. The recommendation is requested again on every plan and can change as the load on the cluster changes, which moves a virtual machine that uses it to the new host. To only use the recommendation when the virtual machine is created, add host_system_id and datastore_id to lifecycle ignore_changes in the virtual machine.",
+			},
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the recommended datastore. Like host_system_id, this can change on every plan.",
+			},
+			"rating": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The rating of the recommendation, from 1 (lowest) to 5 (highest).",
+			},
+		},
+	}
+}
+
+func dataSourceVSphereComputeClusterPlacementRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	id := d.Get("compute_cluster_id").(string)
+	cluster, err := clustercomputeresource.FromID(client, id)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	props, err := clustercomputeresource.Properties(cluster)
+	if err != nil {
+		return fmt.Errorf("error loading cluster properties: %s", err)
+	}
+	if cfg, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx); ok {
+		if cfg.DrsConfig.Enabled == nil || !*cfg.DrsConfig.Enabled {
+			return fmt.Errorf("DRS is not enabled on cluster %q", cluster.InventoryPath)
+		}
+	}
+
+	spec, err := expandComputeClusterPlacementSpec(d, client)
+	if err != nil {
+		return err
+	}
+	result, err := clustercomputeresource.PlaceVM(cluster, spec)
+	if err != nil {
+		return err
+	}
+	host, datastore, rating := flattenComputeClusterPlacementResult(result)
+	if host == "" {
+		return fmt.Errorf("DRS did not recommend a host in cluster %q", cluster.InventoryPath)
+	}
+	log.Printf("[DEBUG] DRS recommended host %q and datastore %q in cluster %q", host, datastore, cluster.InventoryPath)
+
+	d.SetId(id)
+	if err := d.Set("host_system_id", host); err != nil {
+		return err
+	}
+	if err := d.Set("datastore_id", datastore); err != nil {
+		return err
+	}
+	return d.Set("rating", rating)
+}
+
+// expandComputeClusterPlacementSpec builds a PlacementSpec for the creation of
+// a virtual machine with the profile described in the data source.
+func expandComputeClusterPlacementSpec(d *schema.ResourceData, client *govmomi.Client) (types.PlacementSpec, error) {
+	var dc []types.BaseVirtualDeviceConfigSpec
+	var l object.VirtualDeviceList
+
+	if sizes := d.Get("disk_sizes").([]interface{}); len(sizes) > 0 {
+		ctlr, err := l.CreateSCSIController("pvscsi")
+		if err != nil {
+			return types.PlacementSpec{}, err
+		}
+		l = append(l, ctlr)
+		dc = append(dc, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    ctlr,
+		})
+		for _, size := range sizes {
+			disk := &types.VirtualDisk{
+				VirtualDevice: types.VirtualDevice{
+					Key: l.NewKey(),
+					Backing: &types.VirtualDiskFlatVer2BackingInfo{
+						DiskMode:        string(types.VirtualDiskModePersistent),
+						ThinProvisioned: structure.BoolPtr(true),
+					},
+				},
+				CapacityInKB: int64(size.(int)) * 1024 * 1024,
+			}
+			l.AssignController(disk, ctlr.(types.BaseVirtualController))
+			l = append(l, disk)
+			dc = append(dc, &types.VirtualDeviceConfigSpec{
+				Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+				FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+				Device:        disk,
+			})
+		}
+	}
+
+	for _, id := range d.Get("network_ids").([]interface{}) {
+		net, err := network.FromID(client, id.(string))
+		if err != nil {
+			return types.PlacementSpec{}, fmt.Errorf("cannot locate network %q: %s", id, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		backing, err := net.EthernetCardBackingInfo(ctx)
+		cancel()
+		if err != nil {
+			return types.PlacementSpec{}, fmt.Errorf("error reading backing info for network %q: %s", id, err)
+		}
+		card, err := l.CreateEthernetCard("vmxnet3", backing)
+		if err != nil {
+			return types.PlacementSpec{}, err
+		}
+		card.GetVirtualDevice().Key = l.NewKey()
+		l = append(l, card)
+		dc = append(dc, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    card,
+		})
+	}
+
+	return types.PlacementSpec{
+		PlacementType: string(types.PlacementSpecPlacementTypeCreate),
+		ConfigSpec: &types.VirtualMachineConfigSpec{
+			Name:         "terraform-placement",
+			GuestId:      d.Get("guest_id").(string),
+			NumCPUs:      int32(d.Get("num_cpus").(int)),
+			MemoryMB:     int64(d.Get("memory").(int)),
+			DeviceChange: dc,
+		},
+		Hosts:      structure.SliceInterfacesToManagedObjectReferences(d.Get("host_system_ids").([]interface{}), "HostSystem"),
+		Datastores: structure.SliceInterfacesToManagedObjectReferences(d.Get("datastore_ids").([]interface{}), "Datastore"),
+	}, nil
+}
+
+// flattenComputeClusterPlacementResult returns the host, datastore and rating
+// of the first placement action in a DRS placement result. The datastore is
+// taken from the relocate spec, falling back to the datastore of the first
+// disk if the spec does not name one.
+func flattenComputeClusterPlacementResult(result *types.PlacementResult) (string, string, int) {
+	for _, rec := range result.Recommendations {
+		for _, action := range rec.Action {
+			pa, ok := action.(*types.PlacementAction)
+			if !ok || pa.TargetHost == nil {
+				continue
+			}
+			var datastore string
+			if rs := pa.RelocateSpec; rs != nil {
+				switch {
+				case rs.Datastore != nil:
+					datastore = rs.Datastore.Value
+				case len(rs.Disk) > 0:
+					datastore = rs.Disk[0].Datastore.Value
+				}
+			}
+			return pa.TargetHost.Value, datastore, int(rec.Rating)
+		}
+	}
+	return "", "", 0
+}
@@ -0,0 +1,83 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereComputeClusterPlacement_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccDataSourceVSphereComputeClusterPlacementPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereComputeClusterPlacementConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.vsphere_compute_cluster_placement.placement", "host_system_id", regexp.MustCompile("^host-")),
+					resource.TestMatchResourceAttr("data.vsphere_compute_cluster_placement.placement", "datastore_id", regexp.MustCompile("^datastore-")),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenComputeClusterPlacementResult(t *testing.T) {
+	result := &types.PlacementResult{
+		Recommendations: []types.ClusterRecommendation{
+			{
+				Rating: 4,
+				Action: []types.BaseClusterAction{
+					&types.ClusterAction{Type: "Unrelated"},
+					&types.PlacementAction{
+						TargetHost: &types.ManagedObjectReference{Type: "HostSystem", Value: "host-10"},
+						RelocateSpec: &types.VirtualMachineRelocateSpec{
+							Disk: []types.VirtualMachineRelocateSpecDiskLocator{
+								{Datastore: types.ManagedObjectReference{Type: "Datastore", Value: "datastore-20"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	host, datastore, rating := flattenComputeClusterPlacementResult(result)
+	if host != "host-10" || datastore != "datastore-20" || rating != 4 {
+		t.Fatalf("expected host-10, datastore-20 and 4, got %q, %q and %d", host, datastore, rating)
+	}
+
+	if host, _, _ := flattenComputeClusterPlacementResult(&types.PlacementResult{}); host != "" {
+		t.Fatalf("expected no host for empty result, got %q", host)
+	}
+}
+
+func testAccDataSourceVSphereComputeClusterPlacementPreCheck(t *testing.T) {
+	if os.Getenv("TF_VAR_VSPHERE_CLUSTER") == "" {
+		t.Skip("set TF_VAR_VSPHERE_CLUSTER to run vsphere_compute_cluster_placement acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereComputeClusterPlacementConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_compute_cluster_placement" "placement" {
+  compute_cluster_id = "${data.vsphere_compute_cluster.rootcompute_cluster1.id}"
+  num_cpus           = 2
+  memory             = 2048
+  disk_sizes         = [10]
+  network_ids        = ["${data.vsphere_network.network1.id}"]
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootComputeCluster1(), testhelper.ConfigDataRootPortGroup1()),
+	)
+}
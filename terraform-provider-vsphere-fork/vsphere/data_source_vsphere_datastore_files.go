@@ -0,0 +1,230 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	datastoreFilesSortPath         = "path"
+	datastoreFilesSortModification = "modification"
+)
+
+// datastoreFileTypeAllowedValues is a list of the file types that can be
+// returned by the vsphere_datastore_files data source.
+var datastoreFileTypeAllowedValues = []string{
+	"folder",
+	"vmdk",
+	"iso",
+	"floppy",
+	"vmx",
+	"vmtx",
+	"nvram",
+	"snapshot",
+	"log",
+	"file",
+}
+
+func dataSourceVSphereDatastoreFiles() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDatastoreFilesRead,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The managed object ID of the datastore to search.",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The directory on the datastore to search. Defaults to the root of the datastore.",
+			},
+			"patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "A list of glob patterns that file names must match, such as *.iso. Defaults to all files.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"recursive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether or not to search sub-folders of path.",
+			},
+			"file_types": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only return files of these types. Can be any of folder, vmdk, iso, floppy, vmx, vmtx, nvram, snapshot, log, or file.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(datastoreFileTypeAllowedValues, false),
+				},
+			},
+			"sort_by": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      datastoreFilesSortPath,
+				Description:  "The order of the returned files. Can be one of path, or modification for newest first.",
+				ValidateFunc: validation.StringInSlice([]string{datastoreFilesSortPath, datastoreFilesSortModification}, false),
+			},
+			"files": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The files that match the supplied criteria.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"datastore_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"modification_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"owner": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDatastoreFilesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	dsID := d.Get("datastore_id").(string)
+	ds, err := datastore.FromID(client, dsID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	dir := d.Get("path").(string)
+	patterns := structure.SliceInterfacesToStrings(d.Get("patterns").([]interface{}))
+	if len(patterns) < 1 {
+		patterns = []string{"*"}
+	}
+	log.Printf("[DEBUG] dataSourceVSphereDatastoreFiles: Searching %q on datastore %q for %v", dir, ds.Name(), patterns)
+	results, err := datastore.SearchFiles(ds, dir, patterns, d.Get("recursive").(bool))
+	if err != nil {
+		return fmt.Errorf("error searching datastore %q: %s", ds.Name(), err)
+	}
+
+	fileTypes := make(map[string]struct{})
+	for _, v := range d.Get("file_types").(*schema.Set).List() {
+		fileTypes[v.(string)] = struct{}{}
+	}
+
+	type entry struct {
+		m        map[string]interface{}
+		path     string
+		modified time.Time
+	}
+	var entries []entry
+	for _, r := range results {
+		var dp object.DatastorePath
+		if !dp.FromString(r.FolderPath) {
+			return fmt.Errorf("could not parse datastore path %q", r.FolderPath)
+		}
+		for _, bfi := range r.File {
+			t := datastoreFileType(bfi)
+			if _, ok := fileTypes[t]; len(fileTypes) > 0 && !ok {
+				continue
+			}
+			fi := bfi.GetFileInfo()
+			p := path.Join(dp.Path, fi.Path)
+			e := entry{
+				path: p,
+				m: map[string]interface{}{
+					"name":           fi.Path,
+					"path":           p,
+					"datastore_path": (&object.DatastorePath{Datastore: dp.Datastore, Path: p}).String(),
+					"type":           t,
+					"size":           int(fi.FileSize),
+					"owner":          fi.Owner,
+				},
+			}
+			if fi.Modification != nil {
+				e.modified = *fi.Modification
+				e.m["modification_time"] = fi.Modification.Format(time.RFC3339)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	switch d.Get("sort_by").(string) {
+	case datastoreFilesSortModification:
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].modified.Equal(entries[j].modified) {
+				return entries[i].path < entries[j].path
+			}
+			return entries[i].modified.After(entries[j].modified)
+		})
+	default:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	}
+	var files []interface{}
+	for _, e := range entries {
+		files = append(files, e.m)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", dsID, dir))
+	if err := d.Set("files", files); err != nil {
+		return fmt.Errorf("error setting files: %s", err)
+	}
+	log.Printf("[DEBUG] dataSourceVSphereDatastoreFiles: %d files found", len(files))
+	return nil
+}
+
+// datastoreFileType returns the file type name for file info returned from a
+// datastore search.
+func datastoreFileType(bfi types.BaseFileInfo) string {
+	switch bfi.(type) {
+	case *types.FolderFileInfo:
+		return "folder"
+	case *types.VmDiskFileInfo:
+		return "vmdk"
+	case *types.IsoImageFileInfo:
+		return "iso"
+	case *types.FloppyImageFileInfo:
+		return "floppy"
+	case *types.VmConfigFileInfo:
+		return "vmx"
+	case *types.TemplateConfigFileInfo:
+		return "vmtx"
+	case *types.VmNvramFileInfo:
+		return "nvram"
+	case *types.VmSnapshotFileInfo:
+		return "snapshot"
+	case *types.VmLogFileInfo:
+		return "log"
+	}
+	return "file"
+}
@@ -0,0 +1,83 @@
+package vsphere
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccDataSourceVSphereDatastoreFiles_basic(t *testing.T) {
+	testIsoFile := "/tmp/tf_test_datastore_files.iso"
+	if err := ioutil.WriteFile(testIsoFile, []byte("not really an iso\n"), 0644); err != nil {
+		t.Fatalf("error %s", err)
+	}
+	defer os.Remove(testIsoFile)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccCheckEnvVariables(t, []string{"TF_VAR_VSPHERE_DATACENTER", "TF_VAR_VSPHERE_NFS_DS_NAME"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereDatastoreFilesConfig(testIsoFile),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.name", "tf_datastore_files_test.iso"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.path", "tf-datastore-files-test/tf_datastore_files_test.iso"),
+					resource.TestCheckResourceAttr("data.vsphere_datastore_files.files", "files.0.type", "iso"),
+					resource.TestCheckResourceAttrSet("data.vsphere_datastore_files.files", "files.0.modification_time"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereDatastoreFilesConfig(src string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "ds" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_file" "iso" {
+  datacenter         = "${var.datacenter}"
+  datastore          = "${var.datastore}"
+  source_file        = "%s"
+  destination_file   = "tf-datastore-files-test/tf_datastore_files_test.iso"
+  create_directories = true
+}
+
+data "vsphere_datastore_files" "files" {
+  datastore_id = "${data.vsphere_datastore.ds.id}"
+  path         = "tf-datastore-files-test"
+  patterns     = ["*.iso"]
+  file_types   = ["iso"]
+  sort_by      = "modification"
+
+  depends_on = ["vsphere_file.iso"]
+}
+`,
+		os.Getenv("TF_VAR_VSPHERE_DATACENTER"),
+		os.Getenv("TF_VAR_VSPHERE_NFS_DS_NAME"),
+		src,
+	)
+}
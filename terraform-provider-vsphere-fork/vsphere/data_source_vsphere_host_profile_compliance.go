@@ -0,0 +1,148 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostprofile"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereHostProfileCompliance() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostProfileComplianceRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_profile_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The managed object ID of the host profile to check compliance against.",
+			},
+			"host_system_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the hosts to check. If neither this nor compute_cluster_ids is set, all entities attached to the profile are checked.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"compute_cluster_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the clusters to check. If neither this nor host_system_ids is set, all entities attached to the profile are checked.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"compliance_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The overall compliance status of the checked entities. One of compliant, nonCompliant, or unknown.",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The compliance results for each checked entity.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"entity_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"entity_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"compliance_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"check_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"failures": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"failure_type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"message": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"expression_name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostProfileComplianceRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id := d.Get("host_profile_id").(string)
+	entities := hostProfileEntityRefs(
+		d.Get("host_system_ids").(*schema.Set).List(),
+		d.Get("compute_cluster_ids").(*schema.Set).List(),
+	)
+	results, err := hostprofile.CheckCompliance(client, id, entities)
+	if err != nil {
+		return fmt.Errorf("error checking compliance for host profile %q: %s", id, err)
+	}
+
+	status := string(types.ComplianceResultStatusCompliant)
+	if len(results) < 1 {
+		status = string(types.ComplianceResultStatusUnknown)
+	}
+	var out []interface{}
+	for _, r := range results {
+		switch {
+		case r.ComplianceStatus == string(types.ComplianceResultStatusNonCompliant):
+			status = r.ComplianceStatus
+		case r.ComplianceStatus != string(types.ComplianceResultStatusCompliant) && status == string(types.ComplianceResultStatusCompliant):
+			status = string(types.ComplianceResultStatusUnknown)
+		}
+		out = append(out, flattenComplianceResult(r))
+	}
+
+	d.SetId(id)
+	d.Set("compliance_status", status)
+	if err := d.Set("results", out); err != nil {
+		return fmt.Errorf("error setting results: %s", err)
+	}
+	log.Printf("[DEBUG] dataSourceVSphereHostProfileCompliance: %d results for host profile %q, status %s", len(out), id, status)
+	return nil
+}
+
+// flattenComplianceResult reads various fields from a ComplianceResult and
+// returns a map for the results list.
+func flattenComplianceResult(obj types.ComplianceResult) map[string]interface{} {
+	m := map[string]interface{}{
+		"compliance_status": obj.ComplianceStatus,
+	}
+	if obj.Entity != nil {
+		m["entity_id"] = obj.Entity.Value
+		m["entity_type"] = obj.Entity.Type
+	}
+	if obj.CheckTime != nil {
+		m["check_time"] = obj.CheckTime.Format(time.RFC3339)
+	}
+	var failures []interface{}
+	for _, f := range obj.Failure {
+		failures = append(failures, map[string]interface{}{
+			"failure_type":    f.FailureType,
+			"message":         f.Message.Message,
+			"expression_name": f.ExpressionName,
+		})
+	}
+	m["failures"] = failures
+	return m
+}
@@ -0,0 +1,436 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// dataSourceVSphereVirtualMachinesProperties is the list of properties
+// fetched for every virtual machine in the container.
+var dataSourceVSphereVirtualMachinesProperties = []string{
+	"name",
+	"parent",
+	"parentVApp",
+	"config.uuid",
+	"config.guestId",
+	"config.template",
+	"runtime.powerState",
+	"guest.toolsRunningStatus",
+	"guest.ipAddress",
+	"guest.net",
+	"customValue",
+}
+
+func dataSourceVSphereVirtualMachines() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachinesRead,
+
+		Schema: map[string]*schema.Schema{
+			"datacenter_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"folder_id", "resource_pool_id", "cluster_id"},
+				Description:   "The managed object ID of the datacenter to list virtual machines in.",
+			},
+			"folder_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"datacenter_id", "resource_pool_id", "cluster_id"},
+				Description:   "The managed object ID of the folder to list virtual machines in.",
+			},
+			"resource_pool_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"datacenter_id", "folder_id", "cluster_id"},
+				Description:   "The managed object ID of the resource pool to list virtual machines in.",
+			},
+			"cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"datacenter_id", "folder_id", "resource_pool_id"},
+				Description:   "The managed object ID of the compute cluster to list virtual machines in.",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "A regular expression used to match against virtual machine names.",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A list of tag IDs. Only virtual machines that have all of the tags attached are returned.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"custom_attributes": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "A map of custom attribute IDs to values. Only virtual machines that have all of the attribute values set are returned.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"power_state": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return virtual machines in this power state. Can be one of poweredOn, poweredOff, or suspended.",
+				ValidateFunc: validation.StringInSlice(virtualMachinePowerStateAllowedValues, false),
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return virtual machines with this guest ID.",
+			},
+			"include_templates": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether or not to include templates in the results.",
+			},
+			"virtual_machines": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The virtual machines that match the supplied criteria, sorted by path.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"moid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"guest_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"template": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"power_state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vmware_tools_status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_ip_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"guest_ip_addresses": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"uuids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The UUIDs of the virtual machines that match the supplied criteria, in the same order as virtual_machines.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// virtualMachinePowerStateAllowedValues is a list of the valid power states
+// that can be used to filter virtual machines.
+var virtualMachinePowerStateAllowedValues = []string{
+	string(types.VirtualMachinePowerStatePoweredOn),
+	string(types.VirtualMachinePowerStatePoweredOff),
+	string(types.VirtualMachinePowerStateSuspended),
+}
+
+func dataSourceVSphereVirtualMachinesRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] dataSourceVSphereVirtualMachines: Beginning read")
+	client := meta.(*VSphereClient).vimClient
+
+	container, err := dataSourceVSphereVirtualMachinesContainer(d, client)
+	if err != nil {
+		return err
+	}
+	vms, err := virtualmachine.PropertiesInContainer(client, container, dataSourceVSphereVirtualMachinesProperties)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machines: %s", err)
+	}
+	log.Printf("[DEBUG] dataSourceVSphereVirtualMachines: %d virtual machines found in %q", len(vms), container.Value)
+
+	vms, err = filterVirtualMachinesByProperties(d, vms)
+	if err != nil {
+		return err
+	}
+	vms, err = filterVirtualMachinesByTags(d, meta, vms)
+	if err != nil {
+		return err
+	}
+
+	paths, err := virtualMachineInventoryPaths(client, vms)
+	if err != nil {
+		return fmt.Errorf("error computing virtual machine paths: %s", err)
+	}
+	sort.Slice(vms, func(i, j int) bool {
+		return paths[vms[i].Self.Value] < paths[vms[j].Self.Value]
+	})
+
+	var results []interface{}
+	var uuids []string
+	for _, vm := range vms {
+		m := map[string]interface{}{
+			"name":     vm.Name,
+			"moid":     vm.Self.Value,
+			"path":     paths[vm.Self.Value],
+			"template": false,
+		}
+		if vm.Config != nil {
+			m["uuid"] = vm.Config.Uuid
+			m["guest_id"] = vm.Config.GuestId
+			m["template"] = vm.Config.Template
+			uuids = append(uuids, vm.Config.Uuid)
+		}
+		m["power_state"] = string(vm.Runtime.PowerState)
+		if vm.Guest != nil {
+			m["vmware_tools_status"] = vm.Guest.ToolsRunningStatus
+			m["default_ip_address"] = vm.Guest.IpAddress
+			var ips []string
+			for _, n := range vm.Guest.Net {
+				ips = append(ips, n.IpAddress...)
+			}
+			m["guest_ip_addresses"] = ips
+		}
+		results = append(results, m)
+	}
+
+	d.SetId(container.Value)
+	if err := d.Set("virtual_machines", results); err != nil {
+		return fmt.Errorf("error setting virtual_machines: %s", err)
+	}
+	if err := d.Set("uuids", uuids); err != nil {
+		return fmt.Errorf("error setting uuids: %s", err)
+	}
+	log.Printf("[DEBUG] dataSourceVSphereVirtualMachines: Read complete, %d virtual machines matched", len(results))
+	return nil
+}
+
+// dataSourceVSphereVirtualMachinesContainer returns the reference to the
+// container to list virtual machines in. If no container is supplied, the
+// root folder is used.
+func dataSourceVSphereVirtualMachinesContainer(d *schema.ResourceData, client *govmomi.Client) (types.ManagedObjectReference, error) {
+	switch {
+	case d.Get("datacenter_id").(string) != "":
+		dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+		if err != nil {
+			return types.ManagedObjectReference{}, err
+		}
+		return dc.Reference(), nil
+	case d.Get("folder_id").(string) != "":
+		f, err := folder.FromID(client, d.Get("folder_id").(string))
+		if err != nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("cannot locate folder: %s", err)
+		}
+		return f.Reference(), nil
+	case d.Get("resource_pool_id").(string) != "":
+		rp, err := resourcepool.FromID(client, d.Get("resource_pool_id").(string))
+		if err != nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("cannot locate resource pool: %s", err)
+		}
+		return rp.Reference(), nil
+	case d.Get("cluster_id").(string) != "":
+		cluster, err := clustercomputeresource.FromID(client, d.Get("cluster_id").(string))
+		if err != nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("cannot locate compute cluster: %s", err)
+		}
+		return cluster.Reference(), nil
+	}
+	return client.ServiceContent.RootFolder, nil
+}
+
+// filterVirtualMachinesByProperties filters a list of virtual machines by
+// name, power state, guest ID, template flag, and custom attribute values.
+func filterVirtualMachinesByProperties(d *schema.ResourceData, vms []mo.VirtualMachine) ([]mo.VirtualMachine, error) {
+	re, err := regexp.Compile(d.Get("name_regex").(string))
+	if err != nil {
+		return nil, err
+	}
+	powerState := d.Get("power_state").(string)
+	guestID := d.Get("guest_id").(string)
+	includeTemplates := d.Get("include_templates").(bool)
+	attrs := d.Get("custom_attributes").(map[string]interface{})
+
+	var filtered []mo.VirtualMachine
+	for _, vm := range vms {
+		if !re.MatchString(vm.Name) {
+			continue
+		}
+		if powerState != "" && string(vm.Runtime.PowerState) != powerState {
+			continue
+		}
+		if vm.Config != nil {
+			if guestID != "" && vm.Config.GuestId != guestID {
+				continue
+			}
+			if vm.Config.Template && !includeTemplates {
+				continue
+			}
+		} else if guestID != "" {
+			continue
+		}
+		if !virtualMachineHasCustomAttributes(vm, attrs) {
+			continue
+		}
+		filtered = append(filtered, vm)
+	}
+	return filtered, nil
+}
+
+// virtualMachineHasCustomAttributes returns true if all of the supplied
+// custom attribute values are set on the virtual machine.
+func virtualMachineHasCustomAttributes(vm mo.VirtualMachine, attrs map[string]interface{}) bool {
+	if len(attrs) < 1 {
+		return true
+	}
+	values := make(map[string]string)
+	for _, cv := range vm.CustomValue {
+		if sv, ok := cv.(*types.CustomFieldStringValue); ok {
+			values[strconv.Itoa(int(sv.Key))] = sv.Value
+		}
+	}
+	for k, v := range attrs {
+		if values[k] != v.(string) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterVirtualMachinesByTags filters a list of virtual machines to those that
+// have all of the supplied tags attached. Unlike the dynamic data source, no
+// match is not an error, and an empty list is returned.
+func filterVirtualMachinesByTags(d *schema.ResourceData, meta interface{}, vms []mo.VirtualMachine) ([]mo.VirtualMachine, error) {
+	tagIDs := structure.SliceInterfacesToStrings(d.Get("tags").(*schema.Set).List())
+	if len(tagIDs) < 1 {
+		return vms, nil
+	}
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	attached, err := tm.GetAttachedObjectsOnTags(ctx, tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching objects attached to tags: %s", err)
+	}
+	var matches tags.AttachedObjects
+	if len(attached) > 0 {
+		matches = attached[0]
+		for _, a := range attached[1:] {
+			matches = attachedObjectsIntersection(matches, a)
+		}
+	}
+	// A tag with nothing attached to it is not returned at all, so if any of
+	// the tags are missing from the result, nothing can match.
+	if len(attached) < len(tagIDs) {
+		matches = tags.AttachedObjects{}
+	}
+	tagged := make(map[string]struct{})
+	for _, obj := range matches.ObjectIDs {
+		if obj.Reference().Type == "VirtualMachine" {
+			tagged[obj.Reference().Value] = struct{}{}
+		}
+	}
+	var filtered []mo.VirtualMachine
+	for _, vm := range vms {
+		if _, ok := tagged[vm.Self.Value]; ok {
+			filtered = append(filtered, vm)
+		}
+	}
+	return filtered, nil
+}
+
+// virtualMachineInventoryPaths returns the inventory paths of a list of
+// virtual machines, keyed by managed object ID. The names and parents of every
+// folder, datacenter, compute resource, resource pool, and vApp are fetched in
+// a single retrieval, and each path is built by walking up from the parent, or
+// parent vApp, of the virtual machine.
+func virtualMachineInventoryPaths(client *govmomi.Client, vms []mo.VirtualMachine) (map[string]string, error) {
+	if len(vms) < 1 {
+		return map[string]string{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	root := client.ServiceContent.RootFolder
+	m := view.NewManager(client.Client)
+	v, err := m.CreateContainerView(ctx, root, []string{"Folder", "Datacenter", "ComputeResource", "ResourcePool", "VirtualApp"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = v.Destroy(ctx); err != nil {
+			log.Printf("[DEBUG] virtualMachineInventoryPaths: Unexpected error destroying container view: %s", err)
+		}
+	}()
+	var entities []mo.ManagedEntity
+	if err := v.Retrieve(ctx, []string{"ManagedEntity"}, []string{"name", "parent"}, &entities); err != nil {
+		return nil, err
+	}
+	return virtualMachineInventoryPathsFromAncestry(root, vms, entities)
+}
+
+// virtualMachineInventoryPathsFromAncestry builds the inventory paths of a
+// list of virtual machines from the names and parents of their ancestors, up
+// to the root folder.
+func virtualMachineInventoryPathsFromAncestry(root types.ManagedObjectReference, vms []mo.VirtualMachine, entities []mo.ManagedEntity) (map[string]string, error) {
+	ancestors := make(map[types.ManagedObjectReference]mo.ManagedEntity)
+	for _, e := range entities {
+		ancestors[e.Self] = e
+	}
+	paths := make(map[string]string)
+	for _, vm := range vms {
+		p := vm.Name
+		parent := vm.Parent
+		if vm.ParentVApp != nil {
+			parent = vm.ParentVApp
+		}
+		for parent != nil && *parent != root {
+			e, ok := ancestors[*parent]
+			if !ok {
+				return nil, fmt.Errorf("cannot find ancestor %q of virtual machine %q", parent.Value, vm.Self.Value)
+			}
+			p = path.Join(e.Name, p)
+			parent = e.Parent
+		}
+		paths[vm.Self.Value] = "/" + p
+	}
+	return paths, nil
+}
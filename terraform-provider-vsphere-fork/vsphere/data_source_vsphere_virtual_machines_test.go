@@ -0,0 +1,199 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereVirtualMachines_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachinesConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.0.name", os.Getenv("TF_VAR_VSPHERE_TEMPLATE")),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.0.template", "true"),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_virtual_machines.vms",
+						"virtual_machines.0.uuid",
+						regexp.MustCompile("^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$")),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machines.vms", "virtual_machines.0.moid"),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machines.vms", "virtual_machines.0.path"),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machines.vms", "virtual_machines.0.guest_id"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machines.vms", "uuids.0",
+						"data.vsphere_virtual_machine.template", "id",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVSphereVirtualMachines_noTagMatch(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachinesConfigNoTagMatch(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.#", "0"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "uuids.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestVirtualMachineInventoryPathsFromAncestry(t *testing.T) {
+	ref := func(kind, value string) *types.ManagedObjectReference {
+		return &types.ManagedObjectReference{Type: kind, Value: value}
+	}
+	entity := func(self, parent *types.ManagedObjectReference, name string) mo.ManagedEntity {
+		e := mo.ManagedEntity{Name: name, Parent: parent}
+		e.Self = *self
+		return e
+	}
+	vm := func(value, name string, parent, parentVApp *types.ManagedObjectReference) mo.VirtualMachine {
+		v := mo.VirtualMachine{ParentVApp: parentVApp}
+		v.Self = *ref("VirtualMachine", value)
+		v.Name = name
+		v.Parent = parent
+		return v
+	}
+	root := ref("Folder", "group-d1")
+	entities := []mo.ManagedEntity{
+		entity(ref("Datacenter", "datacenter-1"), root, "dc1"),
+		entity(ref("Folder", "group-v1"), ref("Datacenter", "datacenter-1"), "vm"),
+		entity(ref("Folder", "group-v2"), ref("Folder", "group-v1"), "app"),
+		entity(ref("Folder", "group-h1"), ref("Datacenter", "datacenter-1"), "host"),
+		entity(ref("ClusterComputeResource", "domain-c1"), ref("Folder", "group-h1"), "cluster1"),
+		entity(ref("ResourcePool", "resgroup-1"), ref("ClusterComputeResource", "domain-c1"), "Resources"),
+		entity(ref("VirtualApp", "resgroup-v1"), ref("ResourcePool", "resgroup-1"), "vapp1"),
+	}
+
+	cases := []struct {
+		name      string
+		vms       []mo.VirtualMachine
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name: "folders",
+			vms: []mo.VirtualMachine{
+				vm("vm-1", "web", ref("Folder", "group-v1"), nil),
+				vm("vm-2", "db", ref("Folder", "group-v2"), nil),
+			},
+			expected: map[string]string{
+				"vm-1": "/dc1/vm/web",
+				"vm-2": "/dc1/vm/app/db",
+			},
+		},
+		{
+			name: "vApp",
+			vms: []mo.VirtualMachine{
+				vm("vm-3", "member", nil, ref("VirtualApp", "resgroup-v1")),
+			},
+			expected: map[string]string{
+				"vm-3": "/dc1/host/cluster1/Resources/vapp1/member",
+			},
+		},
+		{
+			name: "missing ancestor",
+			vms: []mo.VirtualMachine{
+				vm("vm-4", "lost", ref("Folder", "group-v9"), nil),
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := virtualMachineInventoryPathsFromAncestry(*root, tc.vms, entities)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func testAccDataSourceVSphereVirtualMachinesConfig() string {
+	return fmt.Sprintf(`
+%s
+
+variable "template" {
+  default = "%s"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+}
+
+data "vsphere_virtual_machines" "vms" {
+  datacenter_id     = "${data.vsphere_datacenter.rootdc1.id}"
+  name_regex        = "^${var.template}$"
+  include_templates = true
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootPortGroup1()),
+		os.Getenv("TF_VAR_VSPHERE_TEMPLATE"),
+	)
+}
+
+func testAccDataSourceVSphereVirtualMachinesConfigNoTagMatch() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_tag_category" "testacc-category" {
+  name        = "testacc-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "VirtualMachine",
+  ]
+}
+
+resource "vsphere_tag" "testacc-tag" {
+  name        = "testacc-tag"
+  category_id = "${vsphere_tag_category.testacc-category.id}"
+}
+
+data "vsphere_virtual_machines" "vms" {
+  datacenter_id     = "${data.vsphere_datacenter.rootdc1.id}"
+  tags              = ["${vsphere_tag.testacc-tag.id}"]
+  include_templates = true
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootPortGroup1()),
+	)
+}
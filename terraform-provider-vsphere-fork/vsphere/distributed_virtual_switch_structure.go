@@ -204,6 +204,35 @@ func schemaVMwareDVSConfigSpec() map[string]*schema.Schema {
 			ValidateFunc: validation.StringInSlice(networkResourceControlAllowedValues, false),
 		},
 
+		// VMwareDVSPvlanMapEntry
+		"pvlan_mapping": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "A private VLAN (PVLAN) mapping on the switch. Each primary VLAN must have a promiscuous entry where the primary and secondary VLAN IDs are the same.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"primary_vlan_id": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The primary VLAN ID. The VLAN IDs of 0 and 4095 are reserved and cannot be used in this property.",
+						ValidateFunc: validation.IntBetween(1, 4094),
+					},
+					"secondary_vlan_id": {
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The secondary VLAN ID. The VLAN IDs of 0 and 4095 are reserved and cannot be used in this property.",
+						ValidateFunc: validation.IntBetween(1, 4094),
+					},
+					"pvlan_type": {
+						Type:         schema.TypeString,
+						Required:     true,
+						Description:  "The private VLAN type. Can be one of promiscuous, isolated, or community.",
+						ValidateFunc: validation.StringInSlice(privateVLANTypeAllowedValues, false),
+					},
+				},
+			},
+		},
+
 		"config_version": {
 			Type:        schema.TypeString,
 			Computed:    true,
@@ -530,6 +559,86 @@ func flattenDVSNameArrayUplinkPortPolicy(d *schema.ResourceData, obj *types.DVSN
 	return nil
 }
 
+// expandVMwareDVSPvlanMapEntry reads certain keys from a Set object map and
+// returns a VMwareDVSPvlanMapEntry.
+func expandVMwareDVSPvlanMapEntry(d map[string]interface{}) *types.VMwareDVSPvlanMapEntry {
+	obj := &types.VMwareDVSPvlanMapEntry{
+		PrimaryVlanId:   int32(d["primary_vlan_id"].(int)),
+		SecondaryVlanId: int32(d["secondary_vlan_id"].(int)),
+		PvlanType:       d["pvlan_type"].(string),
+	}
+	return obj
+}
+
+// flattenVMwareDVSPvlanMapEntry reads various fields from a
+// VMwareDVSPvlanMapEntry and returns a Set object map.
+//
+// This is the flatten counterpart to expandVMwareDVSPvlanMapEntry.
+func flattenVMwareDVSPvlanMapEntry(obj types.VMwareDVSPvlanMapEntry) map[string]interface{} {
+	d := make(map[string]interface{})
+	d["primary_vlan_id"] = obj.PrimaryVlanId
+	d["secondary_vlan_id"] = obj.SecondaryVlanId
+	d["pvlan_type"] = obj.PvlanType
+	return d
+}
+
+// expandSliceOfVMwareDVSPvlanConfigSpec expands all PVLAN mapping entries for
+// a VMware DVS, detecting if an entry needs to be added or removed. There is
+// no edit operation for PVLAN entries as the whole entry is the key, so a
+// changed entry is a removal and an addition.
+//
+// vSphere requires a promiscuous entry for a primary VLAN to exist before any
+// isolated or community entries can be created for it, and to be removed only
+// after them, so removals of secondary entries are ordered before promiscuous
+// ones, and additions of promiscuous entries are ordered before secondary
+// ones.
+func expandSliceOfVMwareDVSPvlanConfigSpec(d *schema.ResourceData) []types.VMwareDVSPvlanConfigSpec {
+	var specs []types.VMwareDVSPvlanConfigSpec
+	o, n := d.GetChange("pvlan_mapping")
+	os := o.(*schema.Set)
+	ns := n.(*schema.Set)
+
+	removed := os.Difference(ns).List()
+	added := ns.Difference(os).List()
+
+	isPromiscuous := func(v interface{}) bool {
+		return v.(map[string]interface{})["pvlan_type"].(string) == string(types.VmwareDistributedVirtualSwitchPvlanPortTypePromiscuous)
+	}
+	appendSpecs := func(entries []interface{}, promiscuous bool, op types.ConfigSpecOperation) {
+		for _, e := range entries {
+			if isPromiscuous(e) != promiscuous {
+				continue
+			}
+			specs = append(specs, types.VMwareDVSPvlanConfigSpec{
+				PvlanEntry: *expandVMwareDVSPvlanMapEntry(e.(map[string]interface{})),
+				Operation:  string(op),
+			})
+		}
+	}
+
+	appendSpecs(removed, false, types.ConfigSpecOperationRemove)
+	appendSpecs(removed, true, types.ConfigSpecOperationRemove)
+	appendSpecs(added, true, types.ConfigSpecOperationAdd)
+	appendSpecs(added, false, types.ConfigSpecOperationAdd)
+
+	return specs
+}
+
+// flattenSliceOfVMwareDVSPvlanMapEntry creates a set of all PVLAN mapping
+// entries for a supplied slice of VMwareDVSPvlanMapEntry.
+//
+// This is the flatten counterpart to expandSliceOfVMwareDVSPvlanConfigSpec.
+func flattenSliceOfVMwareDVSPvlanMapEntry(d *schema.ResourceData, entries []types.VMwareDVSPvlanMapEntry) error {
+	var mappings []map[string]interface{}
+	for _, e := range entries {
+		mappings = append(mappings, flattenVMwareDVSPvlanMapEntry(e))
+	}
+	if err := d.Set("pvlan_mapping", mappings); err != nil {
+		return err
+	}
+	return nil
+}
+
 // expandVMwareDVSConfigSpec reads certain ResourceData keys and
 // returns a VMwareDVSConfigSpec.
 func expandVMwareDVSConfigSpec(d *schema.ResourceData) *types.VMwareDVSConfigSpec {
@@ -551,6 +660,7 @@ func expandVMwareDVSConfigSpec(d *schema.ResourceData) *types.VMwareDVSConfigSpe
 		IpfixConfig:                 expandVMwareIpfixConfig(d),
 		LacpApiVersion:              d.Get("lacp_api_version").(string),
 		MulticastFilteringMode:      d.Get("multicast_filtering_mode").(string),
+		PvlanConfigSpec:             expandSliceOfVMwareDVSPvlanConfigSpec(d),
 	}
 	return obj
 }
@@ -599,6 +709,9 @@ func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConf
 	if err := flattenVMwareIpfixConfig(d, obj.IpfixConfig); err != nil {
 		return err
 	}
+	if err := flattenSliceOfVMwareDVSPvlanMapEntry(d, obj.PvlanConfig); err != nil {
+		return err
+	}
 	return nil
 }
 
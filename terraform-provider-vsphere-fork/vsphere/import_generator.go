@@ -0,0 +1,698 @@
+package vsphere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/list"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// importGeneratorUsage is the usage text for the generate-import command.
+const importGeneratorUsage = `Usage: terraform-provider-vsphere generate-import -path PATH [options]
+
+  Walks the datacenter, folder, or cluster at the inventory path PATH and
+  writes Terraform configuration for the virtual machines, folders, resource
+  pools, distributed port groups, tags, and permissions found there, along
+  with a script of matching terraform import commands.
+
+  The connection is configured with the same environment variables as the
+  provider, such as VSPHERE_SERVER, VSPHERE_USER, and VSPHERE_PASSWORD.
+  Setting VSPHERE_PROPERTY_CACHE=true is recommended for large inventories.
+
+Options:
+`
+
+// importGeneratorSkip lists attributes, by resource type and path within the
+// resource, that are left out of generated configuration even when set. These
+// are either read-only unless other options are set, or pin values that are
+// better left to vSphere, such as the host a DRS-managed VM is running on.
+var importGeneratorSkip = map[string][]string{
+	"vsphere_virtual_machine": {
+		"host_system_id",
+		"cpu_share_count",
+		"memory_share_count",
+		"disk.path",
+		"network_interface.mac_address",
+		"network_interface.bandwidth_share_count",
+	},
+	"vsphere_resource_pool": {
+		"cpu_shares",
+		"memory_shares",
+	},
+}
+
+// importGeneratorResource is a single resource that the generator imports and
+// writes configuration for.
+type importGeneratorResource struct {
+	Type     string
+	Name     string
+	ImportID string
+
+	data *schema.ResourceData
+}
+
+// Address returns the resource address, such as vsphere_folder.foo.
+func (r *importGeneratorResource) Address() string {
+	return r.Type + "." + r.Name
+}
+
+// importGenerator walks an inventory path and generates configuration and
+// import commands for the objects found.
+type importGenerator struct {
+	provider    *schema.Provider
+	client      *VSphereClient
+	stderr      io.Writer
+	parallelism int
+
+	resources []*importGeneratorResource
+	names     map[string]bool
+	ids       map[string]string
+}
+
+// RunImportGenerator runs the generate-import command with the supplied
+// arguments, and returns the exit status of the command.
+func RunImportGenerator(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate-import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, importGeneratorUsage)
+		fs.PrintDefaults()
+	}
+	root := fs.String("path", "", "The inventory path of the datacenter, folder, or cluster to walk, such as /dc1/vm/apps.")
+	out := fs.String("out", ".", "The directory to write imported.tf and import.sh to.")
+	withTags := fs.Bool("tags", true, "Generate tag categories and tags attached to the objects found.")
+	withPermissions := fs.Bool("permissions", true, "Generate permissions set on the objects found.")
+	parallelism := fs.Int("parallelism", 10, "The number of objects to read at once.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *root == "" || !strings.HasPrefix(*root, "/") {
+		fmt.Fprintln(stderr, "-path must be set to an absolute inventory path")
+		fs.Usage()
+		return 2
+	}
+
+	// The provider logs through the standard logger. Keep it quiet unless
+	// logging has been asked for in the usual way.
+	if os.Getenv("TF_LOG") == "" {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	p := Provider().(*schema.Provider)
+	if err := p.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{})); err != nil {
+		fmt.Fprintf(stderr, "error configuring vSphere connection: %s\n", err)
+		return 1
+	}
+	g := &importGenerator{
+		provider:    p,
+		client:      p.Meta().(*VSphereClient),
+		stderr:      stderr,
+		parallelism: *parallelism,
+		names:       make(map[string]bool),
+		ids:         make(map[string]string),
+	}
+	if err := g.walk(*root, *withTags, *withPermissions); err != nil {
+		fmt.Fprintf(stderr, "error walking %s: %s\n", *root, err)
+		return 1
+	}
+	g.load()
+	if err := g.write(*out, *root); err != nil {
+		fmt.Fprintf(stderr, "error writing output: %s\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Wrote %d resources to %s and %s\n", len(g.resources), filepath.Join(*out, "imported.tf"), filepath.Join(*out, "import.sh"))
+	return 0
+}
+
+// add queues a resource for import, assigning it a unique name.
+func (g *importGenerator) add(typ, name, importID string) {
+	base := importGeneratorName(name)
+	n := base
+	for i := 2; g.names[typ+"."+n]; i++ {
+		n = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.names[typ+"."+n] = true
+	g.resources = append(g.resources, &importGeneratorResource{
+		Type:     typ,
+		Name:     n,
+		ImportID: importID,
+	})
+}
+
+// walk finds the objects under the root inventory path and queues resources
+// for them.
+func (g *importGenerator) walk(root string, withTags, withPermissions bool) error {
+	client := g.client.vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+
+	rootRef, err := object.NewSearchIndex(client.Client).FindByInventoryPath(ctx, root)
+	if err != nil {
+		return err
+	}
+	if rootRef == nil {
+		return fmt.Errorf("no object found at inventory path %q", root)
+	}
+	finder := find.NewFinder(client.Client, false)
+	elements, err := finder.ManagedObjectList(ctx, path.Join(root, "..."), "Folder", "ResourcePool", "DistributedVirtualPortgroup", "VirtualMachine")
+	if err != nil {
+		return err
+	}
+	elements = append(elements, list.Element{Path: root, Object: rootRef.Reference()})
+
+	byType := make(map[string][]list.Element)
+	for _, e := range elements {
+		t := e.Object.Reference().Type
+		byType[t] = append(byType[t], e)
+	}
+
+	// VMs do not live under clusters and resource pools in the inventory, so
+	// when walking one of those, look for the VMs in the datacenter that run in
+	// the pools found.
+	switch rootRef.Reference().Type {
+	case "ClusterComputeResource", "ComputeResource", "ResourcePool":
+		dc := "/" + strings.SplitN(strings.TrimPrefix(root, "/"), "/", 2)[0]
+		vms, err := finder.ManagedObjectList(ctx, path.Join(dc, "vm", "..."), "VirtualMachine")
+		if err != nil {
+			return err
+		}
+		byType["VirtualMachine"], err = importGeneratorVMsInPools(ctx, client.Client, vms, byType["ResourcePool"])
+		if err != nil {
+			return err
+		}
+	}
+
+	var found []list.Element
+	for _, t := range []string{"Folder", "ResourcePool", "DistributedVirtualPortgroup", "VirtualMachine"} {
+		kept, err := importGeneratorFilter(ctx, client.Client, t, byType[t])
+		if err != nil {
+			return err
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i].Path < kept[j].Path })
+		found = append(found, kept...)
+	}
+
+	refs := make(map[types.ManagedObjectReference]string)
+	for _, e := range found {
+		ref := e.Object.Reference()
+		name := path.Base(e.Path)
+		switch ref.Type {
+		case "Folder":
+			g.add("vsphere_folder", name, e.Path)
+		case "ResourcePool":
+			g.add("vsphere_resource_pool", name, e.Path)
+		case "DistributedVirtualPortgroup":
+			g.add("vsphere_distributed_port_group", name, e.Path)
+		case "VirtualMachine":
+			g.add("vsphere_virtual_machine", name, e.Path)
+		}
+		refs[ref] = name
+	}
+	refs[rootRef.Reference()] = path.Base(root)
+
+	if withTags {
+		if err := g.walkTags(found); err != nil {
+			return err
+		}
+	}
+	if withPermissions {
+		if err := g.walkPermissions(refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importGeneratorVMsInPools returns the VMs that run in one of the supplied
+// resource pools.
+func importGeneratorVMsInPools(ctx context.Context, client *vim25.Client, vms, pools []list.Element) ([]list.Element, error) {
+	if len(vms) < 1 || len(pools) < 1 {
+		return nil, nil
+	}
+	inPool := make(map[types.ManagedObjectReference]bool)
+	for _, e := range pools {
+		inPool[e.Object.Reference()] = true
+	}
+	var refs []types.ManagedObjectReference
+	for _, e := range vms {
+		refs = append(refs, e.Object.Reference())
+	}
+	var props []mo.VirtualMachine
+	if err := property.DefaultCollector(client).Retrieve(ctx, refs, []string{"resourcePool"}, &props); err != nil {
+		return nil, err
+	}
+	keep := make(map[types.ManagedObjectReference]bool)
+	for _, vm := range props {
+		if vm.ResourcePool != nil && inPool[*vm.ResourcePool] {
+			keep[vm.Reference()] = true
+		}
+	}
+	var out []list.Element
+	for _, e := range vms {
+		if keep[e.Object.Reference()] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// importGeneratorFilter removes objects that cannot be managed by the
+// provider from a list of objects of the same type. These are VM templates,
+// the root resource pools of clusters and hosts, vApps, the top-level folders
+// of datacenters, and uplink port groups.
+func importGeneratorFilter(ctx context.Context, client *vim25.Client, t string, elements []list.Element) ([]list.Element, error) {
+	if len(elements) < 1 {
+		return nil, nil
+	}
+	var refs []types.ManagedObjectReference
+	for _, e := range elements {
+		refs = append(refs, e.Object.Reference())
+	}
+	pc := property.DefaultCollector(client)
+	keep := make(map[types.ManagedObjectReference]bool)
+	switch t {
+	case "VirtualMachine":
+		var props []mo.VirtualMachine
+		if err := pc.Retrieve(ctx, refs, []string{"config.template"}, &props); err != nil {
+			return nil, err
+		}
+		for _, p := range props {
+			keep[p.Reference()] = p.Config != nil && !p.Config.Template
+		}
+	case "DistributedVirtualPortgroup":
+		var props []mo.DistributedVirtualPortgroup
+		if err := pc.Retrieve(ctx, refs, []string{"config.uplink"}, &props); err != nil {
+			return nil, err
+		}
+		for _, p := range props {
+			keep[p.Reference()] = p.Config.Uplink == nil || !*p.Config.Uplink
+		}
+	default:
+		var props []mo.ManagedEntity
+		if err := pc.Retrieve(ctx, refs, []string{"parent"}, &props); err != nil {
+			return nil, err
+		}
+		for _, p := range props {
+			switch {
+			case p.Parent == nil:
+			case t == "Folder":
+				keep[p.Reference()] = p.Parent.Type != "Datacenter"
+			case t == "ResourcePool":
+				keep[p.Reference()] = p.Parent.Type == "ResourcePool"
+			}
+		}
+	}
+	var out []list.Element
+	for _, e := range elements {
+		ref := e.Object.Reference()
+		// vApps show up as resource pools, but are managed with their own
+		// resource, so filter them out by their type.
+		if ref.Type == t && keep[ref] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// walkTags queues the tag categories and tags attached to the supplied
+// objects.
+func (g *importGenerator) walkTags(elements []list.Element) error {
+	if g.client.restClient == nil || len(elements) < 1 {
+		return nil
+	}
+	tm, err := g.client.TagsManager()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+
+	// Look up attached tags in batches to keep request sizes reasonable on
+	// large inventories.
+	const batchSize = 500
+	tagIDs := make(map[string]bool)
+	for i := 0; i < len(elements); i += batchSize {
+		var objs []mo.Reference
+		for _, e := range elements[i:minInt(i+batchSize, len(elements))] {
+			objs = append(objs, e.Object.Reference())
+		}
+		attached, err := tm.GetAttachedTagsOnObjects(ctx, objs)
+		if err != nil {
+			return fmt.Errorf("error reading attached tags: %s", err)
+		}
+		for _, a := range attached {
+			for _, id := range a.TagIDs {
+				tagIDs[id] = true
+			}
+		}
+	}
+
+	var ids []string
+	for id := range tagIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	categories := make(map[string]string)
+	for _, id := range ids {
+		tag, err := tm.GetTag(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error reading tag %q: %s", id, err)
+		}
+		categoryName, ok := categories[tag.CategoryID]
+		if !ok {
+			category, err := tm.GetCategory(ctx, tag.CategoryID)
+			if err != nil {
+				return fmt.Errorf("error reading tag category %q: %s", tag.CategoryID, err)
+			}
+			categoryName = category.Name
+			categories[tag.CategoryID] = categoryName
+			g.add("vsphere_tag_category", categoryName, categoryName)
+		}
+		importID, err := json.Marshal(map[string]string{
+			"category_name": categoryName,
+			"tag_name":      tag.Name,
+		})
+		if err != nil {
+			return err
+		}
+		g.add("vsphere_tag", categoryName+"_"+tag.Name, string(importID))
+	}
+	return nil
+}
+
+// walkPermissions queues the permissions set directly on the supplied
+// objects, which are mapped to their names.
+func (g *importGenerator) walkPermissions(refs map[types.ManagedObjectReference]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	am := object.NewAuthorizationManager(g.client.vimClient.Client)
+	perms, err := am.RetrieveAllPermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading permissions: %s", err)
+	}
+	entities := make(map[types.ManagedObjectReference]bool)
+	for _, p := range perms {
+		if p.Entity == nil {
+			continue
+		}
+		if _, ok := refs[*p.Entity]; ok {
+			entities[*p.Entity] = true
+		}
+	}
+	var sorted []types.ManagedObjectReference
+	for ref := range entities {
+		sorted = append(sorted, ref)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+	for _, ref := range sorted {
+		g.add("vsphere_entity_permissions", refs[ref], ref.String())
+	}
+	return nil
+}
+
+// load imports and reads every queued resource, in parallel. Resources that
+// fail are reported and dropped.
+func (g *importGenerator) load() {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sem := make(chan struct{}, maxInt(g.parallelism, 1))
+	failed := make(map[*importGeneratorResource]bool)
+	for _, r := range g.resources {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *importGeneratorResource) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := g.loadResource(r); err != nil {
+				mu.Lock()
+				fmt.Fprintf(g.stderr, "warning: skipping %s (%s): %s\n", r.Address(), r.ImportID, err)
+				failed[r] = true
+				mu.Unlock()
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	var loaded []*importGeneratorResource
+	for _, r := range g.resources {
+		if failed[r] {
+			continue
+		}
+		loaded = append(loaded, r)
+		g.ids[r.data.Id()] = r.Address()
+	}
+	g.resources = loaded
+}
+
+// loadResource runs the importer and Read functions of a resource, the same
+// way that terraform import does.
+func (g *importGenerator) loadResource(r *importGeneratorResource) error {
+	res := g.provider.ResourcesMap[r.Type]
+	d := res.Data(nil)
+	d.SetId(r.ImportID)
+	if res.Importer != nil && res.Importer.State != nil {
+		ds, err := res.Importer.State(d, g.client)
+		if err != nil {
+			return err
+		}
+		if len(ds) < 1 {
+			return fmt.Errorf("importer returned no resources")
+		}
+		d = ds[0]
+	}
+	if err := res.Read(d, g.client); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return fmt.Errorf("resource not found on read")
+	}
+	r.data = d
+	return nil
+}
+
+// write writes the generated configuration and import script to the output
+// directory.
+func (g *importGenerator) write(dir, root string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var tf, sh bytes.Buffer
+	fmt.Fprintf(&tf, "# Generated by terraform-provider-vsphere generate-import from %s.\n", root)
+	fmt.Fprintf(&tf, "# Run terraform fmt to align attributes, and import.sh to import the resources.\n")
+	fmt.Fprintf(&tf, "#\n")
+	fmt.Fprintf(&tf, "# Virtual machines have no clone block, and are flagged as imported in state\n")
+	fmt.Fprintf(&tf, "# until the first apply. Disk labels are assigned in device order on import,\n")
+	fmt.Fprintf(&tf, "# and must not be changed.\n\n")
+	sh.WriteString("#!/bin/sh\nset -e\n\n")
+	for _, r := range g.resources {
+		g.renderResource(&tf, r)
+		fmt.Fprintf(&sh, "terraform import %s %s\n", importGeneratorShellQuote(r.Address()), importGeneratorShellQuote(r.ImportID))
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "imported.tf"), tf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "import.sh"), sh.Bytes(), 0755)
+}
+
+// renderResource writes the configuration block for a loaded resource.
+func (g *importGenerator) renderResource(w io.Writer, r *importGeneratorResource) {
+	sm := g.provider.ResourcesMap[r.Type].Schema
+	values := make(map[string]interface{})
+	for k := range sm {
+		values[k] = r.data.Get(k)
+	}
+	fmt.Fprintf(w, "resource %q %q {\n", r.Type, r.Name)
+	g.renderBody(w, r.Type, "", sm, values, 1)
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// renderBody writes the attributes and nested blocks of a block, attributes
+// first. Only attributes that can be set in configuration are written, and
+// optional attributes are only written when they differ from their defaults.
+func (g *importGenerator) renderBody(w io.Writer, typ, prefix string, sm map[string]*schema.Schema, values map[string]interface{}, depth int) {
+	pad := strings.Repeat("  ", depth)
+	var keys []string
+	for k, s := range sm {
+		if !s.Required && !s.Optional || s.Deprecated != "" || s.Removed != "" {
+			continue
+		}
+		if importGeneratorSkipped(typ, prefix+k) {
+			continue
+		}
+		if !s.Required && !importGeneratorShouldRender(s, values[k]) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var blocks []string
+	for _, k := range keys {
+		if _, ok := sm[k].Elem.(*schema.Resource); ok {
+			blocks = append(blocks, k)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s = %s\n", pad, k, g.renderValue(k, values[k]))
+	}
+	for _, k := range blocks {
+		elem := sm[k].Elem.(*schema.Resource)
+		for _, item := range importGeneratorList(values[k]) {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "\n%s%s {\n", pad, k)
+			g.renderBody(w, typ, prefix+k+".", elem.Schema, m, depth+1)
+			fmt.Fprintf(w, "%s}\n", pad)
+		}
+	}
+}
+
+// renderValue renders an attribute value as an HCL expression. IDs of other
+// generated resources are rendered as references to those resources.
+func (g *importGenerator) renderValue(key string, v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		if strings.HasSuffix(key, "id") || strings.HasSuffix(key, "ids") || key == "tags" {
+			if addr, ok := g.ids[x]; ok {
+				return addr + ".id"
+			}
+		}
+		return importGeneratorQuote(x)
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case map[string]interface{}:
+		var keys []string
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s = %s", importGeneratorQuote(k), g.renderValue("", x[k])))
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case []interface{}, *schema.Set:
+		var parts []string
+		for _, e := range importGeneratorList(x) {
+			parts = append(parts, g.renderValue(key, e))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return importGeneratorQuote(fmt.Sprint(v))
+}
+
+// importGeneratorSkipped returns true if an attribute is in the skip list for
+// a resource type.
+func importGeneratorSkipped(typ, key string) bool {
+	for _, k := range importGeneratorSkip[typ] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// importGeneratorShouldRender returns true if an optional attribute should be
+// written, which is when it is set to something other than its default.
+func importGeneratorShouldRender(s *schema.Schema, v interface{}) bool {
+	if s.Default != nil {
+		return !reflect.DeepEqual(s.Default, v)
+	}
+	switch x := v.(type) {
+	case nil:
+		return false
+	case string:
+		return x != ""
+	case int:
+		return x != 0
+	case float64:
+		return x != 0
+	case bool:
+		return x
+	case map[string]interface{}:
+		return len(x) > 0
+	}
+	return len(importGeneratorList(v)) > 0
+}
+
+// importGeneratorList returns the elements of a list or set value.
+func importGeneratorList(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case []interface{}:
+		return x
+	case *schema.Set:
+		return x.List()
+	}
+	return nil
+}
+
+// importGeneratorName turns an inventory name into a valid resource name.
+func importGeneratorName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	n := b.String()
+	if n == "" || !(n[0] >= 'a' && n[0] <= 'z' || n[0] == '_') {
+		n = "_" + n
+	}
+	return n
+}
+
+// importGeneratorQuote quotes a string for HCL, escaping template sequences.
+func importGeneratorQuote(s string) string {
+	q := strconv.Quote(s)
+	q = strings.Replace(q, "${", "$${", -1)
+	return strings.Replace(q, "%{", "%%{", -1)
+}
+
+// importGeneratorShellQuote quotes a string for a POSIX shell.
+func importGeneratorShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
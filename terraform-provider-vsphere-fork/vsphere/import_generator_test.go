@@ -0,0 +1,98 @@
+package vsphere
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestImportGeneratorRenderResource(t *testing.T) {
+	p := Provider().(*schema.Provider)
+	g := &importGenerator{
+		provider: p,
+		ids: map[string]string{
+			"group-v1": "vsphere_folder.parent",
+		},
+	}
+
+	d := p.ResourcesMap["vsphere_folder"].Data(nil)
+	d.SetId("group-v2")
+	d.Set("path", "parent/${child}")
+	d.Set("type", "vm")
+	d.Set("datacenter_id", "datacenter-1")
+
+	var buf bytes.Buffer
+	g.renderResource(&buf, &importGeneratorResource{
+		Type: "vsphere_folder",
+		Name: "child",
+		data: d,
+	})
+	expected := `resource "vsphere_folder" "child" {
+  datacenter_id = "datacenter-1"
+  path = "parent/$${child}"
+  type = "vm"
+}
+
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	d = p.ResourcesMap["vsphere_entity_permissions"].Data(nil)
+	d.SetId("group-v1")
+	d.Set("entity_id", "group-v1")
+	d.Set("entity_type", "Folder")
+	d.Set("permissions", []interface{}{
+		map[string]interface{}{
+			"user_or_group": `VSPHERE.LOCAL\admins`,
+			"propagate":     true,
+			"is_group":      true,
+			"role_id":       "-1",
+		},
+	})
+	buf.Reset()
+	g.renderResource(&buf, &importGeneratorResource{
+		Type: "vsphere_entity_permissions",
+		Name: "parent",
+		data: d,
+	})
+	expected = `resource "vsphere_entity_permissions" "parent" {
+  entity_id = vsphere_folder.parent.id
+  entity_type = "Folder"
+
+  permissions {
+    is_group = true
+    propagate = true
+    role_id = "-1"
+    user_or_group = "VSPHERE.LOCAL\\admins"
+  }
+}
+
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestImportGeneratorName(t *testing.T) {
+	cases := map[string]string{
+		"web01":      "web01",
+		"Web Server": "web_server",
+		"10.0.0.1":   "_10_0_0_1",
+		"db-primary": "db-primary",
+		"café vm":    "caf__vm",
+	}
+	for in, expected := range cases {
+		if actual := importGeneratorName(in); actual != expected {
+			t.Fatalf("%q: expected %q, got %q", in, expected, actual)
+		}
+	}
+}
+
+func TestImportGeneratorShellQuote(t *testing.T) {
+	expected := `'/dc1/vm/bob'\''s vm'`
+	if actual := importGeneratorShellQuote("/dc1/vm/bob's vm"); actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
@@ -262,3 +262,32 @@ func moveHostOutOf(cluster *object.ClusterComputeResource, host *object.HostSyst
 	log.Printf("[DEBUG] Host %q moved out of cluster %q successfully", host.Name(), cluster.Name())
 	return nil
 }
+
+// PlaceVM asks DRS for placement recommendations for a virtual machine in the
+// cluster. An error is returned if DRS does not return any recommendations.
+func PlaceVM(cluster *object.ClusterComputeResource, spec types.PlacementSpec) (*types.PlacementResult, error) {
+	log.Printf("[DEBUG] Acquiring DRS placement recommendations from cluster %q (type: %q)", cluster.InventoryPath, spec.PlacementType)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	result, err := cluster.PlaceVm(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Recommendations) < 1 {
+		var reasons []string
+		if result.DrsFault != nil {
+			for _, f := range result.DrsFault.FaultsByVm {
+				for _, fault := range f.GetClusterDrsFaultsFaultsByVm().Fault {
+					if fault.LocalizedMessage != "" {
+						reasons = append(reasons, fault.LocalizedMessage)
+					}
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			return nil, fmt.Errorf("no DRS placement recommendations were found for cluster %q: %s", cluster.InventoryPath, strings.Join(reasons, "; "))
+		}
+		return nil, fmt.Errorf("no DRS placement recommendations were found for cluster %q", cluster.InventoryPath)
+	}
+	return result, nil
+}
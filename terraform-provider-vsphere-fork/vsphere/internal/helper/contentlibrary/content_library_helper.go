@@ -7,11 +7,14 @@ import (
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
 	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vapi/library"
 	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vapi/vcenter"
 	"log"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -131,6 +134,52 @@ func IsContentLibraryItem(c *rest.Client, id string) bool {
 	return false
 }
 
+// itemStorage is a file of a library item as stored on a storage backing.
+type itemStorage struct {
+	Name           string                  `json:"name"`
+	StorageBacking library.StorageBackings `json:"storage_backing"`
+	StorageURIs    []string                `json:"storage_uris"`
+}
+
+// ItemISOPath accepts a Content Library item ID and returns the datastore path
+// of the ISO file of the item, for use as CDROM backing.
+func ItemISOPath(c *govmomi.Client, rc *rest.Client, id string) (string, error) {
+	log.Printf("[DEBUG] contentlibrary.ItemISOPath: Resolving ISO file of library item %s", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	url := rc.Resource("/com/vmware/content/library/item/storage").WithParam("library_item_id", id)
+	var files []itemStorage
+	if err := rc.Do(ctx, url.Request(http.MethodGet), &files); err != nil {
+		return "", provider.ProviderError(id, "ItemISOPath", err)
+	}
+	for _, f := range files {
+		if !strings.EqualFold(filepath.Ext(f.Name), ".iso") {
+			continue
+		}
+		if f.StorageBacking.Type != "DATASTORE" || len(f.StorageURIs) < 1 {
+			return "", fmt.Errorf("ISO file %q of library item %s is not stored on a datastore", f.Name, id)
+		}
+		ds, err := datastore.FromID(c, f.StorageBacking.DatastoreID)
+		if err != nil {
+			return "", err
+		}
+		props, err := datastore.Properties(ds)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(f.StorageURIs[0], props.Summary.Url) {
+			return "", fmt.Errorf("ISO file %q of library item %s is not on datastore %q", f.Name, id, props.Name)
+		}
+		dp := object.DatastorePath{
+			Datastore: props.Name,
+			Path:      strings.TrimPrefix(f.StorageURIs[0], props.Summary.Url),
+		}
+		log.Printf("[DEBUG] contentlibrary.ItemISOPath: Library item %s resolved to %s", id, dp.String())
+		return dp.String(), nil
+	}
+	return "", fmt.Errorf("library item %s does not contain an ISO file", id)
+}
+
 // CreateLibraryItem creates an item in a Content Library.
 func CreateLibraryItem(c *rest.Client, l *library.Library, name string, desc string, t string, files []interface{}) (string, error) {
 	log.Printf("[DEBUG] contentlibrary.CreateLibraryItem: Creating content library item %s.", name)
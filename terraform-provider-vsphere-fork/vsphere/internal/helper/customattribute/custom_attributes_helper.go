@@ -132,14 +132,21 @@ func GetDiffProcessorIfAttributesDefined(client *govmomi.Client, d *schema.Resou
 		// No custom attributes defined
 		return nil, nil
 	}
+	return GetDiffProcessor(client, old.(map[string]interface{}), new.(map[string]interface{}))
+}
+
+// GetDiffProcessor returns a CustomAttributeDiffProcessor for an explicit set
+// of old and new attribute values, for callers that do not diff the
+// ConfigKey attribute of a resource.
+func GetDiffProcessor(client *govmomi.Client, old, new map[string]interface{}) (*CustomAttributeDiffProcessor, error) {
 	fm, err := object.GetCustomFieldsManager(client.Client)
 	if err != nil {
 		return nil, err
 	}
 	return &CustomAttributeDiffProcessor{
 		fm:            fm,
-		oldAttributes: old.(map[string]interface{}),
-		newAttributes: new.(map[string]interface{}),
+		oldAttributes: old,
+		newAttributes: new,
 	}, nil
 }
 
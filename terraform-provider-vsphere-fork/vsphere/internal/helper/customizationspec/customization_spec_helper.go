@@ -0,0 +1,147 @@
+package customizationspec
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// TypeLinux is the customization spec type for Linux guests.
+	TypeLinux = "Linux"
+
+	// TypeWindows is the customization spec type for Windows guests.
+	TypeWindows = "Windows"
+)
+
+// TypeAllowedValues is a list of the valid customization spec types.
+var TypeAllowedValues = []string{
+	TypeLinux,
+	TypeWindows,
+}
+
+// OSFamily returns the guest OS family for a customization spec type, in the
+// form returned by resourcepool.OSFamily.
+func OSFamily(specType string) string {
+	switch specType {
+	case TypeLinux:
+		return string(types.VirtualMachineGuestOsFamilyLinuxGuest)
+	case TypeWindows:
+		return string(types.VirtualMachineGuestOsFamilyWindowsGuest)
+	}
+	return ""
+}
+
+// NotFoundError is an error type that is returned when a customization spec
+// could not be found by name.
+type NotFoundError struct {
+	Name string
+}
+
+// Error implements error for NotFoundError.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("customization specification %q not found", e.Name)
+}
+
+// IsNotFoundError checks to see if an error is a NotFoundError.
+func IsNotFoundError(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+// manager returns the CustomizationSpecManager for a client, validating that
+// the connection is to vCenter first.
+func manager(client *govmomi.Client) (*object.CustomizationSpecManager, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	return object.NewCustomizationSpecManager(client.Client), nil
+}
+
+// FromName fetches a customization specification item by its name. A
+// NotFoundError is returned if the spec does not exist.
+func FromName(client *govmomi.Client, name string) (*types.CustomizationSpecItem, error) {
+	log.Printf("[DEBUG] Fetching customization specification %q", name)
+	m, err := manager(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	exists, err := m.DoesCustomizationSpecExist(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &NotFoundError{Name: name}
+	}
+	item, err := m.GetCustomizationSpec(ctx, name)
+	if err != nil {
+		if viapi.IsAnyNotFoundError(err) {
+			return nil, &NotFoundError{Name: name}
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Create creates a new customization specification item.
+func Create(client *govmomi.Client, item types.CustomizationSpecItem) error {
+	log.Printf("[DEBUG] Creating customization specification %q", item.Info.Name)
+	m, err := manager(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.CreateCustomizationSpec(ctx, item)
+}
+
+// Overwrite replaces an existing customization specification item. The
+// change version of the item is refreshed from the server before the update
+// is sent, as vCenter rejects updates with a stale change version.
+func Overwrite(client *govmomi.Client, item types.CustomizationSpecItem) error {
+	log.Printf("[DEBUG] Updating customization specification %q", item.Info.Name)
+	current, err := FromName(client, item.Info.Name)
+	if err != nil {
+		return err
+	}
+	item.Info.ChangeVersion = current.Info.ChangeVersion
+	m, err := manager(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.OverwriteCustomizationSpec(ctx, item)
+}
+
+// Rename renames a customization specification item.
+func Rename(client *govmomi.Client, name, newName string) error {
+	log.Printf("[DEBUG] Renaming customization specification %q to %q", name, newName)
+	m, err := manager(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.RenameCustomizationSpec(ctx, name, newName)
+}
+
+// Delete removes a customization specification item.
+func Delete(client *govmomi.Client, name string) error {
+	log.Printf("[DEBUG] Deleting customization specification %q", name)
+	m, err := manager(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.DeleteCustomizationSpec(ctx, name)
+}
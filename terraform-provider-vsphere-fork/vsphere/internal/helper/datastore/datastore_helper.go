@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/propertycache"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -91,6 +92,9 @@ func Properties(ds *object.Datastore) (*mo.Datastore, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.Datastore
+	if propertycache.Get(ds.Client(), ds.Reference(), &props) {
+		return &props, nil
+	}
 	if err := ds.Properties(ctx, ds.Reference(), nil, &props); err != nil {
 		return nil, err
 	}
@@ -216,6 +220,69 @@ func searchDatastore(ds *object.Datastore, name string) (*types.HostDatastoreBro
 	return &r, nil
 }
 
+// SearchFiles searches a directory of a datastore for files matching the
+// supplied glob patterns, descending into sub-folders if recursive is set.
+// File type queries are included in the search so that the returned file info
+// is of the specific type for disks, ISO images, folders, and other VM files.
+//
+// The directory should be a bare path, not a datastore path. An empty path
+// searches from the root of the datastore.
+func SearchFiles(ds *object.Datastore, dir string, patterns []string, recursive bool) ([]types.HostDatastoreBrowserSearchResults, error) {
+	browser, err := Browser(ds)
+	if err != nil {
+		return nil, err
+	}
+	dp := &object.DatastorePath{
+		Datastore: ds.Name(),
+		Path:      dir,
+	}
+	spec := &types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: patterns,
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			FileOwner:    types.NewBool(true),
+			Modification: true,
+		},
+		Query: []types.BaseFileQuery{
+			&types.FolderFileQuery{},
+			&types.VmDiskFileQuery{},
+			&types.IsoImageFileQuery{},
+			&types.FloppyImageFileQuery{},
+			&types.VmConfigFileQuery{},
+			&types.TemplateConfigFileQuery{},
+			&types.VmNvramFileQuery{},
+			&types.VmSnapshotFileQuery{},
+			&types.VmLogFileQuery{},
+			&types.FileQuery{},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var task *object.Task
+	if recursive {
+		task, err = browser.SearchDatastoreSubFolders(ctx, dp.String(), spec)
+	} else {
+		task, err = browser.SearchDatastore(ctx, dp.String(), spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch r := info.Result.(type) {
+	case types.ArrayOfHostDatastoreBrowserSearchResults:
+		return r.HostDatastoreBrowserSearchResults, nil
+	case types.HostDatastoreBrowserSearchResults:
+		return []types.HostDatastoreBrowserSearchResults{r}, nil
+	}
+	return nil, fmt.Errorf("unexpected search result type %T", info.Result)
+}
+
 // FileExists takes a path in the datastore and checks to see if it exists.
 //
 // The path should be a bare path, not a datastore path. Globs are not allowed.
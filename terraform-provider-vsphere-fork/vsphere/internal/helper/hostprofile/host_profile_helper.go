@@ -0,0 +1,208 @@
+package hostprofile
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Reference returns the managed object reference for a host profile ID.
+func Reference(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "HostProfile",
+		Value: id,
+	}
+}
+
+// managerRefs returns the HostProfileManager and ProfileComplianceManager
+// references for a client, validating that the connection is to vCenter
+// first.
+func managerRefs(client *govmomi.Client) (types.ManagedObjectReference, types.ManagedObjectReference, error) {
+	var hpm, pcm types.ManagedObjectReference
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return hpm, pcm, err
+	}
+	sc := client.Client.ServiceContent
+	if sc.HostProfileManager == nil || sc.ComplianceManager == nil {
+		return hpm, pcm, fmt.Errorf("host profiles are not supported on this connection")
+	}
+	return *sc.HostProfileManager, *sc.ComplianceManager, nil
+}
+
+// Properties returns the HostProfile managed object for a host profile ID.
+func Properties(client *govmomi.Client, id string) (*mo.HostProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.HostProfile
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, Reference(id), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// Create extracts a new host profile from a reference host and returns the ID
+// of the new profile.
+func Create(client *govmomi.Client, name, description string, host *object.HostSystem) (string, error) {
+	log.Printf("[DEBUG] Creating host profile %q from host %q", name, host.Reference().Value)
+	hpm, _, err := managerRefs(client)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.CreateProfile{
+		This: hpm,
+		CreateSpec: &types.HostProfileHostBasedConfigSpec{
+			HostProfileConfigSpec: types.HostProfileConfigSpec{
+				ProfileCreateSpec: types.ProfileCreateSpec{
+					Name:       name,
+					Annotation: description,
+					Enabled:    types.NewBool(true),
+				},
+			},
+			Host:                 host.Reference(),
+			UseHostProfileEngine: types.NewBool(true),
+		},
+	}
+	res, err := methods.CreateProfile(ctx, client.Client, &req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval.Value, nil
+}
+
+// UpdateFromHost re-extracts an existing host profile from a reference host,
+// which becomes the new reference host for the profile.
+func UpdateFromHost(client *govmomi.Client, id, name, description string, host *object.HostSystem) error {
+	log.Printf("[DEBUG] Updating host profile %q from host %q", id, host.Reference().Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateHostProfile{
+		This: Reference(id),
+		Config: &types.HostProfileHostBasedConfigSpec{
+			HostProfileConfigSpec: types.HostProfileConfigSpec{
+				ProfileCreateSpec: types.ProfileCreateSpec{
+					Name:       name,
+					Annotation: description,
+					Enabled:    types.NewBool(true),
+				},
+			},
+			Host:                 host.Reference(),
+			UseHostProfileEngine: types.NewBool(true),
+		},
+	}
+	_, err := methods.UpdateHostProfile(ctx, client.Client, &req)
+	return err
+}
+
+// UpdateInfo changes the name and description of a host profile, leaving the
+// profile contents unchanged.
+func UpdateInfo(client *govmomi.Client, id, name, description string) error {
+	log.Printf("[DEBUG] Updating name and description of host profile %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateHostProfile{
+		This: Reference(id),
+		Config: &types.HostProfileCompleteConfigSpec{
+			HostProfileConfigSpec: types.HostProfileConfigSpec{
+				ProfileCreateSpec: types.ProfileCreateSpec{
+					Name:       name,
+					Annotation: description,
+				},
+			},
+		},
+	}
+	_, err := methods.UpdateHostProfile(ctx, client.Client, &req)
+	return err
+}
+
+// Associate attaches hosts or clusters to a host profile.
+func Associate(client *govmomi.Client, id string, entities []types.ManagedObjectReference) error {
+	if len(entities) < 1 {
+		return nil
+	}
+	log.Printf("[DEBUG] Attaching %d entities to host profile %q", len(entities), id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.AssociateProfile{
+		This:   Reference(id),
+		Entity: entities,
+	}
+	_, err := methods.AssociateProfile(ctx, client.Client, &req)
+	return err
+}
+
+// Dissociate detaches hosts or clusters from a host profile.
+func Dissociate(client *govmomi.Client, id string, entities []types.ManagedObjectReference) error {
+	if len(entities) < 1 {
+		return nil
+	}
+	log.Printf("[DEBUG] Detaching %d entities from host profile %q", len(entities), id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.DissociateProfile{
+		This:   Reference(id),
+		Entity: entities,
+	}
+	_, err := methods.DissociateProfile(ctx, client.Client, &req)
+	return err
+}
+
+// Delete destroys a host profile.
+func Delete(client *govmomi.Client, id string) error {
+	log.Printf("[DEBUG] Deleting host profile %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.DestroyProfile{
+		This: Reference(id),
+	}
+	_, err := methods.DestroyProfile(ctx, client.Client, &req)
+	return err
+}
+
+// CheckCompliance runs a compliance check of a host profile against the
+// supplied entities. If no entities are supplied, all entities attached to
+// the profile are checked.
+func CheckCompliance(client *govmomi.Client, id string, entities []types.ManagedObjectReference) ([]types.ComplianceResult, error) {
+	log.Printf("[DEBUG] Checking compliance for host profile %q", id)
+	_, pcm, err := managerRefs(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.CheckCompliance_Task{
+		This:    pcm,
+		Profile: []types.ManagedObjectReference{Reference(id)},
+		Entity:  entities,
+	}
+	res, err := methods.CheckCompliance_Task(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if info.Result == nil {
+		return nil, nil
+	}
+	results, ok := info.Result.(types.ArrayOfComplianceResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected compliance check result type %T", info.Result)
+	}
+	return results.ComplianceResult, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/propertycache"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
@@ -63,6 +64,9 @@ func Properties(host *object.HostSystem) (*mo.HostSystem, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.HostSystem
+	if propertycache.Get(host.Client(), host.Reference(), &props) {
+		return &props, nil
+	}
 	if err := host.Properties(ctx, host.Reference(), nil, &props); err != nil {
 		return nil, err
 	}
@@ -198,3 +202,83 @@ func GetConnectionState(host *object.HostSystem) (types.HostSystemConnectionStat
 
 	return hostProps.Runtime.ConnectionState, nil
 }
+
+// certificateManager returns the certificate manager of a host.
+func certificateManager(ctx context.Context, host *object.HostSystem) (*object.HostCertificateManager, error) {
+	m, err := host.ConfigManager().CertificateManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading certificate manager for host %q: %s", host.Name(), err)
+	}
+	return m, nil
+}
+
+// CertificateInfo returns information about the certificate currently
+// installed on a host.
+func CertificateInfo(host *object.HostSystem) (*object.HostCertificateInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := certificateManager(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return m.CertificateInfo(ctx)
+}
+
+// GenerateCertificateSigningRequest generates a new key pair on a host and
+// returns a certificate signing request for it. If dn is empty, the host's
+// name or IP address is used as the common name, depending on useIP.
+func GenerateCertificateSigningRequest(host *object.HostSystem, dn string, useIP bool) (string, error) {
+	log.Printf("[DEBUG] Generating certificate signing request on host %q", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := certificateManager(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if dn != "" {
+		return m.GenerateCertificateSigningRequestByDn(ctx, dn)
+	}
+	return m.GenerateCertificateSigningRequest(ctx, useIP)
+}
+
+// InstallServerCertificate installs a signed certificate on a host. The
+// certificate must match the key pair of the last signing request generated
+// on the host.
+func InstallServerCertificate(host *object.HostSystem, cert string) error {
+	log.Printf("[DEBUG] Installing server certificate on host %q", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := certificateManager(ctx, host)
+	if err != nil {
+		return err
+	}
+	return m.InstallServerCertificate(ctx, cert)
+}
+
+// ReplaceCACertificates replaces the trusted CA certificates of a host. The
+// host's certificate revocation lists are kept as they are.
+func ReplaceCACertificates(host *object.HostSystem, certs []string) error {
+	log.Printf("[DEBUG] Replacing CA certificates on host %q", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := certificateManager(ctx, host)
+	if err != nil {
+		return err
+	}
+	crls, err := m.ListCACertificateRevocationLists(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing certificate revocation lists: %s", err)
+	}
+	return m.ReplaceCACertificatesAndCRLs(ctx, certs, crls)
+}
+
+// CACertificates returns the trusted CA certificates of a host.
+func CACertificates(host *object.HostSystem) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := certificateManager(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return m.ListCACertificates(ctx)
+}
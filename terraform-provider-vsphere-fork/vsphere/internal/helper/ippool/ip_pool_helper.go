@@ -0,0 +1,217 @@
+package ippool
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// NotFoundError is returned when an IP pool cannot be found in a datacenter.
+type NotFoundError struct {
+	ID         int32
+	Datacenter string
+}
+
+// Error implements error for NotFoundError.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("IP pool %d not found in datacenter %q", e.ID, e.Datacenter)
+}
+
+// IsNotFoundError returns true if the error is a NotFoundError.
+func IsNotFoundError(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+// managerRef returns the IpPoolManager reference for a client, validating
+// that the connection is to vCenter first.
+func managerRef(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return types.ManagedObjectReference{}, err
+	}
+	if client.Client.ServiceContent.IpPoolManager == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("network protocol profiles are not supported on this connection")
+	}
+	return *client.Client.ServiceContent.IpPoolManager, nil
+}
+
+// List returns all of the IP pools in a datacenter.
+func List(client *govmomi.Client, dc *object.Datacenter) ([]types.IpPool, error) {
+	ipm, err := managerRef(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.QueryIpPools{
+		This: ipm,
+		Dc:   dc.Reference(),
+	}
+	res, err := methods.QueryIpPools(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
+
+// FromID locates an IP pool in a datacenter by its ID. A NotFoundError is
+// returned if the pool does not exist.
+func FromID(client *govmomi.Client, dc *object.Datacenter, id int32) (*types.IpPool, error) {
+	log.Printf("[DEBUG] Locating IP pool %d in datacenter %q", id, dc.Reference().Value)
+	pools, err := List(client, dc)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		if pool.Id == id {
+			return &pool, nil
+		}
+	}
+	return nil, &NotFoundError{ID: id, Datacenter: dc.Reference().Value}
+}
+
+// Create creates an IP pool in a datacenter and returns the new pool's ID.
+func Create(client *govmomi.Client, dc *object.Datacenter, pool types.IpPool) (int32, error) {
+	log.Printf("[DEBUG] Creating IP pool %q in datacenter %q", pool.Name, dc.Reference().Value)
+	ipm, err := managerRef(client)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.CreateIpPool{
+		This: ipm,
+		Dc:   dc.Reference(),
+		Pool: pool,
+	}
+	res, err := methods.CreateIpPool(ctx, client.Client, &req)
+	if err != nil {
+		return 0, err
+	}
+	return res.Returnval, nil
+}
+
+// updateIPPoolBody is the body of an UpdateIpPool call. It is used instead
+// of methods.UpdateIpPool so that the pool can be sent with empty values.
+type updateIPPoolBody struct {
+	Req    *updateIPPoolRequest        `xml:"urn:vim25 UpdateIpPool,omitempty"`
+	Res    *types.UpdateIpPoolResponse `xml:"UpdateIpPoolResponse,omitempty"`
+	Fault_ *soap.Fault                 `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault,omitempty"`
+}
+
+func (b *updateIPPoolBody) Fault() *soap.Fault { return b.Fault_ }
+
+type updateIPPoolRequest struct {
+	This types.ManagedObjectReference `xml:"_this"`
+	Dc   types.ManagedObjectReference `xml:"dc"`
+	Pool updateIPPool                 `xml:"pool"`
+}
+
+// updateIPPool mirrors types.IpPool, but always sends its string settings.
+// UpdateIpPool leaves settings that are missing from the request as they are,
+// and types.IpPool leaves empty values out, so settings could otherwise not be
+// cleared.
+type updateIPPool struct {
+	ID                 int32                     `xml:"id"`
+	Name               string                    `xml:"name"`
+	Ipv4Config         updateIPPoolConfigInfo    `xml:"ipv4Config"`
+	Ipv6Config         updateIPPoolConfigInfo    `xml:"ipv6Config"`
+	DNSDomain          string                    `xml:"dnsDomain"`
+	DNSSearchPath      string                    `xml:"dnsSearchPath"`
+	HostPrefix         string                    `xml:"hostPrefix"`
+	HTTPProxy          string                    `xml:"httpProxy"`
+	NetworkAssociation []types.IpPoolAssociation `xml:"networkAssociation,omitempty"`
+}
+
+// updateIPPoolConfigInfo mirrors types.IpPoolIpPoolConfigInfo, but always
+// sends its string settings. DNS servers are a list, so an empty list still
+// cannot be sent.
+type updateIPPoolConfigInfo struct {
+	SubnetAddress       string   `xml:"subnetAddress"`
+	Netmask             string   `xml:"netmask"`
+	Gateway             string   `xml:"gateway"`
+	Range               string   `xml:"range"`
+	DNS                 []string `xml:"dns,omitempty"`
+	DhcpServerAvailable bool     `xml:"dhcpServerAvailable"`
+	IPPoolEnabled       bool     `xml:"ipPoolEnabled"`
+}
+
+// newUpdateIPPool converts an IpPool for an UpdateIpPool call. A missing IPv4
+// or IPv6 configuration is sent as an empty one, which is how vSphere reports
+// protocols that are not configured.
+func newUpdateIPPool(pool types.IpPool) updateIPPool {
+	return updateIPPool{
+		ID:                 pool.Id,
+		Name:               pool.Name,
+		Ipv4Config:         newUpdateIPPoolConfigInfo(pool.Ipv4Config),
+		Ipv6Config:         newUpdateIPPoolConfigInfo(pool.Ipv6Config),
+		DNSDomain:          pool.DnsDomain,
+		DNSSearchPath:      pool.DnsSearchPath,
+		HostPrefix:         pool.HostPrefix,
+		HTTPProxy:          pool.HttpProxy,
+		NetworkAssociation: pool.NetworkAssociation,
+	}
+}
+
+func newUpdateIPPoolConfigInfo(info *types.IpPoolIpPoolConfigInfo) updateIPPoolConfigInfo {
+	if info == nil {
+		return updateIPPoolConfigInfo{}
+	}
+	return updateIPPoolConfigInfo{
+		SubnetAddress:       info.SubnetAddress,
+		Netmask:             info.Netmask,
+		Gateway:             info.Gateway,
+		Range:               info.Range,
+		DNS:                 info.Dns,
+		DhcpServerAvailable: info.DhcpServerAvailable != nil && *info.DhcpServerAvailable,
+		IPPoolEnabled:       info.IpPoolEnabled != nil && *info.IpPoolEnabled,
+	}
+}
+
+// Update updates an IP pool in a datacenter. The pool is matched on its ID.
+// Empty settings in the pool are cleared, with the exception of DNS servers
+// and network associations, which are left as they are when empty.
+func Update(client *govmomi.Client, dc *object.Datacenter, pool types.IpPool) error {
+	log.Printf("[DEBUG] Updating IP pool %d in datacenter %q", pool.Id, dc.Reference().Value)
+	ipm, err := managerRef(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var reqBody, resBody updateIPPoolBody
+	reqBody.Req = &updateIPPoolRequest{
+		This: ipm,
+		Dc:   dc.Reference(),
+		Pool: newUpdateIPPool(pool),
+	}
+	return client.Client.RoundTrip(ctx, &reqBody, &resBody)
+}
+
+// Destroy removes an IP pool from a datacenter. If force is true, the pool is
+// removed even if addresses from it are still allocated.
+func Destroy(client *govmomi.Client, dc *object.Datacenter, id int32, force bool) error {
+	log.Printf("[DEBUG] Destroying IP pool %d in datacenter %q", id, dc.Reference().Value)
+	ipm, err := managerRef(client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.DestroyIpPool{
+		This:  ipm,
+		Dc:    dc.Reference(),
+		Id:    id,
+		Force: force,
+	}
+	_, err = methods.DestroyIpPool(ctx, client.Client, &req)
+	return err
+}
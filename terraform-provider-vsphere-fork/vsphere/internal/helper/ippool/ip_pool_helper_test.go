@@ -0,0 +1,39 @@
+package ippool
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestNewUpdateIPPool(t *testing.T) {
+	pool := types.IpPool{
+		Id:   1,
+		Name: "pool",
+		Ipv4Config: &types.IpPoolIpPoolConfigInfo{
+			SubnetAddress: "10.10.0.0",
+			Netmask:       "255.255.255.0",
+			Dns:           []string{"10.10.0.2"},
+		},
+	}
+	b, err := xml.Marshal(newUpdateIPPool(pool))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	actual := string(b)
+	expected := []string{
+		"<subnetAddress>10.10.0.0</subnetAddress><netmask>255.255.255.0</netmask><gateway></gateway><range></range><dns>10.10.0.2</dns>",
+		"<ipv6Config><subnetAddress></subnetAddress><netmask></netmask><gateway></gateway><range></range><dhcpServerAvailable>false</dhcpServerAvailable><ipPoolEnabled>false</ipPoolEnabled></ipv6Config>",
+		"<dnsDomain></dnsDomain><dnsSearchPath></dnsSearchPath><hostPrefix></hostPrefix><httpProxy></httpProxy>",
+	}
+	for _, e := range expected {
+		if !strings.Contains(actual, e) {
+			t.Fatalf("expected %s to contain %s", actual, e)
+		}
+	}
+	if strings.Contains(actual, "networkAssociation") {
+		t.Fatalf("expected %s not to contain networkAssociation", actual)
+	}
+}
@@ -0,0 +1,174 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/units"
+)
+
+const (
+	// ModeError reports failed preflight checks as plan errors.
+	ModeError = "error"
+
+	// ModeWarn logs failed preflight checks as warnings and lets the plan
+	// continue. The provider cannot add warnings to a plan, so these are only
+	// visible in the log, with TF_LOG set to WARN or a more verbose level.
+	ModeWarn = "warn"
+
+	// ModeOff skips preflight checks altogether.
+	ModeOff = "off"
+)
+
+// Modes are the allowed values for the preflight_checks provider setting.
+var Modes = []string{ModeError, ModeWarn, ModeOff}
+
+// Report handles the failed checks for a resource according to mode. In
+// ModeError the failures are returned as a single error, otherwise they are
+// logged as warnings and nil is returned. Logged warnings are not shown in the
+// plan output, only in the log when TF_LOG is set.
+func Report(mode, id string, errs []error) error {
+	if len(errs) < 1 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	if mode == ModeError {
+		return fmt.Errorf("preflight checks failed: %s", strings.Join(msgs, "; "))
+	}
+	for _, msg := range msgs {
+		log.Printf("[WARN] %s: preflight check failed: %s", id, msg)
+	}
+	return nil
+}
+
+// DatastoreSpace checks that the datastore with the supplied ID has at least
+// required bytes of free space.
+func DatastoreSpace(client *govmomi.Client, id string, required int64) error {
+	ds, err := datastore.FromID(client, id)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore ID %q: %s", id, err)
+	}
+	props, err := datastore.Properties(ds)
+	if err != nil {
+		return fmt.Errorf("error fetching properties for datastore %q: %s", id, err)
+	}
+	log.Printf("[DEBUG] Checking %s required against %s free on datastore %q", units.ByteSize(required), units.ByteSize(props.Summary.FreeSpace), props.Name)
+	return checkSpace("datastore", props.Name, required, props.Summary.FreeSpace)
+}
+
+// DatastoreClusterSpace checks that the datastore cluster with the supplied
+// ID has at least required bytes of free space across its member datastores.
+func DatastoreClusterSpace(client *govmomi.Client, id string, required int64) error {
+	pod, err := storagepod.FromID(client, id)
+	if err != nil {
+		return fmt.Errorf("error fetching datastore cluster ID %q: %s", id, err)
+	}
+	props, err := storagepod.Properties(pod)
+	if err != nil {
+		return fmt.Errorf("error fetching properties for datastore cluster %q: %s", id, err)
+	}
+	if props.Summary == nil {
+		log.Printf("[DEBUG] No summary available for datastore cluster %q, skipping space check", props.Name)
+		return nil
+	}
+	log.Printf("[DEBUG] Checking %s required against %s free on datastore cluster %q", units.ByteSize(required), units.ByteSize(props.Summary.FreeSpace), props.Name)
+	return checkSpace("datastore cluster", props.Name, required, props.Summary.FreeSpace)
+}
+
+func checkSpace(kind, name string, required, free int64) error {
+	if required > free {
+		return fmt.Errorf("%s %q has %s free, but %s is required", kind, name, units.ByteSize(free), units.ByteSize(required))
+	}
+	return nil
+}
+
+// ResourcePoolReservation checks that the resource pool with the supplied ID
+// can accommodate an additional CPU reservation of cpu MHz and memory
+// reservation of memory MB. Zero values are not checked.
+func ResourcePoolReservation(client *govmomi.Client, id string, cpu, memory int64) error {
+	if cpu <= 0 && memory <= 0 {
+		return nil
+	}
+	pool, err := resourcepool.FromID(client, id)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool ID %q: %s", id, err)
+	}
+	props, err := resourcepool.Properties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching properties for resource pool %q: %s", id, err)
+	}
+	var errs []string
+	if cpu > props.Runtime.Cpu.UnreservedForVm {
+		errs = append(errs, fmt.Sprintf("%d MHz of CPU reservation requested, but only %d MHz is available", cpu, props.Runtime.Cpu.UnreservedForVm))
+	}
+	memoryFree := props.Runtime.Memory.UnreservedForVm / units.MB
+	if memory > memoryFree {
+		errs = append(errs, fmt.Sprintf("%d MB of memory reservation requested, but only %d MB is available", memory, memoryFree))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("resource pool %q: %s", props.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// HardwareVersion checks that the compute resource backing the resource pool
+// with the supplied ID supports the supplied virtual machine hardware
+// version. When upgrade is true, the version is checked for upgrade support,
+// otherwise it is checked for creation support. If hostID is not empty, the
+// version must also be available on that host.
+func HardwareVersion(client *govmomi.Client, poolID, hostID string, version int, upgrade bool) error {
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool ID %q: %s", poolID, err)
+	}
+	props, err := resourcepool.Properties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching properties for resource pool %q: %s", poolID, err)
+	}
+	b, err := computeresource.EnvironmentBrowserFromReference(client, props.Owner)
+	if err != nil {
+		return fmt.Errorf("error loading environment browser for %q: %s", props.Owner.Value, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	descs, err := b.QueryConfigOptionDescriptor(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying supported hardware versions: %s", err)
+	}
+	for _, desc := range descs {
+		if virtualmachine.GetHardwareVersionNumber(desc.Key) != version {
+			continue
+		}
+		supported := desc.CreateSupported
+		action := "creation"
+		if upgrade {
+			supported = desc.UpgradeSupported
+			action = "upgrade"
+		}
+		if supported == nil || !*supported {
+			return fmt.Errorf("hardware version %d is not supported for %s on %q", version, action, props.Owner.Value)
+		}
+		if hostID == "" || len(desc.Host) < 1 {
+			return nil
+		}
+		for _, ref := range desc.Host {
+			if ref.Value == hostID {
+				return nil
+			}
+		}
+		return fmt.Errorf("hardware version %d is not supported on host %q", version, hostID)
+	}
+	return fmt.Errorf("hardware version %d is not supported on %q", version, props.Owner.Value)
+}
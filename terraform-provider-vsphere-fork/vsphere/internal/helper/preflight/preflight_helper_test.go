@@ -0,0 +1,35 @@
+package preflight
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	errs := []error{errors.New("first"), errors.New("second")}
+
+	err := Report(ModeError, "vm", errs)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expected := "preflight checks failed: first; second"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+
+	if err := Report(ModeWarn, "vm", errs); err != nil {
+		t.Fatalf("expected no error in warn mode, got %s", err)
+	}
+	if err := Report(ModeError, "vm", nil); err != nil {
+		t.Fatalf("expected no error without failed checks, got %s", err)
+	}
+}
+
+func TestCheckSpace(t *testing.T) {
+	if err := checkSpace("datastore", "ds1", 10, 20); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := checkSpace("datastore", "ds1", 20, 10); err == nil {
+		t.Fatal("expected error")
+	}
+}
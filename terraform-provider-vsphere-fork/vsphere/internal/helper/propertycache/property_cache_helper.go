@@ -0,0 +1,206 @@
+package propertycache
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/retry"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// cacheableTypes are the managed object types that the cache bulk-loads, and
+// the mo types their properties are loaded into.
+var cacheableTypes = map[string]reflect.Type{
+	"VirtualMachine": reflect.TypeOf(mo.VirtualMachine{}),
+	"HostSystem":     reflect.TypeOf(mo.HostSystem{}),
+	"Datastore":      reflect.TypeOf(mo.Datastore{}),
+}
+
+// caches holds the cache for each client that has caching enabled.
+var caches sync.Map
+
+// Cache is an inventory-wide property cache for a single client. The first
+// lookup of a managed object type loads the properties of every object of
+// that type in the inventory with a single container view retrieval. Later
+// lookups of the same type are served from memory.
+//
+// A call that could modify the inventory can change properties of objects
+// other than the ones it is passed, such as the free space of a datastore
+// after a virtual machine is reconfigured, or the virtual machines on a host
+// after one is migrated, and tasks keep changing them after the call returns.
+// Rather than track these side effects, the whole cache is dropped on the
+// first such call, and all lookups are made directly from then on. The cache
+// is meant for runs that only read the inventory, such as plan and refresh.
+type Cache struct {
+	mu       sync.Mutex
+	client   *vim25.Client
+	disabled bool
+	loaded   map[string]bool
+	entries  map[types.ManagedObjectReference]interface{}
+}
+
+// newCache returns a new, empty cache for the supplied client.
+func newCache(client *vim25.Client) *Cache {
+	return &Cache{
+		client:  client,
+		loaded:  make(map[string]bool),
+		entries: make(map[types.ManagedObjectReference]interface{}),
+	}
+}
+
+// Enable turns on property caching for the supplied client. The client's
+// round tripper is wrapped so that the first call that could modify the
+// inventory drops the cache.
+func Enable(client *vim25.Client) {
+	c := newCache(client)
+	client.RoundTripper = &roundTripper{
+		roundTripper: client.RoundTripper,
+		cache:        c,
+	}
+	caches.Store(client, c)
+	log.Printf("[DEBUG] Property cache enabled")
+}
+
+// Get copies the cached properties of the supplied managed object into dst,
+// which must be a pointer to the mo type for the object, such as
+// *mo.VirtualMachine. It returns false if caching is not enabled for the
+// client, or if the object is not in the cache, in which case the caller
+// should fetch the properties directly.
+//
+// The copy is shallow, so callers must not modify the properties they get
+// back.
+func Get(client *vim25.Client, ref types.ManagedObjectReference, dst interface{}) bool {
+	v, ok := caches.Load(client)
+	if !ok {
+		return false
+	}
+	return v.(*Cache).get(ref, dst)
+}
+
+func (c *Cache) get(ref types.ManagedObjectReference, dst interface{}) bool {
+	t, ok := cacheableTypes[ref.Type]
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled {
+		return false
+	}
+	if !c.loaded[ref.Type] {
+		c.load(ref.Type, t)
+	}
+	entry, ok := c.entries[ref]
+	if !ok {
+		return false
+	}
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Type() != t {
+		return false
+	}
+	dv.Elem().Set(reflect.ValueOf(entry))
+	return true
+}
+
+// load bulk-loads all properties of every object of the supplied type. Errors
+// are logged rather than returned, and leave the type uncached, so that
+// lookups fall back to fetching objects directly.
+func (c *Cache) load(kind string, t reflect.Type) {
+	// Only attempt the load once, even if it fails.
+	c.loaded[kind] = true
+	log.Printf("[DEBUG] Loading property cache for all %s objects", kind)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m := view.NewManager(c.client)
+	v, err := m.CreateContainerView(ctx, c.client.ServiceContent.RootFolder, []string{kind}, true)
+	if err != nil {
+		log.Printf("[DEBUG] Error creating container view for %s property cache: %s", kind, err)
+		return
+	}
+	defer func() {
+		if err := v.Destroy(ctx); err != nil {
+			log.Printf("[DEBUG] Unexpected error destroying container view: %s", err)
+		}
+	}()
+	objs := reflect.New(reflect.SliceOf(t))
+	if err := v.Retrieve(ctx, []string{kind}, nil, objs.Interface()); err != nil {
+		log.Printf("[DEBUG] Error loading %s property cache: %s", kind, err)
+		return
+	}
+	s := objs.Elem()
+	for i := 0; i < s.Len(); i++ {
+		obj := s.Index(i).Interface()
+		c.entries[obj.(mo.Reference).Reference()] = obj
+	}
+	log.Printf("[DEBUG] Loaded %d %s objects into property cache", s.Len(), kind)
+}
+
+// disable drops all cached properties, and stops the cache from being used or
+// loaded again.
+func (c *Cache) disable(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled {
+		return
+	}
+	log.Printf("[DEBUG] Dropping property cache before %s call", method)
+	c.disabled = true
+	c.loaded = nil
+	c.entries = nil
+}
+
+// readOnlyMethods are SOAP methods that do not modify the inventory, in
+// addition to those matched by retry.IsSafeSOAPMethod. These manage the views
+// and property filters used to read the inventory and wait for tasks,
+// including those used by load.
+var readOnlyMethods = map[string]bool{
+	"CreateContainerView":      true,
+	"CreateListView":           true,
+	"CreateListViewFromView":   true,
+	"DestroyView":              true,
+	"CreateFilter":             true,
+	"DestroyPropertyFilter":    true,
+	"CreatePropertyCollector":  true,
+	"DestroyPropertyCollector": true,
+	"CancelWaitForUpdates":     true,
+	"SessionIsActive":          true,
+}
+
+// isReadOnlyMethod returns true if the SOAP method for the supplied request
+// body does not modify the inventory.
+func isReadOnlyMethod(method string) bool {
+	return readOnlyMethods[method] || retry.IsSafeSOAPMethod(method)
+}
+
+// requestMethod returns the name of the SOAP method for a request body, such
+// as ReconfigVM_Task for a *methods.ReconfigVM_TaskBody.
+func requestMethod(req soap.HasFault) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Body")
+}
+
+type roundTripper struct {
+	roundTripper soap.RoundTripper
+	cache        *Cache
+}
+
+// RoundTrip implements soap.RoundTripper for roundTripper. The cache is
+// dropped before a call that could modify the inventory is made, so that no
+// lookup made while the call or its task is in flight is served stale data.
+func (r *roundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if method := requestMethod(req); !isReadOnlyMethod(method) {
+		r.cache.disable(method)
+	}
+	return r.roundTripper.RoundTrip(ctx, req, res)
+}
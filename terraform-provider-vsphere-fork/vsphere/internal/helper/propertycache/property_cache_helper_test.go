@@ -0,0 +1,122 @@
+package propertycache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testLoadedCache(vms ...mo.VirtualMachine) *Cache {
+	c := newCache(nil)
+	c.loaded["VirtualMachine"] = true
+	for _, vm := range vms {
+		c.entries[vm.Self] = vm
+	}
+	return c
+}
+
+type testRoundTripper struct {
+	calls int
+}
+
+func (rt *testRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.calls++
+	return nil
+}
+
+func TestCacheGet(t *testing.T) {
+	ref := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-1"}
+	vm := mo.VirtualMachine{}
+	vm.Self = ref
+	vm.Name = "foo"
+	c := testLoadedCache(vm)
+
+	var props mo.VirtualMachine
+	if !c.get(ref, &props) {
+		t.Fatalf("expected %s to be cached", ref)
+	}
+	if props.Name != "foo" {
+		t.Fatalf("expected name %q, got %q", "foo", props.Name)
+	}
+
+	other := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-2"}
+	if c.get(other, &props) {
+		t.Fatalf("expected %s not to be cached", other)
+	}
+
+	var host mo.HostSystem
+	if c.get(ref, &host) {
+		t.Fatal("expected lookup into the wrong type to miss")
+	}
+}
+
+func TestRoundTripperDisable(t *testing.T) {
+	ref := types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-1"}
+	vm := mo.VirtualMachine{}
+	vm.Self = ref
+	c := testLoadedCache(vm)
+	ds := types.ManagedObjectReference{Type: "Datastore", Value: "datastore-1"}
+	c.loaded["Datastore"] = true
+	c.entries[ds] = mo.Datastore{}
+	inner := &testRoundTripper{}
+	rt := &roundTripper{roundTripper: inner, cache: c}
+
+	reads := []soap.HasFault{
+		&methods.RetrievePropertiesExBody{
+			Req: &types.RetrievePropertiesEx{This: types.ManagedObjectReference{Type: "PropertyCollector", Value: "propertyCollector"}},
+		},
+		&methods.CreateContainerViewBody{
+			Req: &types.CreateContainerView{This: types.ManagedObjectReference{Type: "ViewManager", Value: "ViewManager"}},
+		},
+		&methods.DestroyViewBody{
+			Req: &types.DestroyView{This: types.ManagedObjectReference{Type: "ContainerView", Value: "session[1]1"}},
+		},
+	}
+	for _, req := range reads {
+		if err := rt.RoundTrip(context.Background(), req, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var props mo.VirtualMachine
+	if !c.get(ref, &props) {
+		t.Fatal("expected read-only calls not to drop cache")
+	}
+
+	// Reconfiguring a virtual machine changes the free space of its
+	// datastores, which are not arguments of the call.
+	write := methods.ReconfigVM_TaskBody{
+		Req: &types.ReconfigVM_Task{This: ref},
+	}
+	if err := rt.RoundTrip(context.Background(), &write, &methods.ReconfigVM_TaskBody{}); err != nil {
+		t.Fatal(err)
+	}
+	if c.get(ref, &props) {
+		t.Fatal("expected write call to drop virtual machine entries")
+	}
+	var dsProps mo.Datastore
+	if c.get(ds, &dsProps) {
+		t.Fatal("expected write call to drop datastore entries")
+	}
+	if inner.calls != len(reads)+1 {
+		t.Fatalf("expected %d calls to be passed through, got %d", len(reads)+1, inner.calls)
+	}
+}
+
+func TestRequestMethod(t *testing.T) {
+	cases := []struct {
+		req      soap.HasFault
+		expected string
+	}{
+		{req: &methods.ReconfigVM_TaskBody{}, expected: "ReconfigVM_Task"},
+		{req: &methods.RetrievePropertiesExBody{}, expected: "RetrievePropertiesEx"},
+	}
+	for _, tc := range cases {
+		if actual := requestMethod(tc.req); actual != tc.expected {
+			t.Fatalf("expected %q, got %q", tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Policy describes how failed API calls are retried.
+type Policy struct {
+	// The maximum number of times a call is retried after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+
+	// The delay before the first retry. The delay doubles with each further
+	// retry, up to MaxDelay.
+	MinDelay time.Duration
+
+	// The maximum delay between retries.
+	MaxDelay time.Duration
+}
+
+// Delay returns the backoff delay before the supplied retry attempt, starting
+// from zero. The delay grows exponentially, and half of it is randomized so
+// that concurrent callers do not retry in lock step.
+func (p Policy) Delay(attempt int) time.Duration {
+	d := p.MinDelay
+	for i := 0; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// wait sleeps for the backoff delay of the supplied attempt, returning false
+// if the context is canceled first.
+func (p Policy) wait(ctx context.Context, attempt int) bool {
+	t := time.NewTimer(p.Delay(attempt))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// safeSOAPMethodPrefixes are the prefixes of SOAP methods that do not modify
+// the inventory, and can be retried after any transient error.
+var safeSOAPMethodPrefixes = []string{
+	"Retrieve",
+	"ContinueRetrieve",
+	"Find",
+	"Query",
+	"WaitForUpdates",
+	"CheckForUpdates",
+	"CurrentTime",
+}
+
+// IsSafeSOAPMethod returns true if the supplied SOAP method is read-only.
+func IsSafeSOAPMethod(method string) bool {
+	for _, p := range safeSOAPMethodPrefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// soapMethod returns the name of the SOAP method for a request body, such as
+// RetrievePropertiesEx for a *methods.RetrievePropertiesExBody.
+func soapMethod(req soap.HasFault) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Body")
+}
+
+// isTransientFault returns true if the supplied fault means that the server
+// rejected an operation because of a conflicting one, without acting on it.
+func isTransientFault(fault types.AnyType) bool {
+	switch fault.(type) {
+	case types.TaskInProgress, *types.TaskInProgress,
+		types.ConcurrentAccess, *types.ConcurrentAccess:
+		return true
+	}
+	return false
+}
+
+// IsTransientSOAPError returns true if the error returned from calling the
+// supplied SOAP method, or from waiting on the task it started, is likely to
+// succeed on retry.
+//
+// TaskInProgress and ConcurrentAccess faults, and HTTP 503 responses, mean
+// that the server rejected the call without acting on it, so these are
+// retried for all methods. Other gateway errors and dropped connections leave
+// the outcome of the call unknown, so these are only retried for read-only
+// methods.
+func IsTransientSOAPError(method string, err error) bool {
+	if soap.IsSoapFault(err) {
+		return isTransientFault(soap.ToSoapFault(err).VimFault())
+	}
+	if soap.IsVimFault(err) {
+		return isTransientFault(soap.ToVimFault(err))
+	}
+	var te task.Error
+	if errors.As(err, &te) {
+		return te.LocalizedMethodFault != nil && isTransientFault(te.Fault())
+	}
+	switch statusCode(err) {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return IsSafeSOAPMethod(method)
+	}
+	return IsSafeSOAPMethod(method) && isConnectionError(err)
+}
+
+// statusCode extracts the HTTP status code from an error returned by the SOAP
+// client for a non-200, non-fault response. The SOAP client does not export
+// its status error, but formats it with the response status, such as "503
+// Service Unavailable". Zero is returned if the error is not a status error.
+func statusCode(err error) int {
+	var ue *url.Error
+	if !errors.As(err, &ue) || ue.Err == nil {
+		return 0
+	}
+	var ne net.Error
+	if errors.As(ue.Err, &ne) {
+		return 0
+	}
+	f := strings.Fields(ue.Err.Error())
+	if len(f) < 1 {
+		return 0
+	}
+	code, err := strconv.Atoi(f[0])
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
+// isConnectionError returns true if the error is from a dropped or failed
+// connection, rather than a response from the server.
+func isConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+const (
+	// taskWaitTimeout is how long a task started by a *_Task method is watched
+	// for a transient fault before it is handed back to the caller.
+	taskWaitTimeout = 5 * time.Second
+
+	// taskPollInterval is the delay between checks of a watched task.
+	taskPollInterval = 250 * time.Millisecond
+)
+
+type soapRoundTripper struct {
+	roundTripper      soap.RoundTripper
+	propertyCollector types.ManagedObjectReference
+	policy            Policy
+}
+
+// NewSOAPRoundTripper wraps a SOAP round tripper, such as the one on a
+// vim25.Client, retrying calls that fail with transient errors according to
+// the supplied policy.
+//
+// Conflicting operations are often only rejected once the task they start
+// runs, so when a property collector is supplied, tasks started by *_Task
+// methods are watched for a short time, and the call is retried if the task
+// fails with a transient fault. Tasks still running after that are returned
+// to the caller as is.
+func NewSOAPRoundTripper(rt soap.RoundTripper, pc types.ManagedObjectReference, policy Policy) soap.RoundTripper {
+	return &soapRoundTripper{
+		roundTripper:      rt,
+		propertyCollector: pc,
+		policy:            policy,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for soapRoundTripper.
+func (r *soapRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method := soapMethod(req)
+	for attempt := 0; ; attempt++ {
+		err := r.roundTripper.RoundTrip(ctx, req, res)
+		if attempt >= r.policy.MaxRetries {
+			return err
+		}
+		if err == nil {
+			if err = r.taskError(ctx, method, res); err == nil || !IsTransientSOAPError(method, err) {
+				return nil
+			}
+		} else if !IsTransientSOAPError(method, err) {
+			return err
+		}
+		log.Printf("[DEBUG] Retrying %s after transient error (retry %d of %d): %s", method, attempt+1, r.policy.MaxRetries, err)
+		if !r.policy.wait(ctx, attempt) {
+			return err
+		}
+		// Clear the fault decoded into the response from the failed attempt so
+		// that it does not carry over to the next one.
+		v := reflect.ValueOf(res).Elem()
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// taskError waits for the task started by a *_Task method to finish, for up
+// to taskWaitTimeout, and returns the task's fault if it failed. nil is
+// returned for other methods, and for tasks that succeed, are still running,
+// or cannot be read.
+func (r *soapRoundTripper) taskError(ctx context.Context, method string, res soap.HasFault) error {
+	if r.propertyCollector.Value == "" || !strings.HasSuffix(method, "_Task") {
+		return nil
+	}
+	ref, ok := taskReference(res)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, taskWaitTimeout)
+	defer cancel()
+	for {
+		var t mo.Task
+		if err := mo.RetrieveProperties(ctx, r.roundTripper, r.propertyCollector, ref, &t); err != nil {
+			return nil
+		}
+		switch t.Info.State {
+		case types.TaskInfoStateSuccess:
+			return nil
+		case types.TaskInfoStateError:
+			if t.Info.Error == nil {
+				return nil
+			}
+			return task.Error{LocalizedMethodFault: t.Info.Error}
+		}
+		timer := time.NewTimer(taskPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// taskReference returns the task returned in the response body of a *_Task
+// method, such as a *methods.ReconfigVM_TaskBody.
+func taskReference(res soap.HasFault) (types.ManagedObjectReference, bool) {
+	v := reflect.ValueOf(res)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return types.ManagedObjectReference{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return types.ManagedObjectReference{}, false
+	}
+	r := v.FieldByName("Res")
+	if !r.IsValid() || r.Kind() != reflect.Ptr || r.IsNil() {
+		return types.ManagedObjectReference{}, false
+	}
+	rv := r.Elem().FieldByName("Returnval")
+	if !rv.IsValid() {
+		return types.ManagedObjectReference{}, false
+	}
+	ref, ok := rv.Interface().(types.ManagedObjectReference)
+	return ref, ok && ref.Type == "Task"
+}
+
+type httpRoundTripper struct {
+	roundTripper http.RoundTripper
+	policy       Policy
+}
+
+// NewHTTPRoundTripper wraps an HTTP transport, such as the one used by the
+// REST client, retrying requests that fail with transient errors according to
+// the supplied policy.
+func NewHTTPRoundTripper(rt http.RoundTripper, policy Policy) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &httpRoundTripper{
+		roundTripper: rt,
+		policy:       policy,
+	}
+}
+
+// isSafeHTTPMethod returns true if the supplied HTTP method is read-only.
+func isSafeHTTPMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// IsTransientHTTPResponse returns true if the result of an HTTP request is
+// likely to succeed on retry. The rules follow IsTransientSOAPError: 503 and
+// 429 responses are retried for all methods, while gateway errors and
+// dropped connections are only retried for read-only methods.
+func IsTransientHTTPResponse(method string, res *http.Response, err error) bool {
+	if err != nil {
+		return isSafeHTTPMethod(method) && isConnectionError(err)
+	}
+	switch res.StatusCode {
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isSafeHTTPMethod(method)
+	}
+	return false
+}
+
+// RoundTrip implements http.RoundTripper for httpRoundTripper.
+func (r *httpRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body can only be retried if the body can be read again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return r.roundTripper.RoundTrip(req)
+	}
+	for attempt := 0; ; attempt++ {
+		res, err := r.roundTripper.RoundTrip(req)
+		if attempt >= r.policy.MaxRetries || !IsTransientHTTPResponse(req.Method, res, err) {
+			return res, err
+		}
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = res.Status
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		log.Printf("[DEBUG] Retrying %s %s after transient error (retry %d of %d): %s", req.Method, req.URL.Path, attempt+1, r.policy.MaxRetries, reason)
+		if !r.policy.wait(req.Context(), attempt) {
+			return nil, req.Context().Err()
+		}
+		next := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			next.Body = body
+		}
+		req = next
+	}
+}
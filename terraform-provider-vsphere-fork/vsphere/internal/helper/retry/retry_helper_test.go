@@ -0,0 +1,376 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testStatusError struct {
+	status string
+}
+
+func (e *testStatusError) Error() string {
+	return e.status
+}
+
+func testSOAPFault(fault types.AnyType) error {
+	return soap.WrapSoapFault(&soap.Fault{
+		Code:   "ServerFaultCode",
+		String: "fault",
+		Detail: struct {
+			Fault types.AnyType `xml:",any,typeattr"`
+		}{Fault: fault},
+	})
+}
+
+func TestIsTransientSOAPError(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "task in progress",
+			method:   "ReconfigVM_Task",
+			err:      testSOAPFault(types.TaskInProgress{}),
+			expected: true,
+		},
+		{
+			name:     "concurrent access",
+			method:   "ReconfigVM_Task",
+			err:      testSOAPFault(types.ConcurrentAccess{}),
+			expected: true,
+		},
+		{
+			name:     "not found",
+			method:   "RetrievePropertiesEx",
+			err:      testSOAPFault(types.ManagedObjectNotFound{}),
+			expected: false,
+		},
+		{
+			name:     "service unavailable",
+			method:   "CreateVM_Task",
+			err:      &url.Error{Op: "POST", URL: "/sdk", Err: &testStatusError{"503 Service Unavailable"}},
+			expected: true,
+		},
+		{
+			name:     "bad gateway on read",
+			method:   "RetrievePropertiesEx",
+			err:      &url.Error{Op: "POST", URL: "/sdk", Err: &testStatusError{"502 Bad Gateway"}},
+			expected: true,
+		},
+		{
+			name:     "bad gateway on write",
+			method:   "CreateVM_Task",
+			err:      &url.Error{Op: "POST", URL: "/sdk", Err: &testStatusError{"502 Bad Gateway"}},
+			expected: false,
+		},
+		{
+			name:     "dropped connection on read",
+			method:   "RetrievePropertiesEx",
+			err:      &url.Error{Op: "POST", URL: "/sdk", Err: io.EOF},
+			expected: true,
+		},
+		{
+			name:     "dropped connection on write",
+			method:   "CreateVM_Task",
+			err:      &url.Error{Op: "POST", URL: "/sdk", Err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}},
+			expected: false,
+		},
+		{
+			name:     "task in progress task result",
+			method:   "ReconfigVM_Task",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.TaskInProgress{}}},
+			expected: true,
+		},
+		{
+			name:     "invalid argument task result",
+			method:   "ReconfigVM_Task",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.InvalidArgument{}}},
+			expected: false,
+		},
+		{
+			name:     "other error",
+			method:   "RetrievePropertiesEx",
+			err:      errors.New("something went wrong"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := IsTransientSOAPError(tc.method, tc.err); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPolicyDelay(t *testing.T) {
+	p := Policy{
+		MinDelay: 100 * time.Millisecond,
+		MaxDelay: time.Second,
+	}
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: 100 * time.Millisecond},
+		{attempt: 1, max: 200 * time.Millisecond},
+		{attempt: 3, max: 800 * time.Millisecond},
+		{attempt: 10, max: time.Second},
+	}
+	for _, tc := range cases {
+		for i := 0; i < 10; i++ {
+			d := p.Delay(tc.attempt)
+			if d < tc.max/2 || d > tc.max {
+				t.Fatalf("attempt %d: expected delay between %s and %s, got %s", tc.attempt, tc.max/2, tc.max, d)
+			}
+		}
+	}
+}
+
+type testSOAPRoundTripper struct {
+	errs  []error
+	calls int
+}
+
+func (rt *testSOAPRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.calls++
+	if len(rt.errs) > 0 {
+		err := rt.errs[0]
+		rt.errs = rt.errs[1:]
+		return err
+	}
+	return nil
+}
+
+func TestSOAPRoundTripper(t *testing.T) {
+	fault := testSOAPFault(types.TaskInProgress{})
+	cases := []struct {
+		name     string
+		errs     []error
+		retries  int
+		calls    int
+		expected bool
+	}{
+		{
+			name:     "succeeds after retry",
+			errs:     []error{fault, fault},
+			retries:  3,
+			calls:    3,
+			expected: true,
+		},
+		{
+			name:     "gives up after max retries",
+			errs:     []error{fault, fault, fault},
+			retries:  2,
+			calls:    3,
+			expected: false,
+		},
+		{
+			name:     "retries disabled",
+			errs:     []error{fault},
+			retries:  0,
+			calls:    1,
+			expected: false,
+		},
+		{
+			name:     "permanent error",
+			errs:     []error{testSOAPFault(types.InvalidArgument{})},
+			retries:  3,
+			calls:    1,
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			inner := &testSOAPRoundTripper{errs: tc.errs}
+			rt := NewSOAPRoundTripper(inner, types.ManagedObjectReference{}, Policy{MaxRetries: tc.retries})
+			req := methods.ReconfigVM_TaskBody{}
+			res := methods.ReconfigVM_TaskBody{}
+			err := rt.RoundTrip(context.Background(), &req, &res)
+			if (err == nil) != tc.expected {
+				t.Fatalf("expected success %t, got error %v", tc.expected, err)
+			}
+			if inner.calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, inner.calls)
+			}
+		})
+	}
+}
+
+// testTaskRoundTripper starts a task for each ReconfigVM_Task call, which
+// fails with the next of faults, or succeeds once they run out.
+type testTaskRoundTripper struct {
+	faults []types.BaseMethodFault
+	calls  int
+}
+
+func (rt *testTaskRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	ref := types.ManagedObjectReference{Type: "Task", Value: "task-1"}
+	switch res := res.(type) {
+	case *methods.ReconfigVM_TaskBody:
+		rt.calls++
+		res.Res = &types.ReconfigVM_TaskResponse{Returnval: ref}
+	case *methods.RetrievePropertiesBody:
+		info := types.TaskInfo{State: types.TaskInfoStateSuccess}
+		if rt.calls <= len(rt.faults) {
+			info.State = types.TaskInfoStateError
+			info.Error = &types.LocalizedMethodFault{Fault: rt.faults[rt.calls-1]}
+		}
+		res.Res = &types.RetrievePropertiesResponse{
+			Returnval: []types.ObjectContent{
+				{
+					Obj:     ref,
+					PropSet: []types.DynamicProperty{{Name: "info", Val: info}},
+				},
+			},
+		}
+	}
+	return nil
+}
+
+func TestSOAPRoundTripperTaskFault(t *testing.T) {
+	pc := types.ManagedObjectReference{Type: "PropertyCollector", Value: "propertyCollector"}
+	cases := []struct {
+		name    string
+		faults  []types.BaseMethodFault
+		pc      types.ManagedObjectReference
+		retries int
+		calls   int
+	}{
+		{
+			name:    "succeeds after retry",
+			faults:  []types.BaseMethodFault{&types.TaskInProgress{}, &types.ConcurrentAccess{}},
+			pc:      pc,
+			retries: 3,
+			calls:   3,
+		},
+		{
+			name:    "gives up after max retries",
+			faults:  []types.BaseMethodFault{&types.TaskInProgress{}, &types.TaskInProgress{}},
+			pc:      pc,
+			retries: 1,
+			calls:   2,
+		},
+		{
+			name:    "permanent fault",
+			faults:  []types.BaseMethodFault{&types.InvalidArgument{}},
+			pc:      pc,
+			retries: 3,
+			calls:   1,
+		},
+		{
+			name:    "no property collector",
+			faults:  []types.BaseMethodFault{&types.TaskInProgress{}},
+			retries: 3,
+			calls:   1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			inner := &testTaskRoundTripper{faults: tc.faults}
+			rt := NewSOAPRoundTripper(inner, tc.pc, Policy{MaxRetries: tc.retries})
+			req := methods.ReconfigVM_TaskBody{}
+			res := methods.ReconfigVM_TaskBody{}
+			if err := rt.RoundTrip(context.Background(), &req, &res); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if inner.calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, inner.calls)
+			}
+			if res.Res == nil || res.Res.Returnval.Value != "task-1" {
+				t.Fatalf("expected task-1 to be returned, got %v", res.Res)
+			}
+		})
+	}
+}
+
+func TestHTTPRoundTripper(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		statuses []int
+		calls    int
+		expected int
+	}{
+		{
+			name:     "service unavailable on write",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			calls:    2,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "bad gateway on read",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			calls:    2,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "bad gateway on write",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			calls:    1,
+			expected: http.StatusBadGateway,
+		},
+		{
+			name:     "not found",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusNotFound, http.StatusOK},
+			calls:    1,
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(b) != "body" {
+					t.Errorf("expected request body %q, got %q", "body", string(b))
+				}
+				w.WriteHeader(tc.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: NewHTTPRoundTripper(nil, Policy{MaxRetries: 3})}
+			req, err := http.NewRequest(tc.method, srv.URL, strings.NewReader("body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tc.expected {
+				t.Fatalf("expected status %d, got %d", tc.expected, res.StatusCode)
+			}
+			if calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, calls)
+			}
+		})
+	}
+}
@@ -124,6 +124,17 @@ func IsResourceInUseError(err error) bool {
 	return false
 }
 
+// IsFileAlreadyExistsError checks an error to see if it's of the
+// FileAlreadyExists type.
+func IsFileAlreadyExistsError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.FileAlreadyExists); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // isConcurrentAccessError checks an error to see if it's of the
 // ConcurrentAccess type.
 func isConcurrentAccessError(err error) bool {
@@ -144,6 +155,25 @@ func isConcurrentAccessError(err error) bool {
 	return false
 }
 
+// IsFileLockedError checks an error to see if it's of the FileLocked type.
+// This is usually returned when a file, such as a virtual disk, is in use by a
+// powered on virtual machine.
+func IsFileLockedError(err error) bool {
+	var f types.AnyType
+	var ok bool
+	f, ok = vimSoapFault(err)
+	if !ok {
+		f, ok = taskFault(err)
+	}
+	if ok {
+		switch f.(type) {
+		case types.FileLocked, *types.FileLocked:
+			return true
+		}
+	}
+	return false
+}
+
 // RenameObject renames a MO and tracks the task to make sure it completes.
 func RenameObject(client *govmomi.Client, ref types.ManagedObjectReference, new string) error {
 	req := types.Rename_Task{
@@ -1,9 +1,14 @@
 package viapi
 
 import (
+	"errors"
 	"reflect"
 	"regexp"
 	"testing"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // testMatchError performs regex matching for error cases.
@@ -295,3 +300,42 @@ func TestCompareVersion(t *testing.T) {
 		t.Run(tc.Name, tc.Test)
 	}
 }
+
+func TestIsFileLockedError(t *testing.T) {
+	soapFault := func(f types.AnyType) error {
+		sf := &soap.Fault{}
+		sf.Detail.Fault = f
+		return soap.WrapSoapFault(sf)
+	}
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "SOAP fault",
+			err:      soapFault(types.FileLocked{}),
+			expected: true,
+		},
+		{
+			name:     "task fault",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.FileLocked{}}},
+			expected: true,
+		},
+		{
+			name: "other task fault",
+			err:  task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.FileNotFound{}}},
+		},
+		{
+			name: "other error",
+			err:  errors.New("file locked"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := IsFileLockedError(tc.err); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
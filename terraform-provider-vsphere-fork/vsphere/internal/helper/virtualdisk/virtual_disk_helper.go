@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -152,3 +153,112 @@ func FromPath(client *govmomi.Client, p string, dc *object.Datacenter) (*object.
 	}
 	return &di[0], nil
 }
+
+// Copy copies the virtual disk at srcPath in srcDC to dstPath in dstDC. The
+// destination datacenter can be nil if it is the same as the source. spec is
+// used for the destination disk's format and can be nil to keep the format of
+// the source.
+//
+// Copies can take a long time for large disks, so the task is waited on
+// without a timeout.
+func Copy(client *govmomi.Client, srcPath string, srcDC *object.Datacenter, dstPath string, dstDC *object.Datacenter, spec *types.VirtualDiskSpec) error {
+	if srcDC == nil {
+		return fmt.Errorf("source datacenter cannot be nil")
+	}
+	log.Printf("[DEBUG] Copying virtual disk from %q in datacenter %s to destination %s%s",
+		srcPath,
+		srcDC,
+		dstPath,
+		structure.LogCond(dstDC != nil, fmt.Sprintf(" in datacenter %s", dstDC), ""),
+	)
+	vdm := object.NewVirtualDiskManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := vdm.CopyVirtualDisk(ctx, srcPath, srcDC, dstPath, dstDC, spec, false)
+	if err != nil {
+		return err
+	}
+	if err := task.Wait(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Virtual disk %q successfully copied to %q", srcPath, dstPath)
+	return nil
+}
+
+// Extend grows the virtual disk at the specified datastore path to capacityKb.
+// If eagerZero is true, the added space is zeroed out.
+func Extend(client *govmomi.Client, name string, dc *object.Datacenter, capacityKb int64, eagerZero bool) error {
+	if dc == nil {
+		return fmt.Errorf("datacenter cannot be nil")
+	}
+	log.Printf("[DEBUG] Extending virtual disk %q in datacenter %s to %d KB", name, dc, capacityKb)
+	vdm := object.NewVirtualDiskManager(client.Client)
+	dcRef := dc.Reference()
+	req := types.ExtendVirtualDisk_Task{
+		This:          vdm.Reference(),
+		Name:          name,
+		Datacenter:    &dcRef,
+		NewCapacityKb: capacityKb,
+		EagerZero:     types.NewBool(eagerZero),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.ExtendVirtualDisk_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	if err := object.NewTask(client.Client, res.Returnval).Wait(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Virtual disk %q in datacenter %s extended successfully", name, dc)
+	return nil
+}
+
+// Inflate converts the thin provisioned virtual disk at the specified
+// datastore path to an eager zeroed thick disk.
+func Inflate(client *govmomi.Client, name string, dc *object.Datacenter) error {
+	if dc == nil {
+		return fmt.Errorf("datacenter cannot be nil")
+	}
+	log.Printf("[DEBUG] Inflating virtual disk %q in datacenter %s", name, dc)
+	vdm := object.NewVirtualDiskManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := vdm.InflateVirtualDisk(ctx, name, dc)
+	if err != nil {
+		return err
+	}
+	if err := task.Wait(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Virtual disk %q in datacenter %s inflated successfully", name, dc)
+	return nil
+}
+
+// EagerZero zeroes out the unused space of the lazy zeroed thick virtual disk
+// at the specified datastore path, converting it to an eager zeroed thick
+// disk.
+func EagerZero(client *govmomi.Client, name string, dc *object.Datacenter) error {
+	if dc == nil {
+		return fmt.Errorf("datacenter cannot be nil")
+	}
+	log.Printf("[DEBUG] Eager zeroing virtual disk %q in datacenter %s", name, dc)
+	vdm := object.NewVirtualDiskManager(client.Client)
+	dcRef := dc.Reference()
+	req := types.EagerZeroVirtualDisk_Task{
+		This:       vdm.Reference(),
+		Name:       name,
+		Datacenter: &dcRef,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.EagerZeroVirtualDisk_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	if err := object.NewTask(client.Client, res.Returnval).Wait(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Virtual disk %q in datacenter %s eager zeroed successfully", name, dc)
+	return nil
+}
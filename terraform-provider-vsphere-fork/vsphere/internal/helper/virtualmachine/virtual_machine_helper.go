@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/propertycache"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/vappcontainer"
@@ -241,12 +242,53 @@ func Properties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.VirtualMachine
+	if propertycache.Get(vm.Client(), vm.Reference(), &props) {
+		return &props, nil
+	}
 	if err := vm.Properties(ctx, vm.Reference(), nil, &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
 }
 
+// PropertiesInContainer fetches the supplied properties of every virtual
+// machine under a container, such as a folder, resource pool, cluster, or
+// datacenter, in a single retrieval. If no properties are supplied, all
+// properties are fetched.
+func PropertiesInContainer(client *govmomi.Client, container types.ManagedObjectReference, props []string) ([]mo.VirtualMachine, error) {
+	log.Printf("[DEBUG] Fetching properties for all virtual machines in %q", container.Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m := view.NewManager(client.Client)
+	v, err := m.CreateContainerView(ctx, container, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = v.Destroy(ctx); err != nil {
+			log.Printf("[DEBUG] PropertiesInContainer: Unexpected error destroying container view: %s", err)
+		}
+	}()
+	var vms []mo.VirtualMachine
+	if err := v.Retrieve(ctx, []string{"VirtualMachine"}, props, &vms); err != nil {
+		return nil, err
+	}
+	return vms, nil
+}
+
+// SnapshotProperties is a convenience method that wraps fetching the
+// VirtualMachineSnapshot MO from its higher-level object.
+func SnapshotProperties(vm *object.VirtualMachine, ref types.ManagedObjectReference) (*mo.VirtualMachineSnapshot, error) {
+	log.Printf("[DEBUG] Fetching properties for snapshot %q on VM %q", ref.Value, vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.VirtualMachineSnapshot
+	if err := vm.Properties(ctx, ref, []string{"config"}, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
 // ConfigOptions is a convenience method that wraps fetching the VirtualMachine ConfigOptions
 // as returned by QueryConfigOption.
 func ConfigOptions(vm *object.VirtualMachine) (*types.VirtualMachineConfigOption, error) {
@@ -526,6 +568,37 @@ func Clone(c *govmomi.Client, src *object.VirtualMachine, f *object.Folder, name
 	return FromMOID(c, result.Result.(types.ManagedObjectReference).Value)
 }
 
+// InstantClone wraps the creation of an instant clone of a running virtual
+// machine and the subsequent waiting of the task. The new virtual machine
+// shares the memory and disk state of the source and is powered on when the
+// task completes.
+func InstantClone(c *govmomi.Client, src *object.VirtualMachine, spec types.VirtualMachineInstantCloneSpec, timeout int) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] Instant cloning virtual machine %q to %q", src.InventoryPath, spec.Name)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
+	defer cancel()
+	req := types.InstantClone_Task{
+		This: src.Reference(),
+		Spec: spec,
+	}
+	res, err := methods.InstantClone_Task(ctx, c.Client, &req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for instant clone to complete")
+		}
+		return nil, err
+	}
+	task := object.NewTask(c.Client, res.Returnval)
+	result, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for instant clone to complete")
+		}
+		return nil, err
+	}
+	log.Printf("[DEBUG] Virtual machine %q: instant clone complete (MOID: %q)", spec.Name, result.Result.(types.ManagedObjectReference).Value)
+	return FromMOID(c, result.Result.(types.ManagedObjectReference).Value)
+}
+
 func DeployDest(name string, annotation string, rp *object.ResourcePool, host *object.HostSystem, folder *object.Folder, ds viapi.ManagedObject, spId string, nm []vcenter.NetworkMapping) *vcenter.Deploy {
 	rpId := ""
 	hostId := ""
@@ -671,6 +744,25 @@ func PowerOff(vm *object.VirtualMachine) error {
 	return task.Wait(tctx)
 }
 
+// MarkAsTemplate wraps the conversion of a powered off virtual machine to a
+// template.
+func MarkAsTemplate(vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] Marking virtual machine %q as template", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.MarkAsTemplate(ctx)
+}
+
+// MarkAsVirtualMachine wraps the conversion of a template back to a virtual
+// machine. The virtual machine is placed in the supplied resource pool, and on
+// the supplied host if it is not nil.
+func MarkAsVirtualMachine(vm *object.VirtualMachine, pool *object.ResourcePool, host *object.HostSystem) error {
+	log.Printf("[DEBUG] Marking template %q as virtual machine", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.MarkAsVirtualMachine(ctx, *pool, host)
+}
+
 // ShutdownGuest wraps the graceful shutdown of a guest VM, and then waiting an
 // appropriate amount of time for the guest power state to go to powered off.
 // If the VM does not power off in the shutdown period specified by timeout (in
@@ -748,6 +840,49 @@ func GracefulPowerOff(client *govmomi.Client, vm *object.VirtualMachine, timeout
 	return PowerOff(vm)
 }
 
+// Suspend wraps suspending a VM and the waiting for the subsequent task.
+func Suspend(vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] Suspending virtual machine %q", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := vm.Suspend(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// WaitForPowerState waits for the virtual machine with the supplied reference
+// to reach one of the supplied power states. An error is returned if none of
+// the states are reached within timeout.
+func WaitForPowerState(client *govmomi.Client, ref types.ManagedObjectReference, states []types.VirtualMachinePowerState, timeout time.Duration) error {
+	log.Printf("[DEBUG] Waiting for virtual machine %q to reach power state %v", ref.Value, states)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	p := client.PropertyCollector()
+	err := property.Wait(ctx, p, ref, []string{"runtime.powerState"}, func(pc []types.PropertyChange) bool {
+		for _, c := range pc {
+			if c.Op != types.PropertyChangeOpAssign {
+				continue
+			}
+			if v, ok := c.Val.(types.VirtualMachinePowerState); ok {
+				for _, state := range states {
+					if v == state {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	})
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out waiting for virtual machine %q to reach power state %v", ref.Value, states)
+	}
+	return err
+}
+
 // MoveToFolder moves a virtual machine to the specified folder.
 func MoveToFolder(client *govmomi.Client, vm *object.VirtualMachine, relative string) error {
 	log.Printf("[DEBUG] Moving virtual %q to VM path %q", vm.InventoryPath, relative)
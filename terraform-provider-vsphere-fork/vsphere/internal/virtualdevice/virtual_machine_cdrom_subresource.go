@@ -7,11 +7,14 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/mitchellh/copystructure"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -37,6 +40,26 @@ func CdromSubresourceSchema() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Indicates whether the device should be mapped to a remote client device",
 		},
+		// VirtualCdromIsoBackingInfo, resolved from a content library item
+		"content_library_item_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The ID of a content library item containing the ISO to mount. The ISO is mounted from the datastore backing the content library.",
+		},
+		"controller_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The type of controller the CDROM device should be connected to. Must be 'ide' or 'sata'. Defaults to 'ide'.",
+			ValidateFunc: validation.StringInSlice([]string{SubresourceControllerTypeIDE, SubresourceControllerTypeSATA}, false),
+		},
+		"unit_number": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The unit number of the CDROM device, counted across all controllers of controller_type. Only used if controller_type is set, in which case the unit must be free. Without controller_type, the first free IDE unit is used.",
+			ValidateFunc: validation.IntBetween(0, 119),
+		},
 	}
 	structure.MergeSchema(s, subresourceSchema())
 	return s
@@ -46,11 +69,15 @@ func CdromSubresourceSchema() map[string]*schema.Schema {
 // with a complex device lifecycle.
 type CdromSubresource struct {
 	*Subresource
+
+	// The REST client used to resolve content library items. This is only
+	// needed for create and update operations.
+	restClient *rest.Client
 }
 
 // NewCdromSubresource returns a subresource populated with all of the necessary
 // fields.
-func NewCdromSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *CdromSubresource {
+func NewCdromSubresource(client *govmomi.Client, restClient *rest.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *CdromSubresource {
 	sr := &CdromSubresource{
 		Subresource: &Subresource{
 			schema:  CdromSubresourceSchema(),
@@ -60,6 +87,7 @@ func NewCdromSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old ma
 			olddata: old,
 			rdd:     rdd,
 		},
+		restClient: restClient,
 	}
 	sr.Index = idx
 	return sr
@@ -69,16 +97,14 @@ func NewCdromSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old ma
 // resource.
 //
 // The function takes the root resource's ResourceData, the provider
-// connection, and the device list as known to vSphere at the start of this
+// connections, and the device list as known to vSphere at the start of this
 // operation. All disk operations are carried out, with both the complete,
 // updated, VirtualDeviceList, and the complete list of changes returned as a
 // slice of BaseVirtualDeviceConfigSpec.
-func CdromApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+func CdromApplyOperation(d *schema.ResourceData, c *govmomi.Client, rc *rest.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
 	log.Printf("[DEBUG] CdromApplyOperation: Beginning apply operation")
-	// While we are currently only restricting CD devices to one device, we have
-	// to actually account for the fact that someone could add multiple CD drives
-	// out of band. So this workflow is similar to the multi-device workflow that
-	// exists for network devices.
+	// This workflow is similar to the multi-device workflow that exists for
+	// network devices.
 	o, n := d.GetChange(subresourceTypeCdrom)
 	ods := o.([]interface{})
 	nds := n.([]interface{})
@@ -97,7 +123,7 @@ nextOld:
 				continue nextOld
 			}
 		}
-		r := NewCdromSubresource(c, d, om, nil, n)
+		r := NewCdromSubresource(c, rc, d, om, nil, n)
 		dspec, err := r.Delete(l)
 		if err != nil {
 			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
@@ -125,7 +151,7 @@ nextOld:
 				log.Printf("[DEBUG] CdromApplyOperation: No-op resource: key %d", nm["key"].(int))
 				continue
 			}
-			r := NewCdromSubresource(c, d, nm, om, n)
+			r := NewCdromSubresource(c, rc, d, nm, om, n)
 			uspec, err := r.Update(l)
 			if err != nil {
 				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
@@ -136,7 +162,7 @@ nextOld:
 			continue
 		}
 		// New device
-		r := NewCdromSubresource(c, d, nm, nil, n)
+		r := NewCdromSubresource(c, rc, d, nm, nil, n)
 		cspec, err := r.Create(l)
 		if err != nil {
 			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
@@ -164,10 +190,8 @@ nextOld:
 // returned, all necessary values are just set and committed to state.
 func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
 	log.Printf("[DEBUG] CdromRefreshOperation: Beginning refresh")
-	// While we are currently only restricting CD devices to one device, we have
-	// to actually account for the fact that someone could add multiple CD drives
-	// out of band. So this workflow is similar to the multi-device workflow that
-	// exists for network devices.
+	// This workflow is similar to the multi-device workflow that exists for
+	// network devices.
 	devices := l.Select(func(device types.BaseVirtualDevice) bool {
 		if _, ok := device.(*types.VirtualCdrom); ok {
 			return true
@@ -187,7 +211,7 @@ func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.V
 	for n, item := range curSet {
 		m := item.(map[string]interface{})
 		if m["key"].(int) < 1 {
-			r := NewCdromSubresource(c, d, m, nil, n)
+			r := NewCdromSubresource(c, nil, d, m, nil, n)
 			if err := r.Read(l); err != nil {
 				return fmt.Errorf("%s: %s", r.Addr(), err)
 			}
@@ -225,7 +249,7 @@ func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.V
 				continue
 			}
 			// We should have our device -> resource match, so read now.
-			r := NewCdromSubresource(c, d, m, nil, n)
+			r := NewCdromSubresource(c, nil, d, m, nil, n)
 			vApp, err := verifyVAppCdromIso(d, device.(*types.VirtualCdrom), l, c)
 			if err != nil {
 				return err
@@ -236,6 +260,7 @@ func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.V
 				r.Set("client_device", true)
 				r.Set("datastore_id", "")
 				r.Set("path", "")
+				r.Set("content_library_item_id", "")
 			} else {
 				if err := r.Read(l); err != nil {
 					return fmt.Errorf("%s: %s", r.Addr(), err)
@@ -266,7 +291,7 @@ func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.V
 		if err != nil {
 			return fmt.Errorf("error computing device address: %s", err)
 		}
-		r := NewCdromSubresource(c, d, m, nil, n)
+		r := NewCdromSubresource(c, nil, d, m, nil, n)
 		if err := r.Read(l); err != nil {
 			return fmt.Errorf("%s: %s", r.Addr(), err)
 		}
@@ -285,12 +310,10 @@ func CdromRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.V
 // This differs from a regular apply operation in that a configuration is
 // already present, but we don't have any existing state, which the standard
 // virtual device operations rely pretty heavily on.
-func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, rc *rest.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
 	log.Printf("[DEBUG] CdromPostCloneOperation: Looking for post-clone device changes")
-	// While we are currently only restricting CD devices to one device, we have
-	// to actually account for the fact that someone could add multiple CD drives
-	// out of band. So this workflow is similar to the multi-device workflow that
-	// exists for network devices.
+	// This workflow is similar to the multi-device workflow that exists for
+	// network devices.
 	devices := l.Select(func(device types.BaseVirtualDevice) bool {
 		if _, ok := device.(*types.VirtualCdrom); ok {
 			return true
@@ -318,7 +341,7 @@ func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object
 		if err != nil {
 			return nil, nil, fmt.Errorf("error computing device address: %s", err)
 		}
-		r := NewCdromSubresource(c, d, m, nil, n)
+		r := NewCdromSubresource(c, rc, d, m, nil, n)
 		if err := r.Read(l); err != nil {
 			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
 		}
@@ -337,7 +360,7 @@ func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object
 		cm := ci.(map[string]interface{})
 		if i > len(srcSet)-1 {
 			// New device
-			r := NewCdromSubresource(c, d, cm, nil, i)
+			r := NewCdromSubresource(c, rc, d, cm, nil, i)
 			cspec, err := r.Create(l)
 			if err != nil {
 				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
@@ -353,14 +376,19 @@ func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object
 			return nil, nil, fmt.Errorf("error copying source CDROM device state data at index %d: %s", i, err)
 		}
 		for k, v := range cm {
-			// Skip key and device_address here
+			// Skip key and device_address here. The placement of the source device
+			// is kept unless a controller type is set in configuration.
 			switch k {
 			case "key", "device_address":
 				continue
+			case "controller_type", "unit_number":
+				if cm["controller_type"].(string) == "" {
+					continue
+				}
 			}
 			nm.(map[string]interface{})[k] = v
 		}
-		r := NewCdromSubresource(c, d, nm.(map[string]interface{}), sm, i)
+		r := NewCdromSubresource(c, rc, d, nm.(map[string]interface{}), sm, i)
 		if !reflect.DeepEqual(sm, nm) {
 			// Update
 			cspec, err := r.Update(l)
@@ -378,7 +406,7 @@ func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object
 	if len(curSet) < len(srcSet) {
 		for i, si := range srcSet[len(curSet):] {
 			sm := si.(map[string]interface{})
-			r := NewCdromSubresource(c, d, sm, nil, i+len(curSet))
+			r := NewCdromSubresource(c, rc, d, sm, nil, i+len(curSet))
 			dspec, err := r.Delete(l)
 			if err != nil {
 				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
@@ -404,10 +432,18 @@ func CdromPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object
 func CdromDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
 	log.Printf("[DEBUG] CdromDiffOperation: Beginning diff validation")
 	cr := d.Get(subresourceTypeCdrom)
+	units := make(map[string]struct{})
 	for ci, ce := range cr.([]interface{}) {
 		cm := ce.(map[string]interface{})
-		r := NewCdromSubresource(c, d, cm, nil, ci)
-		if !structure.ValuesAvailable(fmt.Sprintf("%s.%d.", subresourceTypeCdrom, ci), []string{"datastore_id", "path"}, d) {
+		if ct := cm["controller_type"].(string); ct != "" {
+			unit := fmt.Sprintf("%s:%d", ct, cm["unit_number"].(int))
+			if _, ok := units[unit]; ok {
+				return fmt.Errorf("%s: duplicate %s unit_number %d", subresourceTypeCdrom, ct, cm["unit_number"].(int))
+			}
+			units[unit] = struct{}{}
+		}
+		r := NewCdromSubresource(c, nil, d, cm, nil, ci)
+		if !structure.ValuesAvailable(fmt.Sprintf("%s.%d.", subresourceTypeCdrom, ci), []string{"datastore_id", "path", "content_library_item_id"}, d) {
 			log.Printf("[DEBUG] CdromDiffOperation: Cdrom contains a value that depends on a computed value from another resource. Skipping validation")
 			return nil
 		}
@@ -426,11 +462,25 @@ func (r *CdromSubresource) ValidateDiff() error {
 	dsID := r.Get("datastore_id").(string)
 	path := r.Get("path").(string)
 	clientDevice := r.Get("client_device").(bool)
+	itemID := r.Get("content_library_item_id").(string)
 	switch {
-	case clientDevice && (dsID != "" || path != ""):
-		return fmt.Errorf("Cannot have both client_device parameter and ISO file parameters (datastore_id, path) set")
-	case !clientDevice && (dsID == "" || path == ""):
-		return fmt.Errorf("Either client_device or datastore_id and path must be set")
+	case clientDevice && (dsID != "" || path != "" || itemID != ""):
+		return fmt.Errorf("Cannot have both client_device parameter and ISO file parameters (datastore_id, path, content_library_item_id) set")
+	case itemID != "" && (dsID != "" || path != ""):
+		return fmt.Errorf("Cannot have both content_library_item_id and datastore_id or path set")
+	case !clientDevice && itemID == "" && (dsID == "" || path == ""):
+		return fmt.Errorf("Either client_device, content_library_item_id, or datastore_id and path must be set")
+	}
+	// Enforce the maximum unit number for the controllers of the selected type.
+	switch r.Get("controller_type").(string) {
+	case SubresourceControllerTypeIDE:
+		if maxUnit := r.rdd.Get("ide_controller_count").(int)*2 - 1; r.Get("unit_number").(int) > maxUnit {
+			return fmt.Errorf("unit_number too high (%d) - maximum value is %d with %d IDE controller(s)", r.Get("unit_number").(int), maxUnit, r.rdd.Get("ide_controller_count").(int))
+		}
+	case SubresourceControllerTypeSATA:
+		if maxUnit := r.rdd.Get("sata_controller_count").(int)*30 - 1; r.Get("unit_number").(int) > maxUnit {
+			return fmt.Errorf("unit_number too high (%d) - maximum value is %d with %d SATA controller(s)", r.Get("unit_number").(int), maxUnit, r.rdd.Get("sata_controller_count").(int))
+		}
 	}
 	log.Printf("[DEBUG] %s: Config validation complete", r)
 	return nil
@@ -440,19 +490,24 @@ func (r *CdromSubresource) ValidateDiff() error {
 func (r *CdromSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
 	log.Printf("[DEBUG] %s: Running create", r)
 	var spec []types.BaseVirtualDeviceConfigSpec
-	var ctlr types.BaseVirtualController
-	ctlr, err := r.ControllerForCreateUpdate(l, SubresourceControllerTypeIDE, 0)
-	if err != nil {
-		return nil, err
+	device := &types.VirtualCdrom{
+		VirtualDevice: types.VirtualDevice{
+			Key: l.NewKey(),
+			Connectable: &types.VirtualDeviceConnectInfo{
+				AllowGuestControl: true,
+				Connected:         true,
+				StartConnected:    true,
+			},
+		},
 	}
-
-	// We now have the controller on which we can create our device on.
-	device, err := l.CreateCdrom(ctlr.(*types.VirtualIDEController))
+	ctlr, err := r.assignCdrom(l, device)
 	if err != nil {
 		return nil, err
 	}
 	// Map the CDROM to the correct device
-	r.mapCdrom(device, l)
+	if err := r.mapCdrom(device, l); err != nil {
+		return nil, err
+	}
 	// Done here. Save IDs, push the device to the new device list and return.
 	if err := r.SaveDevIDs(device, ctlr); err != nil {
 		return nil, err
@@ -482,11 +537,17 @@ func (r *CdromSubresource) Read(l object.VirtualDeviceList) error {
 	switch backing := device.Backing.(type) {
 	case *types.VirtualCdromRemoteAtapiBackingInfo:
 		r.Set("client_device", true)
+		r.Set("content_library_item_id", "")
 	case *types.VirtualCdromIsoBackingInfo:
 		dp := &object.DatastorePath{}
 		if ok := dp.FromString(backing.FileName); !ok {
 			return fmt.Errorf("could not read datastore path in backing %q", backing.FileName)
 		}
+		// ISOs from a content library are tracked by item ID, so the datastore
+		// path they resolve to is not saved.
+		if r.Get("content_library_item_id").(string) != "" {
+			break
+		}
 		// If a vApp ISO was inserted, it will be removed if the VM is powered off
 		// and cause backing.Datastore to be nil.
 		if backing.Datastore != nil {
@@ -504,12 +565,14 @@ func (r *CdromSubresource) Read(l object.VirtualDeviceList) error {
 		r.Set("datastore_id", "")
 		r.Set("path", "")
 		r.Set("client_device", false)
+		r.Set("content_library_item_id", "")
 	}
 	// Save the device key and address data
 	ctlr, err := findControllerForDevice(l, d)
 	if err != nil {
 		return err
 	}
+	r.readPlacement(device, ctlr)
 	if err := r.SaveDevIDs(d, ctlr); err != nil {
 		return err
 	}
@@ -529,8 +592,21 @@ func (r *CdromSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtu
 		return nil, fmt.Errorf("device at %q is not a virtual CDROM device", l.Name(d))
 	}
 
+	// Move the CDROM if its placement has been changed in configuration.
+	if r.Get("controller_type").(string) != "" && (r.HasChange("controller_type") || r.HasChange("unit_number")) {
+		ctlr, err := r.assignCdrom(l, device)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.SaveDevIDs(device, ctlr); err != nil {
+			return nil, err
+		}
+		r.SetRestart("unit_number")
+	}
 	// Map the CDROM to the correct device
-	r.mapCdrom(device, l)
+	if err := r.mapCdrom(device, l); err != nil {
+		return nil, err
+	}
 	spec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
 	if err != nil {
 		return nil, err
@@ -560,12 +636,27 @@ func (r *CdromSubresource) Delete(l object.VirtualDeviceList) ([]types.BaseVirtu
 	return deleteSpec, nil
 }
 
-// mapCdrom takes a CdromSubresource and attaches either a client device or a datastore ISO.
+// mapCdrom takes a CdromSubresource and attaches either a client device, a
+// datastore ISO, or a content library ISO.
 func (r *CdromSubresource) mapCdrom(device *types.VirtualCdrom, l object.VirtualDeviceList) error {
 	dsID := r.Get("datastore_id").(string)
 	path := r.Get("path").(string)
 	clientDevice := r.Get("client_device").(bool)
+	itemID := r.Get("content_library_item_id").(string)
 	switch {
+	case itemID != "":
+		// If a content library item is set, the CDROM will be mapped to the ISO
+		// file backing the item.
+		if r.restClient == nil {
+			return fmt.Errorf("content_library_item_id requires a connection to vCenter")
+		}
+		iso, err := contentlibrary.ItemISOPath(r.client, r.restClient, itemID)
+		if err != nil {
+			return fmt.Errorf("cannot resolve content library item: %s", err)
+		}
+		device = l.InsertIso(device, iso)
+		l.Connect(device)
+		return nil
 	case dsID != "" && path != "":
 		// If the datastore ID and path are both set, the CDROM will be mapped to a file on a datastore.
 		ds, err := datastore.FromID(r.client, dsID)
@@ -594,6 +685,102 @@ func (r *CdromSubresource) mapCdrom(device *types.VirtualCdrom, l object.Virtual
 	panic(fmt.Sprintf("%s: no CDROM types specified", r))
 }
 
+// assignCdrom assigns a CDROM device to a controller according to
+// controller_type and unit_number. As with disks, the unit number is counted
+// across all controllers of the type, so IDE unit 2 is the first unit on the
+// second IDE controller. Any unit number, including 0, must be free.
+//
+// If no controller type is set, the first free unit on any IDE controller is
+// used. This matches how CDROM devices were placed before placement could be
+// set.
+func (r *CdromSubresource) assignCdrom(l object.VirtualDeviceList, device *types.VirtualCdrom) (types.BaseVirtualController, error) {
+	ct := r.Get("controller_type").(string)
+	if ct == "" {
+		return r.assignCdromFirstFree(l, device, SubresourceControllerTypeIDE)
+	}
+	number := r.Get("unit_number").(int)
+	perCtlr := cdromUnitsPerController(ct)
+	bus := number / perCtlr
+	unit := int32(number % perCtlr)
+
+	ctlr, err := r.ControllerForCreateUpdate(l, ct, bus)
+	if err != nil {
+		return nil, err
+	}
+	if cdromUsedUnits(l, ctlr, device, perCtlr)[unit] {
+		return nil, fmt.Errorf("unit number %d on %s bus %d is in use", unit, ct, bus)
+	}
+	device.ControllerKey = ctlr.GetVirtualController().Key
+	device.UnitNumber = &unit
+	return ctlr, nil
+}
+
+// assignCdromFirstFree assigns a CDROM device to the first free unit on the
+// controllers of type ct, searching the controllers in bus order.
+func (r *CdromSubresource) assignCdromFirstFree(l object.VirtualDeviceList, device *types.VirtualCdrom, ct string) (types.BaseVirtualController, error) {
+	perCtlr := cdromUnitsPerController(ct)
+	for bus := 0; ; bus++ {
+		ctlr, err := pickController(l, bus, ct)
+		if err != nil {
+			if bus == 0 {
+				return nil, fmt.Errorf("could not find an available %s controller", ct)
+			}
+			return nil, fmt.Errorf("no free units on %s controllers", ct)
+		}
+		for i, used := range cdromUsedUnits(l, ctlr, device, perCtlr) {
+			if !used {
+				unit := int32(i)
+				device.ControllerKey = ctlr.GetVirtualController().Key
+				device.UnitNumber = &unit
+				return ctlr, nil
+			}
+		}
+	}
+}
+
+// cdromUsedUnits returns which of the units of a controller are used by
+// devices other than the supplied one.
+func cdromUsedUnits(l object.VirtualDeviceList, ctlr types.BaseVirtualController, device *types.VirtualCdrom, perCtlr int) []bool {
+	units := make([]bool, perCtlr)
+	ckey := ctlr.GetVirtualController().Key
+	for _, dev := range l {
+		vd := dev.GetVirtualDevice()
+		if vd.ControllerKey != ckey || vd.UnitNumber == nil || vd.Key == device.Key || int(*vd.UnitNumber) >= perCtlr {
+			continue
+		}
+		units[*vd.UnitNumber] = true
+	}
+	return units
+}
+
+// readPlacement saves the controller type and unit number of a CDROM device.
+func (r *CdromSubresource) readPlacement(device *types.VirtualCdrom, ctlr types.BaseVirtualController) {
+	var ct string
+	switch ctlr.(type) {
+	case *types.VirtualIDEController:
+		ct = SubresourceControllerTypeIDE
+	case types.BaseVirtualSATAController:
+		ct = SubresourceControllerTypeSATA
+	default:
+		return
+	}
+	var unit int
+	if device.UnitNumber != nil {
+		unit = int(*device.UnitNumber)
+	}
+	r.Set("controller_type", ct)
+	r.Set("unit_number", int(ctlr.GetVirtualController().BusNumber)*cdromUnitsPerController(ct)+unit)
+}
+
+// cdromUnitsPerController returns the number of units on a controller of the
+// given type.
+func cdromUnitsPerController(ct string) int {
+	if ct == SubresourceControllerTypeSATA {
+		return 30
+	}
+	return 2
+}
+
 // VerifyVAppTransport validates that all the required components are included in
 // the virtual machine configuration if vApp properties are set.
 func VerifyVAppTransport(d *schema.ResourceDiff, c *govmomi.Client) error {
@@ -0,0 +1,162 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testCdromData returns the data for a cdrom sub-resource, with any values
+// not in data set to their zero values.
+func testCdromData(data map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{
+		"datastore_id":            "",
+		"path":                    "",
+		"client_device":           false,
+		"content_library_item_id": "",
+		"controller_type":         "",
+		"unit_number":             0,
+		"key":                     0,
+		"device_address":          "",
+	}
+	for k, v := range data {
+		m[k] = v
+	}
+	return m
+}
+
+func TestCdromValidateDiff(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "datastore ISO",
+			data: map[string]interface{}{
+				"datastore_id": "datastore-1",
+				"path":         "iso/os.iso",
+			},
+		},
+		{
+			name: "content library ISO",
+			data: map[string]interface{}{
+				"content_library_item_id": "a1b2c3",
+			},
+		},
+		{
+			name: "content library ISO with datastore path",
+			data: map[string]interface{}{
+				"content_library_item_id": "a1b2c3",
+				"datastore_id":            "datastore-1",
+				"path":                    "iso/os.iso",
+			},
+			expectErr: true,
+		},
+		{
+			name: "client device with content library ISO",
+			data: map[string]interface{}{
+				"client_device":           true,
+				"content_library_item_id": "a1b2c3",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "no backing",
+			data:      map[string]interface{}{},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewCdromSubresource(nil, nil, nil, testCdromData(tc.data), nil, 0)
+			err := r.ValidateDiff()
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCdromAssign(t *testing.T) {
+	unit := func(n int32) *int32 { return &n }
+	l := object.VirtualDeviceList{
+		&types.VirtualIDEController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 200}, BusNumber: 0}},
+		&types.VirtualIDEController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 201}, BusNumber: 1}},
+		&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: 3000, ControllerKey: 200, UnitNumber: unit(0)}},
+	}
+
+	cases := []struct {
+		name      string
+		data      map[string]interface{}
+		devices   object.VirtualDeviceList
+		ctlrKey   int32
+		unit      int32
+		expectErr bool
+	}{
+		{
+			name:    "default placement skips used unit",
+			data:    map[string]interface{}{},
+			ctlrKey: 200,
+			unit:    1,
+		},
+		{
+			name:    "second IDE controller",
+			data:    map[string]interface{}{"controller_type": "ide", "unit_number": 2},
+			ctlrKey: 201,
+			unit:    0,
+		},
+		{
+			name:    "default placement on second IDE controller",
+			data:    map[string]interface{}{},
+			devices: object.VirtualDeviceList{&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: 3001, ControllerKey: 200, UnitNumber: unit(1)}}},
+			ctlrKey: 201,
+			unit:    0,
+		},
+		{
+			name: "default placement with no free units",
+			data: map[string]interface{}{},
+			devices: object.VirtualDeviceList{
+				&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: 3001, ControllerKey: 200, UnitNumber: unit(1)}},
+				&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: 3002, ControllerKey: 201, UnitNumber: unit(0)}},
+				&types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: 3003, ControllerKey: 201, UnitNumber: unit(1)}},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "unit zero in use",
+			data:      map[string]interface{}{"controller_type": "ide", "unit_number": 0},
+			expectErr: true,
+		},
+		{
+			name:      "missing SATA controller",
+			data:      map[string]interface{}{"controller_type": "sata", "unit_number": 0},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewCdromSubresource(nil, nil, nil, testCdromData(tc.data), nil, 0)
+			device := &types.VirtualCdrom{VirtualDevice: types.VirtualDevice{Key: -201}}
+			_, err := r.assignCdrom(append(l[:len(l):len(l)], tc.devices...), device)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if device.ControllerKey != tc.ctlrKey || *device.UnitNumber != tc.unit {
+				t.Fatalf("expected controller %d unit %d, got controller %d unit %d", tc.ctlrKey, tc.unit, device.ControllerKey, *device.UnitNumber)
+			}
+		})
+	}
+}
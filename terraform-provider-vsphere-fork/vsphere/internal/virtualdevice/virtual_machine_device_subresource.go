@@ -23,6 +23,9 @@ const (
 	subresourceTypeDisk             = "disk"
 	subresourceTypeNetworkInterface = "network_interface"
 	subresourceTypeCdrom            = "cdrom"
+	subresourceTypeSerialPort       = "serial_port"
+	subresourceTypeUSBController    = "usb_controller"
+	subresourceTypeVideoCard        = "video_card"
 )
 
 const (
@@ -56,6 +59,10 @@ const (
 	// SubresourceControllerTypePCI is a string representation of PCI controller
 	// classes.
 	SubresourceControllerTypePCI = "pci"
+
+	// SubresourceControllerTypeSIO is a string representation of super I/O
+	// controller classes, which serial and parallel ports are attached to.
+	SubresourceControllerTypeSIO = "sio"
 )
 
 const (
@@ -73,6 +80,7 @@ var subresourceIDControllerTypeAllowedValues = []string{
 	SubresourceControllerTypeSCSI,
 	SubresourceControllerTypePCI,
 	SubresourceControllerTypeSATA,
+	SubresourceControllerTypeSIO,
 }
 
 var sharesLevelAllowedValues = []string{
@@ -150,6 +158,8 @@ func controllerTypeToClass(c types.BaseVirtualController) (string, error) {
 		t = SubresourceControllerTypeSATA
 	case *types.VirtualPCIController:
 		t = SubresourceControllerTypePCI
+	case *types.VirtualSIOController:
+		t = SubresourceControllerTypeSIO
 	case *types.ParaVirtualSCSIController, *types.VirtualBusLogicController,
 		*types.VirtualLsiLogicController, *types.VirtualLsiLogicSASController:
 		t = SubresourceControllerTypeSCSI
@@ -335,10 +345,16 @@ func computeDevAddr(device types.BaseVirtualDevice, ctlr types.BaseVirtualContro
 	if err != nil {
 		return "", err
 	}
+	// Some devices, such as USB controllers, may not have a unit number
+	// assigned until vSphere has created them.
+	var unit int32
+	if vd.UnitNumber != nil {
+		unit = *vd.UnitNumber
+	}
 	parts := []string{
 		ctype,
 		strconv.Itoa(int(vc.BusNumber)),
-		strconv.Itoa(int(structure.DeRef(vd.UnitNumber).(int32))),
+		strconv.Itoa(int(unit)),
 	}
 	return strings.Join(parts, ":"), nil
 }
@@ -414,6 +430,10 @@ func findVirtualDeviceInListControllerSelectFunc(ct string, cb int) func(types.B
 			if _, ok := device.(*types.VirtualPCIController); !ok {
 				return false
 			}
+		case SubresourceControllerTypeSIO:
+			if _, ok := device.(*types.VirtualSIOController); !ok {
+				return false
+			}
 		}
 		vc := device.(types.BaseVirtualController).GetVirtualController()
 		if vc.BusNumber == int32(cb) {
@@ -757,6 +777,10 @@ func pickController(l object.VirtualDeviceList, bus int, ct string) (types.BaseV
 			if ct == "pci" {
 				return d.GetVirtualController().BusNumber == int32(bus)
 			}
+		case *types.VirtualSIOController:
+			if ct == "sio" {
+				return d.GetVirtualController().BusNumber == int32(bus)
+			}
 		}
 		return false
 	})
@@ -0,0 +1,342 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/mitchellh/copystructure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// deviceSubresource is the interface that derivative subresources managed
+// through the generic device operations in this file need to implement.
+type deviceSubresource interface {
+	Create(object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error)
+	Read(object.VirtualDeviceList) error
+	Update(object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error)
+	Delete(object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error)
+
+	Addr() string
+	Get(string) interface{}
+	Data() map[string]interface{}
+}
+
+// deviceSubresourceType describes a class of device subresource for the
+// generic device operations.
+//
+// The generic operations follow the same key tracking workflow as the CDROM
+// and network interface subresources. Devices that are not in configuration
+// are removed, unless the subresource type is computed.
+type deviceSubresourceType struct {
+	// The subresource type, such as "serial_port".
+	srtype string
+
+	// A friendly name for the device class, used in logging.
+	name string
+
+	// computed is true for subresource types that are computed when not set in
+	// configuration, such as the video card that every virtual machine has.
+	// When none are configured, the devices on the virtual machine are left as
+	// they are.
+	computed bool
+
+	// schemaFunc returns the schema for the subresource type.
+	schemaFunc func() map[string]*schema.Schema
+
+	// newFunc returns a new instance of the subresource.
+	newFunc func(*govmomi.Client, resourceDataDiff, map[string]interface{}, map[string]interface{}, int) deviceSubresource
+
+	// selectFunc selects the devices in a device list that are managed by
+	// this subresource type.
+	selectFunc func(types.BaseVirtualDevice) bool
+}
+
+// applyOperation processes an apply operation for all subresources of this
+// type in the resource. The updated VirtualDeviceList and the list of device
+// changes are returned.
+func (t *deviceSubresourceType) applyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s apply operation: Beginning apply operation", t.name)
+	o, n := d.GetChange(t.srtype)
+	ods := o.([]interface{})
+	nds := n.([]interface{})
+
+	var spec []types.BaseVirtualDeviceConfigSpec
+
+	// Look for removed devices first.
+	log.Printf("[DEBUG] %s apply operation: Looking for resources to delete", t.name)
+nextOld:
+	for n, oe := range ods {
+		om := oe.(map[string]interface{})
+		for _, ne := range nds {
+			nm := ne.(map[string]interface{})
+			if om["key"] == nm["key"] {
+				continue nextOld
+			}
+		}
+		r := t.newFunc(c, d, om, nil, n)
+		dspec, err := r.Delete(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		l = applyDeviceChange(l, dspec)
+		spec = append(spec, dspec...)
+	}
+
+	// Now check for creates and updates. The results of this operation are
+	// committed to state after the operation completes.
+	var updates []interface{}
+	log.Printf("[DEBUG] %s apply operation: Looking for resources to create or update", t.name)
+	for n, ne := range nds {
+		nm := ne.(map[string]interface{})
+		if n < len(ods) {
+			// This is an update
+			om := ods[n].(map[string]interface{})
+			if nm["key"] != om["key"] {
+				return nil, nil, fmt.Errorf("key mismatch on %s.%d (old: %d, new: %d). This is a bug with the provider, please report it", t.srtype, n, nm["key"].(int), om["key"].(int))
+			}
+			if reflect.DeepEqual(nm, om) {
+				// no change is a no-op
+				updates = append(updates, nm)
+				log.Printf("[DEBUG] %s apply operation: No-op resource: key %d", t.name, nm["key"].(int))
+				continue
+			}
+			r := t.newFunc(c, d, nm, om, n)
+			uspec, err := r.Update(l)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			l = applyDeviceChange(l, uspec)
+			spec = append(spec, uspec...)
+			updates = append(updates, r.Data())
+			continue
+		}
+		// New device
+		r := t.newFunc(c, d, nm, nil, n)
+		cspec, err := r.Create(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		l = applyDeviceChange(l, cspec)
+		spec = append(spec, cspec...)
+		updates = append(updates, r.Data())
+	}
+
+	log.Printf("[DEBUG] %s apply operation: Post-apply final resource list: %s", t.name, subresourceListString(updates))
+	if err := d.Set(t.srtype, updates); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[DEBUG] %s apply operation: Device list at end of operation: %s", t.name, DeviceListString(l))
+	log.Printf("[DEBUG] %s apply operation: Device config operations from apply: %s", t.name, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s apply operation: Apply complete, returning updated spec", t.name)
+	return l, spec, nil
+}
+
+// refreshOperation processes a refresh operation for all subresources of this
+// type in the resource. Devices that are not yet tracked in state are added
+// to it.
+func (t *deviceSubresourceType) refreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] %s refresh operation: Beginning refresh", t.name)
+	devices := l.Select(t.selectFunc)
+	log.Printf("[DEBUG] %s refresh operation: Devices located: %s", t.name, DeviceListString(devices))
+	curSet := d.Get(t.srtype).([]interface{})
+	log.Printf("[DEBUG] %s refresh operation: Current resource set from state: %s", t.name, subresourceListString(curSet))
+	var newSet []interface{}
+
+	// First check for negative keys. These are freshly added devices that are
+	// usually coming into read post-create.
+	log.Printf("[DEBUG] %s refresh operation: Looking for freshly-created resources to read in", t.name)
+	for n, item := range curSet {
+		m := item.(map[string]interface{})
+		if m["key"].(int) < 1 {
+			r := t.newFunc(c, d, m, nil, n)
+			if err := r.Read(l); err != nil {
+				return fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			if r.Get("key").(int) < 1 {
+				// This should not have happened - if it did, our device
+				// creation/update logic failed somehow that we were not able to track.
+				return fmt.Errorf("device %d with address %s still unaccounted for after update/read", r.Get("key").(int), r.Get("device_address").(string))
+			}
+			newSet = append(newSet, r.Data())
+			for i := 0; i < len(devices); i++ {
+				if devices[i].GetVirtualDevice().Key == int32(r.Get("key").(int)) {
+					devices = append(devices[:i], devices[i+1:]...)
+					i--
+				}
+			}
+		}
+	}
+
+	// Go over the remaining devices, refresh via key, and then remove their
+	// entries as well.
+	log.Printf("[DEBUG] %s refresh operation: Looking for devices known in state", t.name)
+	for i := 0; i < len(devices); i++ {
+		device := devices[i]
+		for n, item := range curSet {
+			m := item.(map[string]interface{})
+			if m["key"].(int) < 1 || device.GetVirtualDevice().Key != int32(m["key"].(int)) {
+				continue
+			}
+			r := t.newFunc(c, d, m, nil, n)
+			if err := r.Read(l); err != nil {
+				return fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			newSet = append(newSet, r.Data())
+			devices = append(devices[:i], devices[i+1:]...)
+			i--
+			break
+		}
+	}
+	log.Printf("[DEBUG] %s refresh operation: Probable orphaned devices: %s", t.name, DeviceListString(devices))
+
+	// Finally, any device that is still here is orphaned. They should be added
+	// as new devices.
+	orphans, err := t.readDevices(d, c, l, devices, len(newSet))
+	if err != nil {
+		return err
+	}
+	newSet = append(newSet, orphans...)
+
+	log.Printf("[DEBUG] %s refresh operation: Resource set to write after adding orphaned devices: %s", t.name, subresourceListString(newSet))
+	log.Printf("[DEBUG] %s refresh operation: Refresh operation complete, sending new resource set", t.name)
+	return d.Set(t.srtype, newSet)
+}
+
+// postCloneOperation normalizes devices of this type on a freshly-cloned
+// virtual machine and returns any necessary device change operations. It
+// also sets the state in advance of the post-create read.
+//
+// If the subresource type is computed and none are set in configuration, the
+// devices from the source are left as they are. Otherwise, source devices past
+// the end of the configured ones are removed. Computed values that are not set
+// in configuration are kept from the source device.
+func (t *deviceSubresourceType) postCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s post-clone operation: Looking for post-clone device changes", t.name)
+	devices := l.Select(t.selectFunc)
+	log.Printf("[DEBUG] %s post-clone operation: Devices located: %s", t.name, DeviceListString(devices))
+	curSet := d.Get(t.srtype).([]interface{})
+	log.Printf("[DEBUG] %s post-clone operation: Current resource set from configuration: %s", t.name, subresourceListString(curSet))
+
+	// Populate the source set as if the devices were orphaned. This give us a
+	// base to diff off of.
+	srcSet, err := t.readDevices(d, c, l, devices, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.computed && len(curSet) < 1 {
+		log.Printf("[DEBUG] %s post-clone operation: No devices in configuration, keeping source devices", t.name)
+		if err := d.Set(t.srtype, srcSet); err != nil {
+			return nil, nil, err
+		}
+		return l, nil, nil
+	}
+
+	// Now go over our current set, treating it like an apply:
+	//
+	// * Devices past the boundaries of existing devices are created
+	// * Devices within the bounds are changed
+	// * Data at the source with the same data after patching config data is a
+	// no-op, but we still push the device's state
+	var spec []types.BaseVirtualDeviceConfigSpec
+	var updates []interface{}
+	srschema := t.schemaFunc()
+	for i, ci := range curSet {
+		cm := ci.(map[string]interface{})
+		if i > len(srcSet)-1 {
+			// New device
+			r := t.newFunc(c, d, cm, nil, i)
+			cspec, err := r.Create(l)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			l = applyDeviceChange(l, cspec)
+			spec = append(spec, cspec...)
+			updates = append(updates, r.Data())
+			continue
+		}
+		sm := srcSet[i].(map[string]interface{})
+		nm, err := copystructure.Copy(sm)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error copying source %s state data at index %d: %s", t.name, i, err)
+		}
+		for k, v := range cm {
+			// Skip key and device_address here
+			switch k {
+			case "key", "device_address":
+				continue
+			}
+			if s, ok := srschema[k]; ok && s.Computed {
+				if _, ok := d.GetOkExists(fmt.Sprintf("%s.%d.%s", t.srtype, i, k)); !ok {
+					continue
+				}
+			}
+			nm.(map[string]interface{})[k] = v
+		}
+		r := t.newFunc(c, d, nm.(map[string]interface{}), sm, i)
+		if !reflect.DeepEqual(sm, nm) {
+			// Update
+			cspec, err := r.Update(l)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			l = applyDeviceChange(l, cspec)
+			spec = append(spec, cspec...)
+		}
+		updates = append(updates, r.Data())
+	}
+
+	// Any other device past the end of the devices listed in config needs to
+	// be removed.
+	if len(curSet) < len(srcSet) {
+		for i, si := range srcSet[len(curSet):] {
+			sm := si.(map[string]interface{})
+			r := t.newFunc(c, d, sm, nil, i+len(curSet))
+			dspec, err := r.Delete(l)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			l = applyDeviceChange(l, dspec)
+			spec = append(spec, dspec...)
+		}
+	}
+
+	log.Printf("[DEBUG] %s post-clone operation: Post-clone final resource list: %s", t.name, subresourceListString(updates))
+	if err := d.Set(t.srtype, updates); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[DEBUG] %s post-clone operation: Device list at end of operation: %s", t.name, DeviceListString(l))
+	log.Printf("[DEBUG] %s post-clone operation: Device config operations from post-clone: %s", t.name, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s post-clone operation: Operation complete, returning updated spec", t.name)
+	return l, spec, nil
+}
+
+// readDevices reads the supplied devices into new subresource data, as if
+// they were orphaned devices not yet tracked in state. offset is the index
+// that the first device will be placed at in the subresource list.
+func (t *deviceSubresourceType) readDevices(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList, devices object.VirtualDeviceList, offset int) ([]interface{}, error) {
+	var set []interface{}
+	for n, device := range devices {
+		m := make(map[string]interface{})
+		vd := device.GetVirtualDevice()
+		ctlr := l.FindByKey(vd.ControllerKey)
+		if ctlr == nil {
+			return nil, fmt.Errorf("could not find controller with key %d", vd.ControllerKey)
+		}
+		m["key"] = int(vd.Key)
+		var err error
+		m["device_address"], err = computeDevAddr(vd, ctlr.(types.BaseVirtualController))
+		if err != nil {
+			return nil, fmt.Errorf("error computing device address: %s", err)
+		}
+		r := t.newFunc(c, d, m, nil, n+offset)
+		if err := r.Read(l); err != nil {
+			return nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		set = append(set, r.Data())
+	}
+	return set, nil
+}
@@ -736,6 +736,69 @@ nextNew:
 	return d.SetNew(subresourceTypeDisk, normalized)
 }
 
+// DiskSpaceRequirements returns the datastore space, in bytes, that the disk
+// sub-resources in a diff need over and above what they use today, keyed by
+// datastore ID. Only thick provisioned disks that are created by Terraform are
+// counted. Disks that do not have a datastore of their own are counted against
+// defaultDatastoreID, and are left out if it is empty.
+//
+// deltaDisks is the number of disks, in unit number order, that are created
+// as delta disks of a linked or instant clone source. These start out empty
+// and are the same size as the source disks, so they are not counted.
+//
+// The supplied lists should be the old and new values of the disk key after
+// DiskDiffOperation has run.
+func DiskSpaceRequirements(o, n []interface{}, defaultDatastoreID string, deltaDisks int) map[string]int64 {
+	old := make(map[string]map[string]interface{})
+	for _, oe := range o {
+		om := oe.(map[string]interface{})
+		if name, err := diskLabelOrName(om); err == nil {
+			old[name] = om
+		}
+	}
+	if deltaDisks > 0 {
+		sorted := make([]interface{}, len(n))
+		copy(sorted, n)
+		sort.Stable(virtualDiskSubresourceSorter(sorted))
+		if deltaDisks > len(sorted) {
+			deltaDisks = len(sorted)
+		}
+		n = sorted[deltaDisks:]
+	}
+	required := make(map[string]int64)
+	for _, ne := range n {
+		nm := ne.(map[string]interface{})
+		if nm["attach"].(bool) || nm["thin_provisioned"].(bool) {
+			continue
+		}
+		name, err := diskLabelOrName(nm)
+		if err != nil || name == diskDeletedName || name == diskDetachedName {
+			continue
+		}
+		dsID := diskSpaceDatastoreID(nm, defaultDatastoreID)
+		if dsID == "" {
+			continue
+		}
+		size, _ := nm["size"].(int)
+		if om, ok := old[name]; ok && diskSpaceDatastoreID(om, defaultDatastoreID) == dsID {
+			// Existing disks on the same datastore only need space to grow.
+			osize, _ := om["size"].(int)
+			size -= osize
+		}
+		if size > 0 {
+			required[dsID] += structure.GiBToByte(size)
+		}
+	}
+	return required
+}
+
+func diskSpaceDatastoreID(data map[string]interface{}, defaultDatastoreID string) string {
+	if dsID, ok := data["datastore_id"].(string); ok && dsID != "" && dsID != diskDatastoreComputedName {
+		return dsID
+	}
+	return defaultDatastoreID
+}
+
 // DiskCloneValidateOperation takes the VirtualDeviceList, which should come
 // from a source VM or template, and validates the following:
 //
@@ -1,6 +1,7 @@
 package virtualdevice
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/vmware/govmomi/vim25/types"
@@ -37,3 +38,112 @@ func TestDiskCapacityInGiB(t *testing.T) {
 		})
 	}
 }
+
+func testDiskSpaceEntry(label, datastoreID string, size int, thin bool) map[string]interface{} {
+	return map[string]interface{}{
+		"label":            label,
+		"datastore_id":     datastoreID,
+		"size":             size,
+		"thin_provisioned": thin,
+		"attach":           false,
+		"unit_number":      0,
+	}
+}
+
+func testDiskSpaceUnitEntry(label, datastoreID string, size int, unit int) map[string]interface{} {
+	m := testDiskSpaceEntry(label, datastoreID, size, false)
+	m["unit_number"] = unit
+	return m
+}
+
+func TestDiskSpaceRequirements(t *testing.T) {
+	const gib = int64(1024 * 1024 * 1024)
+	cases := []struct {
+		name     string
+		old      []interface{}
+		new      []interface{}
+		delta    int
+		expected map[string]int64
+	}{
+		{
+			name: "new thick disks",
+			new: []interface{}{
+				testDiskSpaceEntry("disk0", diskDatastoreComputedName, 10, false),
+				testDiskSpaceEntry("disk1", "datastore-2", 20, false),
+			},
+			expected: map[string]int64{
+				"datastore-1": 10 * gib,
+				"datastore-2": 20 * gib,
+			},
+		},
+		{
+			name: "thin disks are skipped",
+			new: []interface{}{
+				testDiskSpaceEntry("disk0", diskDatastoreComputedName, 10, true),
+			},
+			expected: map[string]int64{},
+		},
+		{
+			name: "grown disk",
+			old: []interface{}{
+				testDiskSpaceEntry("disk0", "datastore-1", 10, false),
+			},
+			new: []interface{}{
+				testDiskSpaceEntry("disk0", "datastore-1", 15, false),
+			},
+			expected: map[string]int64{
+				"datastore-1": 5 * gib,
+			},
+		},
+		{
+			name: "moved disk",
+			old: []interface{}{
+				testDiskSpaceEntry("disk0", "datastore-1", 10, false),
+			},
+			new: []interface{}{
+				testDiskSpaceEntry("disk0", "datastore-2", 10, false),
+			},
+			expected: map[string]int64{
+				"datastore-2": 10 * gib,
+			},
+		},
+		{
+			name: "deleted disk",
+			old: []interface{}{
+				testDiskSpaceEntry("disk0", "datastore-1", 10, false),
+			},
+			new: []interface{}{
+				testDiskSpaceEntry(diskDeletedName, "datastore-1", 10, false),
+			},
+			expected: map[string]int64{},
+		},
+		{
+			name: "linked clone",
+			new: []interface{}{
+				testDiskSpaceUnitEntry("disk2", "datastore-1", 30, 2),
+				testDiskSpaceUnitEntry("disk0", "datastore-1", 10, 0),
+				testDiskSpaceUnitEntry("disk1", "datastore-2", 20, 1),
+			},
+			delta: 2,
+			expected: map[string]int64{
+				"datastore-1": 30 * gib,
+			},
+		},
+		{
+			name: "linked clone without new disks",
+			new: []interface{}{
+				testDiskSpaceUnitEntry("disk0", "datastore-1", 10, 0),
+			},
+			delta:    2,
+			expected: map[string]int64{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := DiskSpaceRequirements(tc.old, tc.new, "datastore-1", tc.delta)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
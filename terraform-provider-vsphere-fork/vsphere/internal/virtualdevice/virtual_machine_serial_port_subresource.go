@@ -0,0 +1,435 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	serialPortBackingTypeNetwork = "network"
+	serialPortBackingTypePipe    = "pipe"
+	serialPortBackingTypeFile    = "file"
+	serialPortBackingTypeDevice  = "device"
+)
+
+var serialPortBackingTypeAllowedValues = []string{
+	serialPortBackingTypeNetwork,
+	serialPortBackingTypePipe,
+	serialPortBackingTypeFile,
+	serialPortBackingTypeDevice,
+}
+
+var serialPortDirectionAllowedValues = []string{
+	string(types.VirtualDeviceURIBackingOptionDirectionServer),
+	string(types.VirtualDeviceURIBackingOptionDirectionClient),
+}
+
+var serialPortPipeEndpointAllowedValues = []string{
+	string(types.VirtualSerialPortEndPointServer),
+	string(types.VirtualSerialPortEndPointClient),
+}
+
+// serialPortSubresourceType describes the serial_port sub-resource for the
+// generic device operations.
+var serialPortSubresourceType = &deviceSubresourceType{
+	srtype:     subresourceTypeSerialPort,
+	name:       "serial port",
+	computed:   true,
+	schemaFunc: SerialPortSubresourceSchema,
+	newFunc: func(c *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) deviceSubresource {
+		return NewSerialPortSubresource(c, rdd, d, old, idx)
+	},
+	selectFunc: func(device types.BaseVirtualDevice) bool {
+		_, ok := device.(*types.VirtualSerialPort)
+		return ok
+	},
+}
+
+// SerialPortSubresourceSchema represents the schema for the serial_port
+// sub-resource.
+func SerialPortSubresourceSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"backing_type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The backing for this serial port. Can be one of network, pipe, file, or device.",
+			ValidateFunc: validation.StringInSlice(serialPortBackingTypeAllowedValues, false),
+		},
+		// VirtualSerialPortURIBackingInfo
+		"service_uri": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The URI of the network service for a network backed serial port, such as telnet://:23000 or tcp://10.0.0.1:5000.",
+		},
+		"direction": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.VirtualDeviceURIBackingOptionDirectionServer),
+			Description:  "Whether the virtual machine listens for (server) or initiates (client) network connections. Can be one of server or client.",
+			ValidateFunc: validation.StringInSlice(serialPortDirectionAllowedValues, false),
+		},
+		"proxy_uri": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The URI of a virtual serial port concentrator (vSPC) to connect a network backed serial port through.",
+		},
+		// VirtualSerialPortPipeBackingInfo
+		"pipe_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The name of the pipe for a pipe backed serial port.",
+		},
+		"pipe_endpoint": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.VirtualSerialPortEndPointServer),
+			Description:  "The end of the pipe that the virtual machine is on. Can be one of server or client.",
+			ValidateFunc: validation.StringInSlice(serialPortPipeEndpointAllowedValues, false),
+		},
+		"no_rx_loss": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Optimize a pipe backed serial port for throughput instead of latency, without losing received data.",
+		},
+		// VirtualSerialPortFileBackingInfo
+		"datastore_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The datastore ID of the output file for a file backed serial port.",
+		},
+		"path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The path of the output file on the datastore for a file backed serial port.",
+		},
+		// VirtualSerialPortDeviceBackingInfo
+		"device_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The name of the host serial device for a device backed serial port. If not set, the device is detected automatically.",
+		},
+		// VirtualSerialPort
+		"yield_on_poll": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Allow the guest to yield the CPU when polling the serial port.",
+		},
+	}
+	structure.MergeSchema(s, subresourceSchema())
+	return s
+}
+
+// SerialPortSubresource represents a vsphere_virtual_machine serial_port
+// sub-resource, with a complex device lifecycle.
+type SerialPortSubresource struct {
+	*Subresource
+}
+
+// NewSerialPortSubresource returns a subresource populated with all of the
+// necessary fields.
+func NewSerialPortSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *SerialPortSubresource {
+	sr := &SerialPortSubresource{
+		Subresource: &Subresource{
+			schema:  SerialPortSubresourceSchema(),
+			client:  client,
+			srtype:  subresourceTypeSerialPort,
+			data:    d,
+			olddata: old,
+			rdd:     rdd,
+		},
+	}
+	sr.Index = idx
+	return sr
+}
+
+// SerialPortApplyOperation processes an apply operation for all serial ports
+// in the resource.
+//
+// The function takes the root resource's ResourceData, the provider
+// connection, and the device list as known to vSphere at the start of this
+// operation. All serial port operations are carried out, with both the
+// complete, updated, VirtualDeviceList, and the complete list of changes
+// returned as a slice of BaseVirtualDeviceConfigSpec.
+func SerialPortApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return serialPortSubresourceType.applyOperation(d, c, l)
+}
+
+// SerialPortRefreshOperation processes a refresh operation for all of the
+// serial ports in the resource.
+func SerialPortRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
+	return serialPortSubresourceType.refreshOperation(d, c, l)
+}
+
+// SerialPortPostCloneOperation normalizes serial ports on a freshly-cloned
+// virtual machine and outputs any necessary device change operations.
+func SerialPortPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return serialPortSubresourceType.postCloneOperation(d, c, l)
+}
+
+// SerialPortDiffOperation performs operations relevant to managing the diff
+// on serial_port sub-resources.
+func SerialPortDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
+	log.Printf("[DEBUG] SerialPortDiffOperation: Beginning diff validation")
+	for i, e := range d.Get(subresourceTypeSerialPort).([]interface{}) {
+		if !structure.ValuesAvailable(fmt.Sprintf("%s.%d.", subresourceTypeSerialPort, i), []string{"service_uri", "proxy_uri", "pipe_name", "datastore_id", "path", "device_name"}, d) {
+			log.Printf("[DEBUG] SerialPortDiffOperation: Serial port contains a value that depends on a computed value from another resource. Skipping validation")
+			return nil
+		}
+		r := NewSerialPortSubresource(c, d, e.(map[string]interface{}), nil, i)
+		if err := r.ValidateDiff(); err != nil {
+			return fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+	}
+	log.Printf("[DEBUG] SerialPortDiffOperation: Diff validation complete")
+	return nil
+}
+
+// ValidateDiff performs any complex validation of an individual serial_port
+// sub-resource that can't be done in schema alone.
+func (r *SerialPortSubresource) ValidateDiff() error {
+	log.Printf("[DEBUG] %s: Beginning serial port configuration validation", r)
+	backing := r.Get("backing_type").(string)
+	fields := map[string][]string{
+		serialPortBackingTypeNetwork: {"service_uri", "proxy_uri"},
+		serialPortBackingTypePipe:    {"pipe_name"},
+		serialPortBackingTypeFile:    {"datastore_id", "path"},
+		serialPortBackingTypeDevice:  {"device_name"},
+	}
+	for t, keys := range fields {
+		if t == backing {
+			continue
+		}
+		for _, k := range keys {
+			if r.Get(k).(string) != "" {
+				return fmt.Errorf("%s cannot be set with backing_type %s", k, backing)
+			}
+		}
+	}
+	switch backing {
+	case serialPortBackingTypeNetwork:
+		if r.Get("service_uri").(string) == "" {
+			return fmt.Errorf("service_uri must be set with backing_type %s", backing)
+		}
+	case serialPortBackingTypePipe:
+		if r.Get("pipe_name").(string) == "" {
+			return fmt.Errorf("pipe_name must be set with backing_type %s", backing)
+		}
+	case serialPortBackingTypeFile:
+		if r.Get("datastore_id").(string) == "" || r.Get("path").(string) == "" {
+			return fmt.Errorf("datastore_id and path must be set with backing_type %s", backing)
+		}
+	}
+	log.Printf("[DEBUG] %s: Config validation complete", r)
+	return nil
+}
+
+// Create creates a vsphere_virtual_machine serial_port sub-resource.
+func (r *SerialPortSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Running create", r)
+	var spec []types.BaseVirtualDeviceConfigSpec
+	ctlr, err := r.ControllerForCreateUpdate(l, SubresourceControllerTypeSIO, 0)
+	if err != nil {
+		return nil, err
+	}
+	device := &types.VirtualSerialPort{}
+	l.AssignController(device, ctlr)
+	device.Key = l.NewKey()
+	device.Connectable = &types.VirtualDeviceConnectInfo{
+		StartConnected:    true,
+		AllowGuestControl: true,
+		Connected:         true,
+	}
+	if err := r.expandSerialPort(device); err != nil {
+		return nil, err
+	}
+	// Serial ports cannot be added to a running virtual machine.
+	r.SetRestart("serial_port")
+	if err := r.SaveDevIDs(device, ctlr); err != nil {
+		return nil, err
+	}
+	dspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+	spec = append(spec, dspec...)
+	log.Printf("[DEBUG] %s: Device config operations from create: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Create finished", r)
+	return spec, nil
+}
+
+// Read reads a vsphere_virtual_machine serial_port sub-resource.
+func (r *SerialPortSubresource) Read(l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] %s: Reading state", r)
+	d, err := r.FindVirtualDevice(l)
+	if err != nil {
+		return fmt.Errorf("cannot find serial port device: %s", err)
+	}
+	device, ok := d.(*types.VirtualSerialPort)
+	if !ok {
+		return fmt.Errorf("device at %q is not a virtual serial port", l.Name(d))
+	}
+	// Reset all backing attributes first, so that only the ones relevant to
+	// the current backing are populated.
+	r.Set("service_uri", "")
+	r.Set("direction", string(types.VirtualDeviceURIBackingOptionDirectionServer))
+	r.Set("proxy_uri", "")
+	r.Set("pipe_name", "")
+	r.Set("pipe_endpoint", string(types.VirtualSerialPortEndPointServer))
+	r.Set("no_rx_loss", false)
+	r.Set("datastore_id", "")
+	r.Set("path", "")
+	r.Set("device_name", "")
+	switch backing := device.Backing.(type) {
+	case *types.VirtualSerialPortURIBackingInfo:
+		r.Set("backing_type", serialPortBackingTypeNetwork)
+		r.Set("service_uri", backing.ServiceURI)
+		r.Set("direction", backing.Direction)
+		r.Set("proxy_uri", backing.ProxyURI)
+	case *types.VirtualSerialPortPipeBackingInfo:
+		r.Set("backing_type", serialPortBackingTypePipe)
+		r.Set("pipe_name", backing.PipeName)
+		r.Set("pipe_endpoint", backing.Endpoint)
+		r.Set("no_rx_loss", structure.DeRef(backing.NoRxLoss))
+	case *types.VirtualSerialPortFileBackingInfo:
+		r.Set("backing_type", serialPortBackingTypeFile)
+		dp := &object.DatastorePath{}
+		if ok := dp.FromString(backing.FileName); !ok {
+			return fmt.Errorf("could not read datastore path in backing %q", backing.FileName)
+		}
+		if backing.Datastore != nil {
+			r.Set("datastore_id", backing.Datastore.Value)
+		}
+		r.Set("path", dp.Path)
+	case *types.VirtualSerialPortDeviceBackingInfo:
+		r.Set("backing_type", serialPortBackingTypeDevice)
+		if backing.UseAutoDetect == nil || !*backing.UseAutoDetect {
+			r.Set("device_name", backing.DeviceName)
+		}
+	default:
+		log.Printf("[DEBUG] %s: Unknown serial port backing type %T, clearing backing type", r, backing)
+		r.Set("backing_type", "")
+	}
+	r.Set("yield_on_poll", device.YieldOnPoll)
+	// Save the device key and address data
+	ctlr, err := findControllerForDevice(l, d)
+	if err != nil {
+		return err
+	}
+	if err := r.SaveDevIDs(d, ctlr); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Read finished (key and device address may have changed)", r)
+	return nil
+}
+
+// Update updates a vsphere_virtual_machine serial_port sub-resource.
+func (r *SerialPortSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning update", r)
+	d, err := r.FindVirtualDevice(l)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find serial port device: %s", err)
+	}
+	device, ok := d.(*types.VirtualSerialPort)
+	if !ok {
+		return nil, fmt.Errorf("device at %q is not a virtual serial port", l.Name(d))
+	}
+	if err := r.expandSerialPort(device); err != nil {
+		return nil, err
+	}
+	// Serial port backings cannot be changed on a running virtual machine.
+	r.SetRestart("serial_port")
+	spec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from update: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Update complete", r)
+	return spec, nil
+}
+
+// Delete deletes a vsphere_virtual_machine serial_port sub-resource.
+func (r *SerialPortSubresource) Delete(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning delete", r)
+	d, err := r.FindVirtualDevice(l)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find serial port device: %s", err)
+	}
+	device, ok := d.(*types.VirtualSerialPort)
+	if !ok {
+		return nil, fmt.Errorf("device at %q is not a virtual serial port", l.Name(d))
+	}
+	r.SetRestart("serial_port")
+	deleteSpec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from delete: %s", r, DeviceChangeString(deleteSpec))
+	log.Printf("[DEBUG] %s: Delete completed", r)
+	return deleteSpec, nil
+}
+
+// expandSerialPort sets the backing and settings of the supplied serial port
+// from the subresource data.
+func (r *SerialPortSubresource) expandSerialPort(device *types.VirtualSerialPort) error {
+	device.YieldOnPoll = r.Get("yield_on_poll").(bool)
+	switch r.Get("backing_type").(string) {
+	case serialPortBackingTypeNetwork:
+		device.Backing = &types.VirtualSerialPortURIBackingInfo{
+			VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+				ServiceURI: r.Get("service_uri").(string),
+				Direction:  r.Get("direction").(string),
+				ProxyURI:   r.Get("proxy_uri").(string),
+			},
+		}
+	case serialPortBackingTypePipe:
+		device.Backing = &types.VirtualSerialPortPipeBackingInfo{
+			VirtualDevicePipeBackingInfo: types.VirtualDevicePipeBackingInfo{
+				PipeName: r.Get("pipe_name").(string),
+			},
+			Endpoint: r.Get("pipe_endpoint").(string),
+			NoRxLoss: structure.BoolPtr(r.Get("no_rx_loss").(bool)),
+		}
+	case serialPortBackingTypeFile:
+		dsID := r.Get("datastore_id").(string)
+		ds, err := datastore.FromID(r.client, dsID)
+		if err != nil {
+			return fmt.Errorf("cannot find datastore: %s", err)
+		}
+		dsProps, err := datastore.Properties(ds)
+		if err != nil {
+			return fmt.Errorf("could not get properties for datastore: %s", err)
+		}
+		dsPath := &object.DatastorePath{
+			Datastore: dsProps.Name,
+			Path:      r.Get("path").(string),
+		}
+		dsRef := ds.Reference()
+		device.Backing = &types.VirtualSerialPortFileBackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+				FileName:  dsPath.String(),
+				Datastore: &dsRef,
+			},
+		}
+	case serialPortBackingTypeDevice:
+		name := r.Get("device_name").(string)
+		device.Backing = &types.VirtualSerialPortDeviceBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+				DeviceName:    name,
+				UseAutoDetect: structure.BoolPtr(name == ""),
+			},
+		}
+	default:
+		return fmt.Errorf("unsupported backing type %q", r.Get("backing_type").(string))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package virtualdevice
+
+import (
+	"testing"
+)
+
+func TestSerialPortValidateDiff(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "network",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypeNetwork,
+				"service_uri":  "telnet://:23000",
+				"proxy_uri":    "telnet://vspc.example.com:13370",
+			},
+		},
+		{
+			name: "network without service_uri",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypeNetwork,
+			},
+			expectErr: true,
+		},
+		{
+			name: "pipe with file fields",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypePipe,
+				"pipe_name":    `\\.\pipe\console`,
+				"path":         "console.log",
+			},
+			expectErr: true,
+		},
+		{
+			name: "file",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypeFile,
+				"datastore_id": "datastore-1",
+				"path":         "vm/console.log",
+			},
+		},
+		{
+			name: "file without datastore_id",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypeFile,
+				"path":         "vm/console.log",
+			},
+			expectErr: true,
+		},
+		{
+			name: "device with auto detection",
+			data: map[string]interface{}{
+				"backing_type": serialPortBackingTypeDevice,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := map[string]interface{}{
+				"key":            0,
+				"device_address": "",
+				"service_uri":    "",
+				"proxy_uri":      "",
+				"pipe_name":      "",
+				"datastore_id":   "",
+				"path":           "",
+				"device_name":    "",
+			}
+			for k, v := range tc.data {
+				data[k] = v
+			}
+			r := NewSerialPortSubresource(nil, nil, data, nil, 0)
+			err := r.ValidateDiff()
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	usbControllerTypeUSB2 = "usb2"
+	usbControllerTypeUSB3 = "usb3"
+)
+
+var usbControllerTypeAllowedValues = []string{
+	usbControllerTypeUSB2,
+	usbControllerTypeUSB3,
+}
+
+// usbControllerSubresourceType describes the usb_controller sub-resource for
+// the generic device operations.
+var usbControllerSubresourceType = &deviceSubresourceType{
+	srtype:     subresourceTypeUSBController,
+	name:       "USB controller",
+	computed:   true,
+	schemaFunc: USBControllerSubresourceSchema,
+	newFunc: func(c *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) deviceSubresource {
+		return NewUSBControllerSubresource(c, rdd, d, old, idx)
+	},
+	selectFunc: func(device types.BaseVirtualDevice) bool {
+		return usbControllerType(device) != ""
+	},
+}
+
+// USBControllerSubresourceSchema represents the schema for the
+// usb_controller sub-resource.
+func USBControllerSubresourceSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The type of USB controller. Can be one of usb2 or usb3. A virtual machine can have at most one controller of each type.",
+			ValidateFunc: validation.StringInSlice(usbControllerTypeAllowedValues, false),
+		},
+		"auto_connect_devices": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Automatically connect new USB devices on the host to this controller.",
+		},
+		"ehci_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Enable the EHCI controller for USB 2.0 devices. Only applies to usb2 controllers.",
+		},
+	}
+	structure.MergeSchema(s, subresourceSchema())
+	return s
+}
+
+// USBControllerSubresource represents a vsphere_virtual_machine
+// usb_controller sub-resource, with a complex device lifecycle.
+type USBControllerSubresource struct {
+	*Subresource
+}
+
+// NewUSBControllerSubresource returns a subresource populated with all of
+// the necessary fields.
+func NewUSBControllerSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *USBControllerSubresource {
+	sr := &USBControllerSubresource{
+		Subresource: &Subresource{
+			schema:  USBControllerSubresourceSchema(),
+			client:  client,
+			srtype:  subresourceTypeUSBController,
+			data:    d,
+			olddata: old,
+			rdd:     rdd,
+		},
+	}
+	sr.Index = idx
+	return sr
+}
+
+// USBControllerApplyOperation processes an apply operation for all USB
+// controllers in the resource.
+//
+// The function takes the root resource's ResourceData, the provider
+// connection, and the device list as known to vSphere at the start of this
+// operation. All USB controller operations are carried out, with both the
+// complete, updated, VirtualDeviceList, and the complete list of changes
+// returned as a slice of BaseVirtualDeviceConfigSpec.
+func USBControllerApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return usbControllerSubresourceType.applyOperation(d, c, l)
+}
+
+// USBControllerRefreshOperation processes a refresh operation for all of the
+// USB controllers in the resource.
+func USBControllerRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
+	return usbControllerSubresourceType.refreshOperation(d, c, l)
+}
+
+// USBControllerPostCloneOperation normalizes USB controllers on a
+// freshly-cloned virtual machine and outputs any necessary device change
+// operations.
+func USBControllerPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return usbControllerSubresourceType.postCloneOperation(d, c, l)
+}
+
+// USBControllerDiffOperation performs operations relevant to managing the
+// diff on usb_controller sub-resources.
+func USBControllerDiffOperation(d *schema.ResourceDiff) error {
+	log.Printf("[DEBUG] USBControllerDiffOperation: Beginning diff validation")
+	o, n := d.GetChange(subresourceTypeUSBController)
+	ods := o.([]interface{})
+	seen := make(map[string]struct{})
+	for i, e := range n.([]interface{}) {
+		t := e.(map[string]interface{})["type"].(string)
+		if _, ok := seen[t]; ok {
+			return fmt.Errorf("%s: duplicate %s controller", subresourceTypeUSBController, t)
+		}
+		seen[t] = struct{}{}
+		if i < len(ods) {
+			if ot := ods[i].(map[string]interface{})["type"].(string); ot != "" && ot != t {
+				return fmt.Errorf("%s.%d: cannot change type from %s to %s, remove the controller and add a new one instead", subresourceTypeUSBController, i, ot, t)
+			}
+		}
+	}
+	log.Printf("[DEBUG] USBControllerDiffOperation: Diff validation complete")
+	return nil
+}
+
+// usbControllerType returns the usb_controller type for the supplied device,
+// or an empty string if it is not a USB controller.
+func usbControllerType(device types.BaseVirtualDevice) string {
+	switch device.(type) {
+	case *types.VirtualUSBController:
+		return usbControllerTypeUSB2
+	case *types.VirtualUSBXHCIController:
+		return usbControllerTypeUSB3
+	}
+	return ""
+}
+
+// findUSBController locates the subresource's USB controller. Devices with a
+// key are found by key. As a virtual machine can only have one controller of
+// each type, freshly created devices are found by their type, as vSphere
+// assigns their unit number.
+func (r *USBControllerSubresource) findUSBController(l object.VirtualDeviceList) (types.BaseVirtualDevice, error) {
+	if key := r.Get("key").(int); key > 0 {
+		return r.FindVirtualDevice(l)
+	}
+	t := r.Get("type").(string)
+	log.Printf("[DEBUG] %s: Looking for %s controller", r, t)
+	for _, device := range l {
+		if usbControllerType(device) == t {
+			return device, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find %s controller", t)
+}
+
+// Create creates a vsphere_virtual_machine usb_controller sub-resource.
+func (r *USBControllerSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Running create", r)
+	var spec []types.BaseVirtualDeviceConfigSpec
+	t := r.Get("type").(string)
+	for _, device := range l {
+		if usbControllerType(device) == t {
+			return nil, fmt.Errorf("virtual machine already has a %s controller: %s", t, l.Name(device))
+		}
+	}
+	ctlr, err := r.ControllerForCreateUpdate(l, SubresourceControllerTypePCI, 0)
+	if err != nil {
+		return nil, err
+	}
+	var device types.BaseVirtualDevice
+	switch t {
+	case usbControllerTypeUSB2:
+		device = &types.VirtualUSBController{
+			AutoConnectDevices: structure.BoolPtr(r.Get("auto_connect_devices").(bool)),
+			EhciEnabled:        structure.BoolPtr(r.Get("ehci_enabled").(bool)),
+		}
+	case usbControllerTypeUSB3:
+		device = &types.VirtualUSBXHCIController{
+			AutoConnectDevices: structure.BoolPtr(r.Get("auto_connect_devices").(bool)),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported USB controller type %q", t)
+	}
+	vd := device.GetVirtualDevice()
+	vd.Key = l.NewKey()
+	vd.ControllerKey = ctlr.GetVirtualController().Key
+	// USB controllers cannot be added to a running virtual machine.
+	r.SetRestart("usb_controller")
+	if err := r.SaveDevIDs(device, ctlr); err != nil {
+		return nil, err
+	}
+	dspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+	spec = append(spec, dspec...)
+	log.Printf("[DEBUG] %s: Device config operations from create: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Create finished", r)
+	return spec, nil
+}
+
+// Read reads a vsphere_virtual_machine usb_controller sub-resource.
+func (r *USBControllerSubresource) Read(l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] %s: Reading state", r)
+	d, err := r.findUSBController(l)
+	if err != nil {
+		return fmt.Errorf("cannot find USB controller: %s", err)
+	}
+	// ehci_enabled only applies to USB 2.0 controllers, so it is kept at its
+	// default for other types.
+	r.Set("ehci_enabled", true)
+	switch device := d.(type) {
+	case *types.VirtualUSBController:
+		r.Set("type", usbControllerTypeUSB2)
+		r.Set("auto_connect_devices", structure.DeRef(device.AutoConnectDevices))
+		r.Set("ehci_enabled", structure.DeRef(device.EhciEnabled))
+	case *types.VirtualUSBXHCIController:
+		r.Set("type", usbControllerTypeUSB3)
+		r.Set("auto_connect_devices", structure.DeRef(device.AutoConnectDevices))
+	default:
+		return fmt.Errorf("device at %q is not a USB controller", l.Name(d))
+	}
+	// Save the device key and address data
+	ctlr, err := findControllerForDevice(l, d)
+	if err != nil {
+		return err
+	}
+	if err := r.SaveDevIDs(d, ctlr); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Read finished (key and device address may have changed)", r)
+	return nil
+}
+
+// Update updates a vsphere_virtual_machine usb_controller sub-resource.
+func (r *USBControllerSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning update", r)
+	if _, err := r.GetWithVeto("type"); err != nil {
+		return nil, err
+	}
+	d, err := r.findUSBController(l)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find USB controller: %s", err)
+	}
+	switch device := d.(type) {
+	case *types.VirtualUSBController:
+		device.AutoConnectDevices = structure.BoolPtr(r.Get("auto_connect_devices").(bool))
+		device.EhciEnabled = structure.BoolPtr(r.GetWithRestart("ehci_enabled").(bool))
+	case *types.VirtualUSBXHCIController:
+		device.AutoConnectDevices = structure.BoolPtr(r.Get("auto_connect_devices").(bool))
+	default:
+		return nil, fmt.Errorf("device at %q is not a USB controller", l.Name(d))
+	}
+	spec, err := object.VirtualDeviceList{d}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from update: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Update complete", r)
+	return spec, nil
+}
+
+// Delete deletes a vsphere_virtual_machine usb_controller sub-resource.
+func (r *USBControllerSubresource) Delete(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning delete", r)
+	d, err := r.findUSBController(l)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find USB controller: %s", err)
+	}
+	r.SetRestart("usb_controller")
+	deleteSpec, err := object.VirtualDeviceList{d}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from delete: %s", r, DeviceChangeString(deleteSpec))
+	log.Printf("[DEBUG] %s: Delete completed", r)
+	return deleteSpec, nil
+}
@@ -0,0 +1,188 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testUSBControllerDeviceList returns a device list with a PCI controller and
+// the supplied devices attached to it.
+func testUSBControllerDeviceList(devices ...types.BaseVirtualDevice) object.VirtualDeviceList {
+	l := object.VirtualDeviceList{
+		&types.VirtualPCIController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 100}}},
+	}
+	for _, device := range devices {
+		device.GetVirtualDevice().ControllerKey = 100
+		l = append(l, device)
+	}
+	return l
+}
+
+func testUSBControllerResourceData(t *testing.T, controllers []interface{}) *schema.ResourceData {
+	s := map[string]*schema.Schema{
+		subresourceTypeUSBController: {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			Elem:     &schema.Resource{Schema: USBControllerSubresourceSchema()},
+		},
+		"reboot_required": {Type: schema.TypeBool, Computed: true},
+	}
+	return schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		subresourceTypeUSBController: controllers,
+	})
+}
+
+func TestUSBControllerCreate(t *testing.T) {
+	usb2 := &types.VirtualUSBController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 7000}}}
+	cases := []struct {
+		name      string
+		ctype     string
+		devices   []types.BaseVirtualDevice
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "usb2",
+			ctype:    usbControllerTypeUSB2,
+			expected: usbControllerTypeUSB2,
+		},
+		{
+			name:     "usb3 next to usb2",
+			ctype:    usbControllerTypeUSB3,
+			devices:  []types.BaseVirtualDevice{usb2},
+			expected: usbControllerTypeUSB3,
+		},
+		{
+			name:      "duplicate usb2",
+			ctype:     usbControllerTypeUSB2,
+			devices:   []types.BaseVirtualDevice{usb2},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := testUSBControllerResourceData(t, nil)
+			data := map[string]interface{}{
+				"type":                 tc.ctype,
+				"auto_connect_devices": true,
+				"ehci_enabled":         false,
+				"key":                  0,
+				"device_address":       "",
+			}
+			r := NewUSBControllerSubresource(nil, d, data, nil, 0)
+			spec, err := r.Create(testUSBControllerDeviceList(tc.devices...))
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(spec) != 1 || spec[0].GetVirtualDeviceConfigSpec().Operation != types.VirtualDeviceConfigSpecOperationAdd {
+				t.Fatalf("expected a single add operation, got %s", DeviceChangeString(spec))
+			}
+			device := spec[0].GetVirtualDeviceConfigSpec().Device
+			if actual := usbControllerType(device); actual != tc.expected {
+				t.Fatalf("expected %s controller, got %q", tc.expected, actual)
+			}
+			if c, ok := device.(*types.VirtualUSBController); ok && (c.EhciEnabled == nil || *c.EhciEnabled) {
+				t.Fatal("expected EHCI to be disabled")
+			}
+			if !d.Get("reboot_required").(bool) {
+				t.Fatal("expected reboot_required to be set")
+			}
+		})
+	}
+}
+
+func TestUSBControllerRead(t *testing.T) {
+	usb3 := &types.VirtualUSBXHCIController{
+		VirtualController:  types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 14000, UnitNumber: new(int32)}},
+		AutoConnectDevices: new(bool),
+	}
+	data := map[string]interface{}{
+		"type":           usbControllerTypeUSB3,
+		"key":            0,
+		"device_address": "",
+	}
+	r := NewUSBControllerSubresource(nil, nil, data, nil, 0)
+	if err := r.Read(testUSBControllerDeviceList(usb3)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"type":                 usbControllerTypeUSB3,
+		"auto_connect_devices": false,
+		"ehci_enabled":         true,
+		"key":                  14000,
+		"device_address":       "pci:0:0",
+	}
+	for k, v := range expected {
+		if r.Get(k) != v {
+			t.Fatalf("expected %s to be %v, got %v", k, v, r.Get(k))
+		}
+	}
+}
+
+func TestUSBControllerPostCloneOperation(t *testing.T) {
+	source := func() object.VirtualDeviceList {
+		return testUSBControllerDeviceList(&types.VirtualUSBController{
+			VirtualController:  types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 7000, UnitNumber: new(int32)}},
+			AutoConnectDevices: new(bool),
+			EhciEnabled:        new(bool),
+		})
+	}
+	cases := []struct {
+		name        string
+		controllers []interface{}
+		expected    []types.VirtualDeviceConfigSpecOperation
+		state       int
+	}{
+		{
+			name:  "none configured keeps source controller",
+			state: 1,
+		},
+		{
+			name: "source controller kept",
+			controllers: []interface{}{
+				map[string]interface{}{"type": usbControllerTypeUSB2, "auto_connect_devices": false, "ehci_enabled": false},
+			},
+			state: 1,
+		},
+		{
+			name: "source controller updated",
+			controllers: []interface{}{
+				map[string]interface{}{"type": usbControllerTypeUSB2},
+			},
+			expected: []types.VirtualDeviceConfigSpecOperation{types.VirtualDeviceConfigSpecOperationEdit},
+			state:    1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := testUSBControllerResourceData(t, tc.controllers)
+			_, spec, err := USBControllerPostCloneOperation(d, nil, source())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(spec) != len(tc.expected) {
+				t.Fatalf("expected %d operations, got %s", len(tc.expected), DeviceChangeString(spec))
+			}
+			for i, op := range tc.expected {
+				if actual := spec[i].GetVirtualDeviceConfigSpec().Operation; actual != op {
+					t.Fatalf("expected operation %d to be %s, got %s", i, op, actual)
+				}
+			}
+			if actual := len(d.Get(subresourceTypeUSBController).([]interface{})); actual != tc.state {
+				t.Fatalf("expected %d controllers in state, got %d", tc.state, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var videoCardRenderer3DAllowedValues = []string{
+	string(types.VirtualMachineVideoCardUse3dRendererAutomatic),
+	string(types.VirtualMachineVideoCardUse3dRendererSoftware),
+	string(types.VirtualMachineVideoCardUse3dRendererHardware),
+}
+
+// videoCardSubresourceType describes the video_card sub-resource for the
+// generic device operations.
+var videoCardSubresourceType = &deviceSubresourceType{
+	srtype:     subresourceTypeVideoCard,
+	name:       "Video card",
+	computed:   true,
+	schemaFunc: VideoCardSubresourceSchema,
+	newFunc: func(c *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) deviceSubresource {
+		return NewVideoCardSubresource(c, rdd, d, old, idx)
+	},
+	selectFunc: func(device types.BaseVirtualDevice) bool {
+		_, ok := device.(*types.VirtualMachineVideoCard)
+		return ok
+	},
+}
+
+// VideoCardSubresourceSchema represents the schema for the video_card
+// sub-resource.
+func VideoCardSubresourceSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"video_ram_size_kb": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The amount of video RAM, in KB.",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"num_displays": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The number of displays.",
+			ValidateFunc: validation.IntBetween(1, 10),
+		},
+		"auto_detect": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Automatically detect video RAM settings from the number of displays and resolution.",
+		},
+		"enable_3d_support": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Enable 3D support.",
+		},
+		"renderer_3d": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The 3D renderer. Can be one of automatic, software, or hardware.",
+			ValidateFunc: validation.StringInSlice(videoCardRenderer3DAllowedValues, false),
+		},
+		"graphics_memory_size_kb": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The amount of graphics memory for 3D support, in KB.",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+	}
+	structure.MergeSchema(s, subresourceSchema())
+	return s
+}
+
+// VideoCardSubresource represents a vsphere_virtual_machine video_card
+// sub-resource, with a complex device lifecycle.
+type VideoCardSubresource struct {
+	*Subresource
+}
+
+// NewVideoCardSubresource returns a subresource populated with all of the
+// necessary fields.
+func NewVideoCardSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *VideoCardSubresource {
+	sr := &VideoCardSubresource{
+		Subresource: &Subresource{
+			schema:  VideoCardSubresourceSchema(),
+			client:  client,
+			srtype:  subresourceTypeVideoCard,
+			data:    d,
+			olddata: old,
+			rdd:     rdd,
+		},
+	}
+	sr.Index = idx
+	return sr
+}
+
+// VideoCardApplyOperation processes an apply operation for the video card in
+// the resource.
+//
+// The function takes the root resource's ResourceData, the provider
+// connection, and the device list as known to vSphere at the start of this
+// operation. All video card operations are carried out, with both the
+// complete, updated, VirtualDeviceList, and the complete list of changes
+// returned as a slice of BaseVirtualDeviceConfigSpec.
+func VideoCardApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return videoCardSubresourceType.applyOperation(d, c, l)
+}
+
+// VideoCardRefreshOperation processes a refresh operation for the video card
+// in the resource.
+func VideoCardRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
+	return videoCardSubresourceType.refreshOperation(d, c, l)
+}
+
+// VideoCardPostCloneOperation normalizes the video card on a freshly-cloned
+// virtual machine and outputs any necessary device change operations.
+func VideoCardPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	return videoCardSubresourceType.postCloneOperation(d, c, l)
+}
+
+// findVideoCard locates the virtual machine's video card. A virtual machine
+// only ever has one, so devices without a known key are looked up by type.
+func (r *VideoCardSubresource) findVideoCard(l object.VirtualDeviceList) (*types.VirtualMachineVideoCard, error) {
+	if key := r.Get("key").(int); key > 0 {
+		device, err := r.FindVirtualDevice(l)
+		if err != nil {
+			return nil, err
+		}
+		card, ok := device.(*types.VirtualMachineVideoCard)
+		if !ok {
+			return nil, fmt.Errorf("device at %q is not a video card", l.Name(device))
+		}
+		return card, nil
+	}
+	cards := l.SelectByType((*types.VirtualMachineVideoCard)(nil))
+	if len(cards) < 1 {
+		return nil, fmt.Errorf("could not find video card")
+	}
+	return cards[len(cards)-1].(*types.VirtualMachineVideoCard), nil
+}
+
+// Create creates a vsphere_virtual_machine video_card sub-resource.
+//
+// Every virtual machine has exactly one video card. When the virtual machine
+// is being created, the card is added in place of the default one, otherwise
+// the existing card is configured.
+func (r *VideoCardSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Running create", r)
+	var spec []types.BaseVirtualDeviceConfigSpec
+	op := types.VirtualDeviceConfigSpecOperationEdit
+	card, err := r.findVideoCard(l)
+	if err != nil || r.rdd.Id() == "" {
+		op = types.VirtualDeviceConfigSpecOperationAdd
+		ctlr, err := r.ControllerForCreateUpdate(l, SubresourceControllerTypePCI, 0)
+		if err != nil {
+			return nil, err
+		}
+		card = &types.VirtualMachineVideoCard{}
+		card.Key = l.NewKey()
+		card.ControllerKey = ctlr.GetVirtualController().Key
+	} else {
+		r.SetRestart("video_card")
+	}
+	r.expandVideoCard(card)
+	ctlr, err := findControllerForDevice(l, card)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.SaveDevIDs(card, ctlr); err != nil {
+		return nil, err
+	}
+	dspec, err := object.VirtualDeviceList{card}.ConfigSpec(op)
+	if err != nil {
+		return nil, err
+	}
+	spec = append(spec, dspec...)
+	log.Printf("[DEBUG] %s: Device config operations from create: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Create finished", r)
+	return spec, nil
+}
+
+// Read reads a vsphere_virtual_machine video_card sub-resource.
+func (r *VideoCardSubresource) Read(l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] %s: Reading state", r)
+	card, err := r.findVideoCard(l)
+	if err != nil {
+		return fmt.Errorf("cannot find video card: %s", err)
+	}
+	r.Set("video_ram_size_kb", card.VideoRamSizeInKB)
+	r.Set("num_displays", card.NumDisplays)
+	r.Set("auto_detect", structure.DeRef(card.UseAutoDetect))
+	r.Set("enable_3d_support", structure.DeRef(card.Enable3DSupport))
+	r.Set("renderer_3d", card.Use3dRenderer)
+	r.Set("graphics_memory_size_kb", card.GraphicsMemorySizeInKB)
+	// Save the device key and address data
+	ctlr, err := findControllerForDevice(l, card)
+	if err != nil {
+		return err
+	}
+	if err := r.SaveDevIDs(card, ctlr); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Read finished (key and device address may have changed)", r)
+	return nil
+}
+
+// Update updates a vsphere_virtual_machine video_card sub-resource.
+func (r *VideoCardSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning update", r)
+	card, err := r.findVideoCard(l)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find video card: %s", err)
+	}
+	// Video card settings can only be changed while the virtual machine is
+	// powered off.
+	r.SetRestart("video_card")
+	r.expandVideoCard(card)
+	spec, err := object.VirtualDeviceList{card}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from update: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Update complete", r)
+	return spec, nil
+}
+
+// Delete is a no-op for the video_card sub-resource. A virtual machine always
+// has a video card, so removing it from configuration leaves the device as it
+// is.
+func (r *VideoCardSubresource) Delete(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Video cards cannot be removed, leaving device in place", r)
+	return nil, nil
+}
+
+// expandVideoCard reads the sub-resource data into the supplied video card.
+// Unset values are left as they are so that vSphere defaults apply. As unset
+// booleans read as false, these are only written when they differ from the
+// card, which on an existing card holds the value read into state.
+func (r *VideoCardSubresource) expandVideoCard(card *types.VirtualMachineVideoCard) {
+	if v := r.Get("video_ram_size_kb").(int); v > 0 {
+		card.VideoRamSizeInKB = int64(v)
+	}
+	if v := r.Get("num_displays").(int); v > 0 {
+		card.NumDisplays = int32(v)
+	}
+	if v := r.Get("auto_detect").(bool); v != (card.UseAutoDetect != nil && *card.UseAutoDetect) {
+		card.UseAutoDetect = structure.BoolPtr(v)
+	}
+	if v := r.Get("enable_3d_support").(bool); v != (card.Enable3DSupport != nil && *card.Enable3DSupport) {
+		card.Enable3DSupport = structure.BoolPtr(v)
+	}
+	if v := r.Get("renderer_3d").(string); v != "" {
+		card.Use3dRenderer = v
+	}
+	if v := r.Get("graphics_memory_size_kb").(int); v > 0 {
+		card.GraphicsMemorySizeInKB = int64(v)
+	}
+}
@@ -0,0 +1,183 @@
+package virtualdevice
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testVideoCardData returns the data for a video_card sub-resource, with any
+// values not in data set to their zero values.
+func testVideoCardData(data map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{
+		"video_ram_size_kb":       0,
+		"num_displays":            0,
+		"auto_detect":             false,
+		"enable_3d_support":       false,
+		"renderer_3d":             "",
+		"graphics_memory_size_kb": 0,
+		"key":                     0,
+		"device_address":          "",
+	}
+	for k, v := range data {
+		m[k] = v
+	}
+	return m
+}
+
+func TestExpandVideoCard(t *testing.T) {
+	cases := []struct {
+		name     string
+		card     types.VirtualMachineVideoCard
+		data     map[string]interface{}
+		expected types.VirtualMachineVideoCard
+	}{
+		{
+			name:     "new card keeps defaults",
+			data:     map[string]interface{}{"num_displays": 2},
+			expected: types.VirtualMachineVideoCard{NumDisplays: 2},
+		},
+		{
+			name: "existing settings kept",
+			card: types.VirtualMachineVideoCard{
+				VideoRamSizeInKB: 4096,
+				NumDisplays:      1,
+				UseAutoDetect:    types.NewBool(true),
+				Enable3DSupport:  types.NewBool(true),
+				Use3dRenderer:    "automatic",
+			},
+			data: map[string]interface{}{
+				"num_displays":      2,
+				"auto_detect":       true,
+				"enable_3d_support": true,
+			},
+			expected: types.VirtualMachineVideoCard{
+				VideoRamSizeInKB: 4096,
+				NumDisplays:      2,
+				UseAutoDetect:    types.NewBool(true),
+				Enable3DSupport:  types.NewBool(true),
+				Use3dRenderer:    "automatic",
+			},
+		},
+		{
+			name: "settings disabled",
+			card: types.VirtualMachineVideoCard{
+				UseAutoDetect:   types.NewBool(true),
+				Enable3DSupport: types.NewBool(true),
+			},
+			data: map[string]interface{}{},
+			expected: types.VirtualMachineVideoCard{
+				UseAutoDetect:   types.NewBool(false),
+				Enable3DSupport: types.NewBool(false),
+			},
+		},
+		{
+			name: "settings enabled",
+			data: map[string]interface{}{
+				"auto_detect":             true,
+				"enable_3d_support":       true,
+				"renderer_3d":             "hardware",
+				"graphics_memory_size_kb": 262144,
+			},
+			expected: types.VirtualMachineVideoCard{
+				UseAutoDetect:          types.NewBool(true),
+				Enable3DSupport:        types.NewBool(true),
+				Use3dRenderer:          "hardware",
+				GraphicsMemorySizeInKB: 262144,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewVideoCardSubresource(nil, nil, testVideoCardData(tc.data), nil, 0)
+			card := tc.card
+			r.expandVideoCard(&card)
+			if !reflect.DeepEqual(tc.expected, card) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, card)
+			}
+		})
+	}
+}
+
+func TestVideoCardPostCloneOperation(t *testing.T) {
+	s := map[string]*schema.Schema{
+		subresourceTypeVideoCard: {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem:     &schema.Resource{Schema: VideoCardSubresourceSchema()},
+		},
+		"reboot_required": {Type: schema.TypeBool, Computed: true},
+	}
+	source := func() object.VirtualDeviceList {
+		return object.VirtualDeviceList{
+			&types.VirtualPCIController{VirtualController: types.VirtualController{VirtualDevice: types.VirtualDevice{Key: 100}}},
+			&types.VirtualMachineVideoCard{
+				VirtualDevice:    types.VirtualDevice{Key: 500, ControllerKey: 100, UnitNumber: new(int32)},
+				VideoRamSizeInKB: 4096,
+				NumDisplays:      1,
+				UseAutoDetect:    types.NewBool(true),
+				Enable3DSupport:  types.NewBool(true),
+				Use3dRenderer:    "automatic",
+			},
+		}
+	}
+	cases := []struct {
+		name       string
+		cards      []interface{}
+		operations int
+		autoDetect bool
+		enable3D   bool
+	}{
+		{
+			name:       "none configured",
+			autoDetect: true,
+			enable3D:   true,
+		},
+		{
+			name: "unset settings kept from source",
+			cards: []interface{}{
+				map[string]interface{}{"num_displays": 2},
+			},
+			operations: 1,
+			autoDetect: true,
+			enable3D:   true,
+		},
+		{
+			name: "settings disabled",
+			cards: []interface{}{
+				map[string]interface{}{"auto_detect": false, "enable_3d_support": false},
+			},
+			operations: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				subresourceTypeVideoCard: tc.cards,
+			})
+			_, spec, err := VideoCardPostCloneOperation(d, nil, source())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(spec) != tc.operations {
+				t.Fatalf("expected %d operations, got %s", tc.operations, DeviceChangeString(spec))
+			}
+			for _, dspec := range spec {
+				card := dspec.GetVirtualDeviceConfigSpec().Device.(*types.VirtualMachineVideoCard)
+				if *card.UseAutoDetect != tc.autoDetect || *card.Enable3DSupport != tc.enable3D {
+					t.Fatalf("expected auto_detect %t and 3D support %t, got %t and %t", tc.autoDetect, tc.enable3D, *card.UseAutoDetect, *card.Enable3DSupport)
+				}
+			}
+			if actual := d.Get("video_card.0.auto_detect").(bool); actual != tc.autoDetect {
+				t.Fatalf("expected auto_detect %t in state, got %t", tc.autoDetect, actual)
+			}
+		})
+	}
+}
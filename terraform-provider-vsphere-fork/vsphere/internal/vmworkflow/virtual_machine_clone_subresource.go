@@ -1,11 +1,14 @@
 package vmworkflow
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customizationspec"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
@@ -30,9 +33,34 @@ func VirtualMachineCloneSchema() map[string]*schema.Schema {
 			Description: "The UUID of the source virtual machine or template.",
 		},
 		"linked_clone": {
-			Type:        schema.TypeBool,
+			Type:          schema.TypeBool,
+			Optional:      true,
+			ConflictsWith: []string{"clone.0.instant_clone"},
+			Description:   "Whether or not to create a linked clone when cloning. When this option is used, the source VM must have a single snapshot associated with it.",
+		},
+		"snapshot_name": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"clone.0.snapshot_id", "clone.0.instant_clone"},
+			Description:   "The name of the snapshot of the source virtual machine to use as the base of the clone. The name must be unique within the snapshot tree of the source.",
+		},
+		"snapshot_id": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ConflictsWith: []string{"clone.0.snapshot_name", "clone.0.instant_clone"},
+			Description:   "The managed object ID of the snapshot of the source virtual machine to use as the base of the clone.",
+		},
+		"instant_clone": {
+			Type:          schema.TypeBool,
+			Optional:      true,
+			ConflictsWith: []string{"clone.0.linked_clone", "clone.0.customize", "clone.0.customization_spec", "clone.0.snapshot_name", "clone.0.snapshot_id"},
+			Description:   "Whether or not to create an instant clone of a running source virtual machine. The new virtual machine shares the memory and disk state of the source and is powered on when the clone completes. As the clone is running, CPU and memory can only differ from the source if hot add is enabled on the source, and other device changes must be ones that can be hot plugged.",
+		},
+		"instant_clone_guestinfo": {
+			Type:        schema.TypeMap,
 			Optional:    true,
-			Description: "Whether or not to create a linked clone when cloning. When this option is used, the source VM must have a single snapshot associated with it.",
+			Description: "A map of guestinfo keys and values passed to the instant clone, used to give the new virtual machine its network identity. Keys are prefixed with guestinfo. if not already.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
 		"timeout": {
 			Type:         schema.TypeInt,
@@ -42,11 +70,32 @@ func VirtualMachineCloneSchema() map[string]*schema.Schema {
 			ValidateFunc: validation.IntAtLeast(10),
 		},
 		"customize": {
-			Type:        schema.TypeList,
-			Optional:    true,
-			MaxItems:    1,
-			Description: "The customization spec for this clone. This allows the user to configure the virtual machine post-clone.",
-			Elem:        &schema.Resource{Schema: VirtualMachineCustomizeSchema()},
+			Type:          schema.TypeList,
+			Optional:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"clone.0.customization_spec", "clone.0.instant_clone"},
+			Description:   "The customization spec for this clone. This allows the user to configure the virtual machine post-clone.",
+			Elem:          &schema.Resource{Schema: VirtualMachineCustomizeSchema()},
+		},
+		"customization_spec": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"clone.0.customize", "clone.0.instant_clone"},
+			Description:   "A reference to a customization specification stored in vCenter, to be used in place of an inline customize block.",
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The name of the stored customization specification.",
+				},
+				"timeout": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     10,
+					Description: "The amount of time, in minutes, to wait for guest OS customization to complete before returning with an error. Setting this value to 0 or a negative value skips the waiter.",
+				},
+			}},
 		},
 		"ovf_network_map": {
 			Type:        schema.TypeMap,
@@ -87,11 +136,47 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 		if eGuestID != aGuestID {
 			return fmt.Errorf("invalid guest ID %q for clone. Please set it to %q", aGuestID, eGuestID)
 		}
+		// If instant clone is enabled, the source must be a running virtual
+		// machine. Disks are shared with the source in the same fashion as a
+		// linked clone, so they are validated the same way.
+		instant := d.Get("clone.0.instant_clone").(bool)
+		if instant {
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: Checking %s for instant clone eligibility", tUUID)
+			if err := validateInstantCloneSource(vprops); err != nil {
+				return err
+			}
+			if err := validateInstantCloneHardware(d, vprops); err != nil {
+				return err
+			}
+		}
+		// If a specific snapshot was requested, check to see that it exists in
+		// the snapshot tree of the source. Disks are validated against the
+		// configuration of the snapshot rather than the current configuration.
+		devices := vprops.Config.Hardware.Device
+		var snapshot *types.ManagedObjectReference
+		if d.NewValueKnown("clone.0.snapshot_name") && d.NewValueKnown("clone.0.snapshot_id") {
+			snapshot, err = findCloneSnapshot(d, vprops)
+			if err != nil {
+				return err
+			}
+			if snapshot != nil {
+				sprops, err := virtualmachine.SnapshotProperties(vm, *snapshot)
+				if err != nil {
+					return fmt.Errorf("error fetching snapshot properties: %s", err)
+				}
+				devices = sprops.Config.Hardware.Device
+			}
+		} else {
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: Snapshot name or ID is not available. Skipping snapshot validation.")
+		}
 		// If linked clone is enabled, check to see if we have a snapshot. There need
-		// to be a single snapshot on the template for it to be eligible.
+		// to be a single snapshot on the template for it to be eligible, unless a
+		// specific snapshot has been requested.
 		linked := d.Get("clone.0.linked_clone").(bool)
 		if linked {
-			if vprops.Config.Template {
+			if snapshot != nil {
+				log.Printf("[DEBUG] ValidateVirtualMachineClone: Snapshot %s on %s satisfies linked clone eligibility", snapshot.Value, tUUID)
+			} else if vprops.Config.Template {
 				log.Printf("[DEBUG] ValidateVirtualMachineClone: Virtual machine %s is marked as a template and satisfies linked clone eligibility", tUUID)
 			} else {
 				log.Printf("[DEBUG] ValidateVirtualMachineClone: Checking snapshots on %s for linked clone eligibility", tUUID)
@@ -103,8 +188,8 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 		// Check to make sure the disks for this VM/template line up with the disks
 		// in the configuration. This is in the virtual device package, so pass off
 		// to that now.
-		l := object.VirtualDeviceList(vprops.Config.Hardware.Device)
-		if err := virtualdevice.DiskCloneValidateOperation(d, c, l, linked); err != nil {
+		l := object.VirtualDeviceList(devices)
+		if err := virtualdevice.DiskCloneValidateOperation(d, c, l, linked || instant); err != nil {
 			return err
 		}
 		vconfig := vprops.Config.VAppConfig
@@ -118,8 +203,18 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 		log.Printf("[DEBUG] ValidateVirtualMachineClone: template_uuid is not available. Skipping template validation.")
 	}
 
+	if d.Get("clone.0.instant_clone").(bool) {
+		if _, ok := d.GetOk("datastore_cluster_id"); ok {
+			return errors.New("datastore_cluster_id cannot be used with instant_clone")
+		}
+	} else if len(d.Get("clone.0.instant_clone_guestinfo").(map[string]interface{})) > 0 {
+		return errors.New("instant_clone_guestinfo can only be used when instant_clone is enabled")
+	}
+
 	// If a customization spec was defined, we need to check some items in it as well.
-	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
+	customize := len(d.Get("clone.0.customize").([]interface{})) > 0
+	storedSpec := len(d.Get("clone.0.customization_spec").([]interface{})) > 0
+	if customize || storedSpec {
 		if poolID, ok := d.GetOk("resource_pool_id"); ok {
 			pool, err := resourcepool.FromID(c, poolID.(string))
 			if err != nil {
@@ -129,8 +224,15 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 			if err != nil {
 				return fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
 			}
-			if err := ValidateCustomizationSpec(d, family); err != nil {
-				return err
+			if customize {
+				if err := ValidateCustomizationSpec(d, family); err != nil {
+					return err
+				}
+			}
+			if storedSpec {
+				if err := validateStoredCustomizationSpec(d, c, family); err != nil {
+					return err
+				}
 			}
 		} else {
 			log.Printf("[DEBUG] ValidateVirtualMachineClone: resource_pool_id is not available. Skipping OS family check.")
@@ -140,6 +242,101 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 	return nil
 }
 
+// CloneDeltaDiskCount returns the number of disks that a linked or instant
+// clone creates as delta disks of its source, which are the disks of the
+// source VM or of the requested snapshot. Zero is returned for full clones.
+func CloneDeltaDiskCount(d *schema.ResourceDiff, c *govmomi.Client) (int, error) {
+	if !d.Get("clone.0.linked_clone").(bool) && !d.Get("clone.0.instant_clone").(bool) {
+		return 0, nil
+	}
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	vm, err := virtualmachine.FromUUID(c, tUUID)
+	if err != nil {
+		return 0, fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching virtual machine or template properties: %s", err)
+	}
+	devices := vprops.Config.Hardware.Device
+	snapshot, err := findCloneSnapshot(d, vprops)
+	if err != nil {
+		return 0, err
+	}
+	if snapshot != nil {
+		sprops, err := virtualmachine.SnapshotProperties(vm, *snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("error fetching snapshot properties: %s", err)
+		}
+		devices = sprops.Config.Hardware.Device
+	}
+	disks := virtualdevice.SelectDisks(object.VirtualDeviceList(devices), d.Get("scsi_controller_count").(int), d.Get("sata_controller_count").(int), d.Get("ide_controller_count").(int))
+	return len(disks), nil
+}
+
+// validateStoredCustomizationSpec checks that the customization specification
+// referenced in clone.0.customization_spec exists and is of the correct type
+// for the guest OS family. If the name is not known yet, or the spec does not
+// exist yet because it is created in the same apply, validation is skipped.
+func validateStoredCustomizationSpec(d *schema.ResourceDiff, c *govmomi.Client, family string) error {
+	if !d.NewValueKnown("clone.0.customization_spec.0.name") {
+		log.Printf("[DEBUG] ValidateVirtualMachineClone: customization spec name is not available. Skipping spec validation.")
+		return nil
+	}
+	name := d.Get("clone.0.customization_spec.0.name").(string)
+	item, err := customizationspec.FromName(c, name)
+	if err != nil {
+		if customizationspec.IsNotFoundError(err) {
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: customization spec %q does not exist yet. Skipping spec validation.", name)
+			return nil
+		}
+		return fmt.Errorf("cannot locate customization specification %q: %s", name, err)
+	}
+	if customizationspec.OSFamily(item.Info.Type) != family {
+		return fmt.Errorf("customization specification %q is of type %s and cannot be used with guest ID %q", name, item.Info.Type, d.Get("guest_id").(string))
+	}
+	return nil
+}
+
+// findCloneSnapshot looks up the snapshot requested in clone.0.snapshot_name
+// or clone.0.snapshot_id in the snapshot tree of the source VM. nil is
+// returned if neither option is set.
+func findCloneSnapshot(d interface{ Get(string) interface{} }, props *mo.VirtualMachine) (*types.ManagedObjectReference, error) {
+	name := d.Get("clone.0.snapshot_name").(string)
+	id := d.Get("clone.0.snapshot_id").(string)
+	if name == "" && id == "" {
+		return nil, nil
+	}
+	var tree []types.VirtualMachineSnapshotTree
+	if props.Snapshot != nil {
+		tree = props.Snapshot.RootSnapshotList
+	}
+	var matches []types.ManagedObjectReference
+	walkCloneSnapshotTree(tree, func(node types.VirtualMachineSnapshotTree) {
+		if (name != "" && node.Name == name) || (id != "" && node.Snapshot.Value == id) {
+			matches = append(matches, node.Snapshot)
+		}
+	})
+	switch {
+	case len(matches) < 1 && name != "":
+		return nil, fmt.Errorf("snapshot %q not found on virtual machine %s", name, props.Config.Uuid)
+	case len(matches) < 1:
+		return nil, fmt.Errorf("snapshot ID %q not found on virtual machine %s", id, props.Config.Uuid)
+	case len(matches) > 1:
+		return nil, fmt.Errorf("snapshot name %q is ambiguous on virtual machine %s (%d matches), use snapshot_id instead", name, props.Config.Uuid, len(matches))
+	}
+	log.Printf("[DEBUG] findCloneSnapshot: Using snapshot %s on virtual machine %s", matches[0].Value, props.Config.Uuid)
+	return &matches[0], nil
+}
+
+// walkCloneSnapshotTree calls f for every node in a snapshot tree.
+func walkCloneSnapshotTree(tree []types.VirtualMachineSnapshotTree, f func(types.VirtualMachineSnapshotTree)) {
+	for _, node := range tree {
+		f(node)
+		walkCloneSnapshotTree(node.ChildSnapshotList, f)
+	}
+}
+
 // validateCloneSnapshots checks a VM to make sure it has a single snapshot
 // with no children, to make sure there is no ambiguity when selecting a
 // snapshot for linked clones.
@@ -165,6 +362,62 @@ func validateCloneSnapshots(props *mo.VirtualMachine) error {
 	return nil
 }
 
+// validateInstantCloneSource checks a VM to make sure it can be used as the
+// source of an instant clone. The source must be a running virtual machine,
+// not a template.
+func validateInstantCloneSource(props *mo.VirtualMachine) error {
+	if props.Config.Template {
+		return fmt.Errorf("virtual machine %s is a template and cannot be used as the source of an instant clone", props.Config.Uuid)
+	}
+	if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		return fmt.Errorf("virtual machine %s must be powered on to be used as the source of an instant clone (current state: %s)", props.Config.Uuid, props.Runtime.PowerState)
+	}
+	return nil
+}
+
+// validateInstantCloneHardware checks that the CPU and memory configuration
+// of an instant clone can be applied to it while it is running. An instant
+// clone is powered on as soon as it is created, so CPU and memory can only
+// differ from the source where the source has hot add (or hot remove)
+// enabled, and the hot plug settings and cores per socket, which need a power
+// cycle to change, must match the source.
+func validateInstantCloneHardware(d interface{ Get(string) interface{} }, props *mo.VirtualMachine) error {
+	hw := props.Config.Hardware
+	cpuHotAdd := props.Config.CpuHotAddEnabled != nil && *props.Config.CpuHotAddEnabled
+	cpuHotRemove := props.Config.CpuHotRemoveEnabled != nil && *props.Config.CpuHotRemoveEnabled
+	memoryHotAdd := props.Config.MemoryHotAddEnabled != nil && *props.Config.MemoryHotAddEnabled
+	for _, hp := range []struct {
+		key   string
+		value bool
+	}{
+		{key: "cpu_hot_add_enabled", value: cpuHotAdd},
+		{key: "cpu_hot_remove_enabled", value: cpuHotRemove},
+		{key: "memory_hot_add_enabled", value: memoryHotAdd},
+	} {
+		if d.Get(hp.key).(bool) != hp.value {
+			return fmt.Errorf("%s must match the source virtual machine (%t) when using instant_clone", hp.key, hp.value)
+		}
+	}
+	if cores := d.Get("num_cores_per_socket").(int); cores != int(hw.NumCoresPerSocket) {
+		return fmt.Errorf("num_cores_per_socket (%d) must match the source virtual machine (%d) when using instant_clone", cores, hw.NumCoresPerSocket)
+	}
+	cpus := d.Get("num_cpus").(int)
+	switch {
+	case cpus > int(hw.NumCPU) && !cpuHotAdd:
+		return fmt.Errorf("num_cpus (%d) cannot be higher than the source virtual machine (%d) when using instant_clone without cpu_hot_add_enabled", cpus, hw.NumCPU)
+	case cpus < int(hw.NumCPU) && !cpuHotRemove:
+		return fmt.Errorf("num_cpus (%d) cannot be lower than the source virtual machine (%d) when using instant_clone without cpu_hot_remove_enabled", cpus, hw.NumCPU)
+	}
+	memory := d.Get("memory").(int)
+	switch {
+	case memory > int(hw.MemoryMB) && !memoryHotAdd:
+		return fmt.Errorf("memory (%d) cannot be higher than the source virtual machine (%d) when using instant_clone without memory_hot_add_enabled", memory, hw.MemoryMB)
+	case memory < int(hw.MemoryMB):
+		return fmt.Errorf("memory (%d) cannot be lower than the source virtual machine (%d) when using instant_clone", memory, hw.MemoryMB)
+	}
+	return nil
+}
+
 // ExpandVirtualMachineCloneSpec creates a clone spec for an existing virtual machine.
 //
 // The clone spec built by this function for the clone contains the target
@@ -195,6 +448,23 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 	if err != nil {
 		return spec, nil, fmt.Errorf("error fetching virtual machine or template properties: %s", err)
 	}
+	// If a specific snapshot was requested, use it as the base of the clone,
+	// for both full and linked clones. Disk relocation is computed against the
+	// configuration of the snapshot.
+	devices := vprops.Config.Hardware.Device
+	snapshot, err := findCloneSnapshot(d, vprops)
+	if err != nil {
+		return spec, nil, err
+	}
+	if snapshot != nil {
+		sprops, err := virtualmachine.SnapshotProperties(vm, *snapshot)
+		if err != nil {
+			return spec, nil, fmt.Errorf("error fetching snapshot properties: %s", err)
+		}
+		devices = sprops.Config.Hardware.Device
+		spec.Snapshot = snapshot
+		log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Using requested snapshot for clone: %s", snapshot.Value)
+	}
 	// If we are creating a linked clone, grab the current snapshot of the
 	// source, and populate the appropriate field. This should have already been
 	// validated, but just in case, validate it again here.
@@ -202,9 +472,13 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 		log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Clone type is a linked clone")
 		log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Fetching snapshot for VM/template UUID %s", tUUID)
 
-		// If our properties tell us that the Template flag is set, then we need to use a
-		// different option to clone the disk so that way vSphere knows the disk is shared.
-		if vprops.Config.Template {
+		// If a snapshot was requested, then child disks are created off of it.
+		// Otherwise, if our properties tell us that the Template flag is set, then
+		// we need to use a different option to clone the disk so that way vSphere
+		// knows the disk is shared.
+		if snapshot != nil {
+			spec.Location.DiskMoveType = string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking)
+		} else if vprops.Config.Template {
 			log.Printf("[DEBUG] Virtual machine %s was marked as a template", tUUID)
 			spec.Location.DiskMoveType = string(types.VirtualMachineRelocateDiskMoveOptionsMoveAllDiskBackingsAndAllowSharing)
 
@@ -249,7 +523,7 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 	}
 
 	// Grab the relocate spec for the disks.
-	l := object.VirtualDeviceList(vprops.Config.Hardware.Device)
+	l := object.VirtualDeviceList(devices)
 	relocators, err := virtualdevice.DiskCloneRelocateOperation(d, c, l)
 	if err != nil {
 		return spec, nil, err
@@ -258,3 +532,74 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 	log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Clone spec prep complete")
 	return spec, vm, nil
 }
+
+// ExpandVirtualMachineInstantCloneSpec creates an instant clone spec for an
+// existing, running virtual machine.
+//
+// The spec contains the placement of the new virtual machine and the
+// guestinfo configuration supplied in instant_clone_guestinfo. Disk and device
+// configuration is inherited from the source and is normalized post-clone.
+func ExpandVirtualMachineInstantCloneSpec(d *schema.ResourceData, c *govmomi.Client, fo *object.Folder) (types.VirtualMachineInstantCloneSpec, *object.VirtualMachine, error) {
+	spec := types.VirtualMachineInstantCloneSpec{
+		Name: d.Get("name").(string),
+	}
+	log.Printf("[DEBUG] ExpandVirtualMachineInstantCloneSpec: Preparing instant clone spec for VM")
+
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	log.Printf("[DEBUG] ExpandVirtualMachineInstantCloneSpec: Cloning from UUID: %s", tUUID)
+	vm, err := virtualmachine.FromUUID(c, tUUID)
+	if err != nil {
+		return spec, nil, fmt.Errorf("cannot locate virtual machine with UUID %q: %s", tUUID, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return spec, nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	// The source should have already been validated, but the power state may
+	// have changed since, so validate it again here.
+	if err := validateInstantCloneSource(vprops); err != nil {
+		return spec, nil, err
+	}
+
+	if dsID, ok := d.GetOk("datastore_id"); ok {
+		ds, err := datastore.FromID(c, dsID.(string))
+		if err != nil {
+			return spec, nil, fmt.Errorf("error locating datastore for VM: %s", err)
+		}
+		spec.Location.Datastore = types.NewReference(ds.Reference())
+	}
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(c, poolID)
+	if err != nil {
+		return spec, nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	var hs *object.HostSystem
+	if v, ok := d.GetOk("host_system_id"); ok {
+		hsID := v.(string)
+		var err error
+		if hs, err = hostsystem.FromID(c, hsID); err != nil {
+			return spec, nil, fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+		}
+	}
+	if err := resourcepool.ValidateHost(c, pool, hs); err != nil {
+		return spec, nil, err
+	}
+	spec.Location.Pool = types.NewReference(pool.Reference())
+	if hs != nil {
+		spec.Location.Host = types.NewReference(hs.Reference())
+	}
+	spec.Location.Folder = types.NewReference(fo.Reference())
+
+	for k, v := range d.Get("clone.0.instant_clone_guestinfo").(map[string]interface{}) {
+		if !strings.HasPrefix(k, "guestinfo.") {
+			k = "guestinfo." + k
+		}
+		spec.Config = append(spec.Config, &types.OptionValue{
+			Key:   k,
+			Value: v.(string),
+		})
+	}
+	log.Printf("[DEBUG] ExpandVirtualMachineInstantCloneSpec: Instant clone spec prep complete")
+	return spec, vm, nil
+}
@@ -0,0 +1,330 @@
+package vmworkflow
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testInstantCloneSource(cpus, cores, memory int32, cpuHotAdd, memoryHotAdd bool) *mo.VirtualMachine {
+	return &mo.VirtualMachine{
+		Config: &types.VirtualMachineConfigInfo{
+			Uuid:                "42010000-0000-0000-0000-000000000001",
+			CpuHotAddEnabled:    &cpuHotAdd,
+			CpuHotRemoveEnabled: new(bool),
+			MemoryHotAddEnabled: &memoryHotAdd,
+			Hardware: types.VirtualHardware{
+				NumCPU:            cpus,
+				NumCoresPerSocket: cores,
+				MemoryMB:          memory,
+			},
+		},
+		Runtime: types.VirtualMachineRuntimeInfo{
+			PowerState: types.VirtualMachinePowerStatePoweredOn,
+		},
+	}
+}
+
+func TestValidateInstantCloneSource(t *testing.T) {
+	cases := []struct {
+		name      string
+		template  bool
+		state     types.VirtualMachinePowerState
+		expectErr bool
+	}{
+		{
+			name:  "powered on",
+			state: types.VirtualMachinePowerStatePoweredOn,
+		},
+		{
+			name:      "powered off",
+			state:     types.VirtualMachinePowerStatePoweredOff,
+			expectErr: true,
+		},
+		{
+			name:      "template",
+			template:  true,
+			state:     types.VirtualMachinePowerStatePoweredOff,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			props := testInstantCloneSource(2, 1, 2048, false, false)
+			props.Config.Template = tc.template
+			props.Runtime.PowerState = tc.state
+			err := validateInstantCloneSource(props)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateInstantCloneHardware(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"num_cpus":               {Type: schema.TypeInt, Optional: true},
+		"num_cores_per_socket":   {Type: schema.TypeInt, Optional: true},
+		"memory":                 {Type: schema.TypeInt, Optional: true},
+		"cpu_hot_add_enabled":    {Type: schema.TypeBool, Optional: true},
+		"cpu_hot_remove_enabled": {Type: schema.TypeBool, Optional: true},
+		"memory_hot_add_enabled": {Type: schema.TypeBool, Optional: true},
+	}
+	cases := []struct {
+		name      string
+		source    *mo.VirtualMachine
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name:   "same as source",
+			source: testInstantCloneSource(2, 1, 2048, false, false),
+			config: map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 2048},
+		},
+		{
+			name:      "more CPUs without hot add",
+			source:    testInstantCloneSource(2, 1, 2048, false, false),
+			config:    map[string]interface{}{"num_cpus": 4, "num_cores_per_socket": 1, "memory": 2048},
+			expectErr: true,
+		},
+		{
+			name:   "more CPUs with hot add",
+			source: testInstantCloneSource(2, 1, 2048, true, false),
+			config: map[string]interface{}{"num_cpus": 4, "num_cores_per_socket": 1, "memory": 2048, "cpu_hot_add_enabled": true},
+		},
+		{
+			name:      "fewer CPUs without hot remove",
+			source:    testInstantCloneSource(2, 1, 2048, true, false),
+			config:    map[string]interface{}{"num_cpus": 1, "num_cores_per_socket": 1, "memory": 2048, "cpu_hot_add_enabled": true},
+			expectErr: true,
+		},
+		{
+			name:      "more memory without hot add",
+			source:    testInstantCloneSource(2, 1, 2048, false, false),
+			config:    map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 4096},
+			expectErr: true,
+		},
+		{
+			name:   "more memory with hot add",
+			source: testInstantCloneSource(2, 1, 2048, false, true),
+			config: map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 4096, "memory_hot_add_enabled": true},
+		},
+		{
+			name:      "less memory",
+			source:    testInstantCloneSource(2, 1, 2048, false, true),
+			config:    map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 1024, "memory_hot_add_enabled": true},
+			expectErr: true,
+		},
+		{
+			name:      "hot add setting differs",
+			source:    testInstantCloneSource(2, 1, 2048, false, false),
+			config:    map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 2048, "cpu_hot_add_enabled": true},
+			expectErr: true,
+		},
+		{
+			name:      "cores per socket differ",
+			source:    testInstantCloneSource(2, 2, 2048, false, false),
+			config:    map[string]interface{}{"num_cpus": 2, "num_cores_per_socket": 1, "memory": 2048},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, tc.config)
+			err := validateInstantCloneHardware(d, tc.source)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func testCloneSnapshotNode(id, name string, children ...types.VirtualMachineSnapshotTree) types.VirtualMachineSnapshotTree {
+	return types.VirtualMachineSnapshotTree{
+		Snapshot:          types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: id},
+		Name:              name,
+		ChildSnapshotList: children,
+	}
+}
+
+func TestFindCloneSnapshot(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"clone": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"snapshot_name": {Type: schema.TypeString, Optional: true},
+				"snapshot_id":   {Type: schema.TypeString, Optional: true},
+			}},
+		},
+	}
+	tree := &types.VirtualMachineSnapshotInfo{
+		CurrentSnapshot: &types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-4"},
+		RootSnapshotList: []types.VirtualMachineSnapshotTree{
+			testCloneSnapshotNode("snapshot-1", "base",
+				testCloneSnapshotNode("snapshot-2", "patched",
+					testCloneSnapshotNode("snapshot-4", "dup"),
+				),
+				testCloneSnapshotNode("snapshot-3", "dup"),
+			),
+		},
+	}
+	cases := []struct {
+		name      string
+		snapName  string
+		snapID    string
+		snapshots *types.VirtualMachineSnapshotInfo
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "not requested",
+			snapshots: tree,
+		},
+		{
+			name:      "by name at root",
+			snapName:  "base",
+			snapshots: tree,
+			expected:  "snapshot-1",
+		},
+		{
+			name:      "by name in child",
+			snapName:  "patched",
+			snapshots: tree,
+			expected:  "snapshot-2",
+		},
+		{
+			name:      "by ID in nested child",
+			snapID:    "snapshot-4",
+			snapshots: tree,
+			expected:  "snapshot-4",
+		},
+		{
+			name:      "duplicate name",
+			snapName:  "dup",
+			snapshots: tree,
+			expectErr: true,
+		},
+		{
+			name:      "missing name",
+			snapName:  "missing",
+			snapshots: tree,
+			expectErr: true,
+		},
+		{
+			name:      "missing ID",
+			snapID:    "snapshot-99",
+			snapshots: tree,
+			expectErr: true,
+		},
+		{
+			name:      "no snapshots",
+			snapName:  "base",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				"clone": []interface{}{
+					map[string]interface{}{
+						"snapshot_name": tc.snapName,
+						"snapshot_id":   tc.snapID,
+					},
+				},
+			})
+			props := &mo.VirtualMachine{
+				Config:   &types.VirtualMachineConfigInfo{Uuid: "42010000-0000-0000-0000-000000000001"},
+				Snapshot: tc.snapshots,
+			}
+			actual, err := findCloneSnapshot(d, props)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			switch {
+			case tc.expected == "" && actual != nil:
+				t.Fatalf("expected no snapshot, got %s", actual.Value)
+			case tc.expected != "" && (actual == nil || actual.Value != tc.expected):
+				t.Fatalf("expected snapshot %s, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateCloneSnapshots(t *testing.T) {
+	current := func(id string) *types.ManagedObjectReference {
+		return &types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: id}
+	}
+	cases := []struct {
+		name      string
+		snapshots *types.VirtualMachineSnapshotInfo
+		expectErr bool
+	}{
+		{
+			name: "single current snapshot",
+			snapshots: &types.VirtualMachineSnapshotInfo{
+				CurrentSnapshot:  current("snapshot-1"),
+				RootSnapshotList: []types.VirtualMachineSnapshotTree{testCloneSnapshotNode("snapshot-1", "base")},
+			},
+		},
+		{
+			name:      "no snapshots",
+			expectErr: true,
+		},
+		{
+			name: "child snapshots",
+			snapshots: &types.VirtualMachineSnapshotInfo{
+				CurrentSnapshot: current("snapshot-2"),
+				RootSnapshotList: []types.VirtualMachineSnapshotTree{
+					testCloneSnapshotNode("snapshot-1", "base", testCloneSnapshotNode("snapshot-2", "patched")),
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "multiple roots",
+			snapshots: &types.VirtualMachineSnapshotInfo{
+				CurrentSnapshot: current("snapshot-1"),
+				RootSnapshotList: []types.VirtualMachineSnapshotTree{
+					testCloneSnapshotNode("snapshot-1", "base"),
+					testCloneSnapshotNode("snapshot-2", "other"),
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			props := &mo.VirtualMachine{
+				Config:   &types.VirtualMachineConfigInfo{Uuid: "42010000-0000-0000-0000-000000000001"},
+				Snapshot: tc.snapshots,
+			}
+			err := validateCloneSnapshots(props)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package vmworkflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// CloudInitReapplyPolicyNextBoot writes changed cloud-init data to the
+	// virtual machine, leaving it to be picked up by cloud-init on the next
+	// boot of the guest.
+	CloudInitReapplyPolicyNextBoot = "next_boot"
+
+	// CloudInitReapplyPolicyReboot writes changed cloud-init data to the
+	// virtual machine and restarts it.
+	CloudInitReapplyPolicyReboot = "reboot"
+
+	// CloudInitReapplyPolicyRecreate replaces the virtual machine when
+	// cloud-init data changes.
+	CloudInitReapplyPolicyRecreate = "recreate"
+)
+
+// cloudInitReapplyPolicyAllowedValues is a list of the valid values for
+// cloud_init.0.reapply_policy.
+var cloudInitReapplyPolicyAllowedValues = []string{
+	CloudInitReapplyPolicyNextBoot,
+	CloudInitReapplyPolicyReboot,
+	CloudInitReapplyPolicyRecreate,
+}
+
+// cloudInitEncoding is the encoding used for data written to the guestinfo
+// keys. This is understood by the VMware guestinfo cloud-init datasource.
+const cloudInitEncoding = "gzip+base64"
+
+// cloudInitGuestInfoKeys maps the data attributes of the cloud_init block to
+// the guestinfo keys read by the cloud-init datasource.
+var cloudInitGuestInfoKeys = []struct {
+	attr string
+	key  string
+}{
+	{attr: "user_data", key: "guestinfo.userdata"},
+	{attr: "meta_data", key: "guestinfo.metadata"},
+	{attr: "vendor_data", key: "guestinfo.vendordata"},
+}
+
+// VirtualMachineCloudInitSchema returns the schema for the cloud_init block
+// of vsphere_virtual_machine.
+func VirtualMachineCloudInitSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"user_data": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init user data, written to the guestinfo.userdata key.",
+		},
+		"meta_data": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init instance metadata, written to the guestinfo.metadata key.",
+		},
+		"vendor_data": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The cloud-init vendor data, written to the guestinfo.vendordata key.",
+		},
+		"reapply_policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      CloudInitReapplyPolicyNextBoot,
+			Description:  "The action to take when cloud-init data changes on an existing virtual machine. Can be one of next_boot, reboot, or recreate.",
+			ValidateFunc: validation.StringInSlice(cloudInitReapplyPolicyAllowedValues, false),
+		},
+	}
+}
+
+// CloudInitGuestInfoKeys returns all extraConfig keys managed by the
+// cloud_init block, including the encoding keys.
+func CloudInitGuestInfoKeys() []string {
+	var keys []string
+	for _, gk := range cloudInitGuestInfoKeys {
+		keys = append(keys, gk.key, gk.key+".encoding")
+	}
+	return keys
+}
+
+// CloudInitChanged returns true if any of the cloud-init data attributes
+// have changed. Changes to reapply_policy alone are not counted.
+func CloudInitChanged(d interface{ HasChange(string) bool }) bool {
+	if d.HasChange("cloud_init.#") {
+		return true
+	}
+	for _, gk := range cloudInitGuestInfoKeys {
+		if d.HasChange("cloud_init.0." + gk.attr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CloudInitRebootRequired returns true if the cloud-init data of an existing
+// virtual machine has changed and the reapply policy is reboot.
+func CloudInitRebootRequired(d *schema.ResourceData) bool {
+	return d.Id() != "" && CloudInitChanged(d) && d.Get("cloud_init.0.reapply_policy").(string) == CloudInitReapplyPolicyReboot
+}
+
+// ExpandCloudInitExtraConfig returns the extraConfig option values for the
+// cloud_init block. Nothing is returned unless the cloud-init data has
+// changed. Data that has been removed from configuration is cleared from the
+// virtual machine.
+func ExpandCloudInitExtraConfig(d *schema.ResourceData) ([]types.BaseOptionValue, error) {
+	if !CloudInitChanged(d) {
+		return nil, nil
+	}
+	var opts []types.BaseOptionValue
+	for _, gk := range cloudInitGuestInfoKeys {
+		attr, key := gk.attr, gk.key
+		v := d.Get("cloud_init.0." + attr).(string)
+		if v == "" {
+			opts = append(
+				opts,
+				&types.OptionValue{Key: key, Value: ""},
+				&types.OptionValue{Key: key + ".encoding", Value: ""},
+			)
+			continue
+		}
+		encoded, err := encodeCloudInitData(v)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding cloud-init %s: %s", attr, err)
+		}
+		opts = append(
+			opts,
+			&types.OptionValue{Key: key, Value: encoded},
+			&types.OptionValue{Key: key + ".encoding", Value: cloudInitEncoding},
+		)
+	}
+	return opts, nil
+}
+
+// FlattenCloudInit reads the guestinfo keys from a virtual machine's
+// extraConfig and saves the decoded data to the cloud_init block. This is
+// only done if cloud_init is already present in state, so that guestinfo data
+// maintained out-of-band is not adopted.
+func FlattenCloudInit(d *schema.ResourceData, opts []types.BaseOptionValue) error {
+	if len(d.Get("cloud_init").([]interface{})) < 1 {
+		return nil
+	}
+	values := make(map[string]string)
+	for _, v := range opts {
+		ov := v.GetOptionValue()
+		if s, ok := ov.Value.(string); ok {
+			values[ov.Key] = s
+		}
+	}
+	m := map[string]interface{}{
+		"reapply_policy": d.Get("cloud_init.0.reapply_policy").(string),
+	}
+	for _, gk := range cloudInitGuestInfoKeys {
+		data, err := decodeCloudInitData(values[gk.key], values[gk.key+".encoding"])
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %s", gk.key, err)
+		}
+		m[gk.attr] = data
+	}
+	return d.Set("cloud_init", []interface{}{m})
+}
+
+// CloudInitDiffOperation validates the cloud_init block during diff
+// customization. It checks that the block does not clash with guestinfo keys
+// set directly through extra_config, and flags changed data as ForceNew when
+// the reapply policy is recreate.
+func CloudInitDiffOperation(d *schema.ResourceDiff) error {
+	if len(d.Get("cloud_init").([]interface{})) < 1 {
+		return nil
+	}
+	ec := d.Get("extra_config").(map[string]interface{})
+	for _, key := range CloudInitGuestInfoKeys() {
+		if _, ok := ec[key]; ok {
+			return fmt.Errorf("extra_config key %q cannot be set when cloud_init is in use", key)
+		}
+	}
+	if d.Id() == "" || d.Get("cloud_init.0.reapply_policy").(string) != CloudInitReapplyPolicyRecreate {
+		return nil
+	}
+	for _, gk := range cloudInitGuestInfoKeys {
+		k := "cloud_init.0." + gk.attr
+		if d.HasChange(k) {
+			log.Printf("[DEBUG] %s: cloud-init data has changed and reapply_policy is recreate, marking %s as ForceNew", structure.ResourceIDString(d, "vsphere_virtual_machine"), k)
+			if err := d.ForceNew(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeCloudInitData compresses data with gzip and encodes it as base64.
+func encodeCloudInitData(data string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeCloudInitData decodes data read from a guestinfo key according to
+// its encoding. Unencoded data is returned as-is.
+func decodeCloudInitData(data, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "":
+		return data, nil
+	case "base64", "b64":
+		b, err := base64.StdEncoding.DecodeString(data)
+		return string(b), err
+	case "gzip+base64", "gz+b64":
+		b, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return "", err
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		out, err := ioutil.ReadAll(zr)
+		return string(out), err
+	}
+	return "", fmt.Errorf("unsupported encoding %q", encoding)
+}
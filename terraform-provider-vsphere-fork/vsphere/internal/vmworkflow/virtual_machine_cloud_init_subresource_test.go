@@ -0,0 +1,172 @@
+package vmworkflow
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testCloudInitResourceData(t *testing.T, ci map[string]interface{}) *schema.ResourceData {
+	s := map[string]*schema.Schema{
+		"cloud_init": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     &schema.Resource{Schema: VirtualMachineCloudInitSchema()},
+		},
+	}
+	return schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"cloud_init": []interface{}{ci},
+	})
+}
+
+func TestCloudInitDataRoundTrip(t *testing.T) {
+	cases := []string{
+		"",
+		"#cloud-config\nhostname: test\n",
+		"instance-id: i-abcdef\nlocal-hostname: test\n",
+	}
+	for _, data := range cases {
+		encoded, err := encodeCloudInitData(data)
+		if err != nil {
+			t.Fatalf("error encoding %q: %s", data, err)
+		}
+		decoded, err := decodeCloudInitData(encoded, cloudInitEncoding)
+		if err != nil {
+			t.Fatalf("error decoding %q: %s", data, err)
+		}
+		if decoded != data {
+			t.Fatalf("expected %q, got %q", data, decoded)
+		}
+	}
+}
+
+func TestDecodeCloudInitData(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      string
+		encoding  string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "plain",
+			data:     "#cloud-config",
+			expected: "#cloud-config",
+		},
+		{
+			name:     "base64",
+			data:     "I2Nsb3VkLWNvbmZpZw==",
+			encoding: "base64",
+			expected: "#cloud-config",
+		},
+		{
+			name:      "bad gzip",
+			data:      "I2Nsb3VkLWNvbmZpZw==",
+			encoding:  "gz+b64",
+			expectErr: true,
+		},
+		{
+			name:      "unsupported encoding",
+			data:      "#cloud-config",
+			encoding:  "zstd",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := decodeCloudInitData(tc.data, tc.encoding)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpandCloudInitExtraConfig(t *testing.T) {
+	d := testCloudInitResourceData(t, map[string]interface{}{
+		"user_data": "#cloud-config\nhostname: test\n",
+		"meta_data": "instance-id: i-abcdef\n",
+	})
+	opts, err := ExpandCloudInitExtraConfig(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	values := make(map[string]string)
+	for _, v := range opts {
+		ov := v.(*types.OptionValue)
+		values[ov.Key] = ov.Value.(string)
+	}
+	for _, key := range CloudInitGuestInfoKeys() {
+		if _, ok := values[key]; !ok {
+			t.Fatalf("expected key %q to be set", key)
+		}
+	}
+	if len(values) != len(CloudInitGuestInfoKeys()) {
+		t.Fatalf("expected %d keys, got %d", len(CloudInitGuestInfoKeys()), len(values))
+	}
+	for attr, key := range map[string]string{"user_data": "guestinfo.userdata", "meta_data": "guestinfo.metadata"} {
+		if values[key+".encoding"] != cloudInitEncoding {
+			t.Fatalf("expected %s.encoding to be %q, got %q", key, cloudInitEncoding, values[key+".encoding"])
+		}
+		decoded, err := decodeCloudInitData(values[key], values[key+".encoding"])
+		if err != nil {
+			t.Fatalf("error decoding %s: %s", key, err)
+		}
+		if decoded != d.Get("cloud_init.0."+attr).(string) {
+			t.Fatalf("expected %s to decode to %s, got %q", key, attr, decoded)
+		}
+	}
+	if values["guestinfo.vendordata"] != "" || values["guestinfo.vendordata.encoding"] != "" {
+		t.Fatal("expected guestinfo.vendordata to be cleared")
+	}
+}
+
+func TestCloudInitRebootRequired(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   string
+		id       string
+		expected bool
+	}{
+		{
+			name:   "new virtual machine",
+			policy: CloudInitReapplyPolicyReboot,
+		},
+		{
+			name:     "reboot",
+			policy:   CloudInitReapplyPolicyReboot,
+			id:       "vm-1",
+			expected: true,
+		},
+		{
+			name:   "next boot",
+			policy: CloudInitReapplyPolicyNextBoot,
+			id:     "vm-1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := testCloudInitResourceData(t, map[string]interface{}{
+				"user_data":      "#cloud-config",
+				"reapply_policy": tc.policy,
+			})
+			d.SetId(tc.id)
+			if actual := CloudInitRebootRequired(d); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -13,16 +13,35 @@ import (
 )
 
 const (
-	cKeyPrefix        = "clone.0.customize.0"
-	cLinuxKeyPrefix   = "clone.0.customize.0.linux_options.0"
-	cWindowsKeyPrefix = "clone.0.customize.0.windows_options.0"
-	cNetifKeyPrefix   = "clone.0.customize.0.network_interface"
+	cKeyPrefix = "clone.0.customize.0"
+	sKeyPrefix = "spec.0"
 )
 
+// customizeKeys holds the key prefixes used to read customization settings
+// out of ResourceData. vsphere_virtual_machine keeps its settings under
+// clone.0.customize.0, while vsphere_guest_os_customization keeps them under
+// spec.0.
+type customizeKeys struct {
+	base    string
+	linux   string
+	windows string
+	netif   string
+}
+
+// newCustomizeKeys returns the customizeKeys for a base prefix.
+func newCustomizeKeys(base string) customizeKeys {
+	return customizeKeys{
+		base:    base,
+		linux:   base + ".linux_options.0",
+		windows: base + ".windows_options.0",
+		netif:   base + ".network_interface",
+	}
+}
+
 // netifKey renders a specific network_interface key for a specific resource
 // index.
-func netifKey(key string, n int) string {
-	return fmt.Sprintf("%s.%d.%s", cNetifKeyPrefix, n, key)
+func (k customizeKeys) netifKey(key string, n int) string {
+	return fmt.Sprintf("%s.%d.%s", k.netif, n, key)
 }
 
 // matchGateway take an IP, mask, and gateway, and checks to see if the gateway
@@ -53,6 +72,40 @@ func v4CIDRMaskToDotted(mask int) string {
 
 // VirtualMachineCustomizeSchema returns the schema for VM customization.
 func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
+	s := customizeSchema(newCustomizeKeys(cKeyPrefix))
+	s["timeout"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Optional:    true,
+		Default:     10,
+		Description: "The amount of time, in minutes, to wait for guest OS customization to complete before returning with an error. Setting this value to 0 or a negative value skips the waiter.",
+	}
+	return s
+}
+
+// GuestOSCustomizationSpecSchema returns the schema for the spec block of
+// vsphere_guest_os_customization.
+//
+// This is the same schema as VirtualMachineCustomizeSchema, minus the waiter
+// timeout. As a stored spec is usually shared by several virtual machines, the
+// Linux host name and Windows computer name are optional, and default to the
+// name of the virtual machine the spec is applied to.
+func GuestOSCustomizationSpecSchema() map[string]*schema.Schema {
+	s := customizeSchema(newCustomizeKeys(sKeyPrefix))
+	linux := s["linux_options"].Elem.(*schema.Resource).Schema["host_name"]
+	linux.Required = false
+	linux.Optional = true
+	linux.Description = "The host name for the virtual machine. If left blank, the name of the virtual machine is used."
+	windows := s["windows_options"].Elem.(*schema.Resource).Schema["computer_name"]
+	windows.Required = false
+	windows.Optional = true
+	windows.Description = "The computer name for the virtual machine. If left blank, the name of the virtual machine is used."
+	return s
+}
+
+// customizeSchema returns the customization settings shared by
+// VirtualMachineCustomizeSchema and GuestOSCustomizationSpecSchema. The
+// supplied keys are used to build ConflictsWith references.
+func customizeSchema(k customizeKeys) map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		// CustomizationGlobalIPSettings
 		"dns_server_list": {
@@ -73,7 +126,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeList,
 			Optional:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{cKeyPrefix + "." + "windows_options", cKeyPrefix + "." + "windows_sysprep_text"},
+			ConflictsWith: []string{k.base + "." + "windows_options", k.base + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Linux virtual machines.",
 			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 				"domain": {
@@ -109,7 +162,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeList,
 			Optional:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_sysprep_text"},
+			ConflictsWith: []string{k.base + "." + "linux_options", k.base + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Windows virtual machines.",
 			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 				// CustomizationGuiRunOnce
@@ -148,26 +201,26 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"domain_admin_user": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{k.windows + "." + "workgroup"},
 					Description:   "The user account of the domain administrator used to join this virtual machine to the domain.",
 				},
 				"domain_admin_password": {
 					Type:          schema.TypeString,
 					Optional:      true,
 					Sensitive:     true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{k.windows + "." + "workgroup"},
 					Description:   "The password of the domain administrator used to join this virtual machine to the domain.",
 				},
 				"join_domain": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{k.windows + "." + "workgroup"},
 					Description:   "The domain that the virtual machine should join.",
 				},
 				"workgroup": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "join_domain"},
+					ConflictsWith: []string{k.windows + "." + "join_domain"},
 					Description:   "The workgroup for this virtual machine if not joining a domain.",
 				},
 
@@ -203,7 +256,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeString,
 			Optional:      true,
 			Sensitive:     true,
-			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_options"},
+			ConflictsWith: []string{k.base + "." + "linux_options", k.base + "." + "windows_options"},
 			Description:   "Use this option to specify a windows sysprep file directly.",
 		},
 
@@ -258,44 +311,45 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "The IPv6 default gateway when using network_interface customization on the virtual machine. This address must be local to a static IPv4 address configured in an interface sub-resource.",
 		},
-		"timeout": {
-			Type:        schema.TypeInt,
-			Optional:    true,
-			Default:     10,
-			Description: "The amount of time, in minutes, to wait for guest OS customization to complete before returning with an error. Setting this value to 0 or a negative value skips the waiter.",
-		},
 	}
 }
 
+// expandBaseCustomizationName returns a fixed customization name, or a
+// CustomizationVirtualMachineName if the name is empty.
+func expandBaseCustomizationName(name string) types.BaseCustomizationName {
+	if name == "" {
+		return &types.CustomizationVirtualMachineName{}
+	}
+	return &types.CustomizationFixedName{Name: name}
+}
+
 // expandCustomizationGlobalIPSettings reads certain ResourceData keys and
 // returns a CustomizationGlobalIPSettings.
-func expandCustomizationGlobalIPSettings(d *schema.ResourceData) types.CustomizationGlobalIPSettings {
+func expandCustomizationGlobalIPSettings(d *schema.ResourceData, k customizeKeys) types.CustomizationGlobalIPSettings {
 	obj := types.CustomizationGlobalIPSettings{
-		DnsSuffixList: structure.SliceInterfacesToStrings(d.Get(cKeyPrefix + "." + "dns_suffix_list").([]interface{})),
-		DnsServerList: structure.SliceInterfacesToStrings(d.Get(cKeyPrefix + "." + "dns_server_list").([]interface{})),
+		DnsSuffixList: structure.SliceInterfacesToStrings(d.Get(k.base + "." + "dns_suffix_list").([]interface{})),
+		DnsServerList: structure.SliceInterfacesToStrings(d.Get(k.base + "." + "dns_server_list").([]interface{})),
 	}
 	return obj
 }
 
 // expandCustomizationLinuxPrep reads certain ResourceData keys and
 // returns a CustomizationLinuxPrep.
-func expandCustomizationLinuxPrep(d *schema.ResourceData) *types.CustomizationLinuxPrep {
+func expandCustomizationLinuxPrep(d *schema.ResourceData, k customizeKeys) *types.CustomizationLinuxPrep {
 	obj := &types.CustomizationLinuxPrep{
-		HostName: &types.CustomizationFixedName{
-			Name: d.Get(cLinuxKeyPrefix + "." + "host_name").(string),
-		},
-		Domain:     d.Get(cLinuxKeyPrefix + "." + "domain").(string),
-		TimeZone:   d.Get(cLinuxKeyPrefix + "." + "time_zone").(string),
-		HwClockUTC: structure.GetBoolPtr(d, cLinuxKeyPrefix+"."+"hw_clock_utc"),
+		HostName:   expandBaseCustomizationName(d.Get(k.linux + "." + "host_name").(string)),
+		Domain:     d.Get(k.linux + "." + "domain").(string),
+		TimeZone:   d.Get(k.linux + "." + "time_zone").(string),
+		HwClockUTC: structure.GetBoolPtr(d, k.linux+"."+"hw_clock_utc"),
 	}
 	return obj
 }
 
 // expandCustomizationGuiRunOnce reads certain ResourceData keys and
 // returns a CustomizationGuiRunOnce.
-func expandCustomizationGuiRunOnce(d *schema.ResourceData) *types.CustomizationGuiRunOnce {
+func expandCustomizationGuiRunOnce(d *schema.ResourceData, k customizeKeys) *types.CustomizationGuiRunOnce {
 	obj := &types.CustomizationGuiRunOnce{
-		CommandList: structure.SliceInterfacesToStrings(d.Get(cWindowsKeyPrefix + "." + "run_once_command_list").([]interface{})),
+		CommandList: structure.SliceInterfacesToStrings(d.Get(k.windows + "." + "run_once_command_list").([]interface{})),
 	}
 	if len(obj.CommandList) < 1 {
 		return nil
@@ -305,13 +359,13 @@ func expandCustomizationGuiRunOnce(d *schema.ResourceData) *types.CustomizationG
 
 // expandCustomizationGuiUnattended reads certain ResourceData keys and
 // returns a CustomizationGuiUnattended.
-func expandCustomizationGuiUnattended(d *schema.ResourceData) types.CustomizationGuiUnattended {
+func expandCustomizationGuiUnattended(d *schema.ResourceData, k customizeKeys) types.CustomizationGuiUnattended {
 	obj := types.CustomizationGuiUnattended{
-		TimeZone:       int32(d.Get(cWindowsKeyPrefix + "." + "time_zone").(int)),
-		AutoLogon:      d.Get(cWindowsKeyPrefix + "." + "auto_logon").(bool),
-		AutoLogonCount: int32(d.Get(cWindowsKeyPrefix + "." + "auto_logon_count").(int)),
+		TimeZone:       int32(d.Get(k.windows + "." + "time_zone").(int)),
+		AutoLogon:      d.Get(k.windows + "." + "auto_logon").(bool),
+		AutoLogonCount: int32(d.Get(k.windows + "." + "auto_logon_count").(int)),
 	}
-	if v, ok := d.GetOk(cWindowsKeyPrefix + "." + "admin_password"); ok {
+	if v, ok := d.GetOk(k.windows + "." + "admin_password"); ok {
 		obj.Password = &types.CustomizationPassword{
 			Value:     v.(string),
 			PlainText: true,
@@ -323,13 +377,13 @@ func expandCustomizationGuiUnattended(d *schema.ResourceData) types.Customizatio
 
 // expandCustomizationIdentification reads certain ResourceData keys and
 // returns a CustomizationIdentification.
-func expandCustomizationIdentification(d *schema.ResourceData) types.CustomizationIdentification {
+func expandCustomizationIdentification(d *schema.ResourceData, k customizeKeys) types.CustomizationIdentification {
 	obj := types.CustomizationIdentification{
-		JoinWorkgroup: d.Get(cWindowsKeyPrefix + "." + "workgroup").(string),
-		JoinDomain:    d.Get(cWindowsKeyPrefix + "." + "join_domain").(string),
-		DomainAdmin:   d.Get(cWindowsKeyPrefix + "." + "domain_admin_user").(string),
+		JoinWorkgroup: d.Get(k.windows + "." + "workgroup").(string),
+		JoinDomain:    d.Get(k.windows + "." + "join_domain").(string),
+		DomainAdmin:   d.Get(k.windows + "." + "domain_admin_user").(string),
 	}
-	if v, ok := d.GetOk(cWindowsKeyPrefix + "." + "domain_admin_password"); ok {
+	if v, ok := d.GetOk(k.windows + "." + "domain_admin_password"); ok {
 		obj.DomainAdminPassword = &types.CustomizationPassword{
 			Value:     v.(string),
 			PlainText: true,
@@ -340,35 +394,33 @@ func expandCustomizationIdentification(d *schema.ResourceData) types.Customizati
 
 // expandCustomizationUserData reads certain ResourceData keys and
 // returns a CustomizationUserData.
-func expandCustomizationUserData(d *schema.ResourceData) types.CustomizationUserData {
+func expandCustomizationUserData(d *schema.ResourceData, k customizeKeys) types.CustomizationUserData {
 	obj := types.CustomizationUserData{
-		FullName: d.Get(cWindowsKeyPrefix + "." + "full_name").(string),
-		OrgName:  d.Get(cWindowsKeyPrefix + "." + "organization_name").(string),
-		ComputerName: &types.CustomizationFixedName{
-			Name: d.Get(cWindowsKeyPrefix + "." + "computer_name").(string),
-		},
-		ProductId: d.Get(cWindowsKeyPrefix + "." + "product_key").(string),
+		FullName:     d.Get(k.windows + "." + "full_name").(string),
+		OrgName:      d.Get(k.windows + "." + "organization_name").(string),
+		ComputerName: expandBaseCustomizationName(d.Get(k.windows + "." + "computer_name").(string)),
+		ProductId:    d.Get(k.windows + "." + "product_key").(string),
 	}
 	return obj
 }
 
 // expandCustomizationSysprep reads certain ResourceData keys and
 // returns a CustomizationSysprep.
-func expandCustomizationSysprep(d *schema.ResourceData) *types.CustomizationSysprep {
+func expandCustomizationSysprep(d *schema.ResourceData, k customizeKeys) *types.CustomizationSysprep {
 	obj := &types.CustomizationSysprep{
-		GuiUnattended:  expandCustomizationGuiUnattended(d),
-		UserData:       expandCustomizationUserData(d),
-		GuiRunOnce:     expandCustomizationGuiRunOnce(d),
-		Identification: expandCustomizationIdentification(d),
+		GuiUnattended:  expandCustomizationGuiUnattended(d, k),
+		UserData:       expandCustomizationUserData(d, k),
+		GuiRunOnce:     expandCustomizationGuiRunOnce(d, k),
+		Identification: expandCustomizationIdentification(d, k),
 	}
 	return obj
 }
 
 // expandCustomizationSysprepText reads certain ResourceData keys and
 // returns a CustomizationSysprepText.
-func expandCustomizationSysprepText(d *schema.ResourceData) *types.CustomizationSysprepText {
+func expandCustomizationSysprepText(d *schema.ResourceData, k customizeKeys) *types.CustomizationSysprepText {
 	obj := &types.CustomizationSysprepText{
-		Value: d.Get(cKeyPrefix + "." + "windows_sysprep_text").(string),
+		Value: d.Get(k.base + "." + "windows_sysprep_text").(string),
 	}
 	return obj
 }
@@ -379,17 +431,17 @@ func expandCustomizationSysprepText(d *schema.ResourceData) *types.Customization
 // Only one of the three types of identity settings can be specified: Linux
 // settings (from linux_options), Windows settings (from windows_options), and
 // the raw Windows sysprep file (via windows_sysprep_text).
-func expandBaseCustomizationIdentitySettings(d *schema.ResourceData, family string) types.BaseCustomizationIdentitySettings {
+func expandBaseCustomizationIdentitySettings(d *schema.ResourceData, k customizeKeys, family string) types.BaseCustomizationIdentitySettings {
 	var obj types.BaseCustomizationIdentitySettings
-	_, windowsExists := d.GetOkExists(cKeyPrefix + "." + "windows_options")
-	_, sysprepExists := d.GetOkExists(cKeyPrefix + "." + "windows_sysprep_text")
+	_, windowsExists := d.GetOkExists(k.base + "." + "windows_options")
+	_, sysprepExists := d.GetOkExists(k.base + "." + "windows_sysprep_text")
 	switch {
 	case family == string(types.VirtualMachineGuestOsFamilyLinuxGuest):
-		obj = expandCustomizationLinuxPrep(d)
+		obj = expandCustomizationLinuxPrep(d, k)
 	case family == string(types.VirtualMachineGuestOsFamilyWindowsGuest) && windowsExists:
-		obj = expandCustomizationSysprep(d)
+		obj = expandCustomizationSysprep(d, k)
 	case family == string(types.VirtualMachineGuestOsFamilyWindowsGuest) && sysprepExists:
-		obj = expandCustomizationSysprepText(d)
+		obj = expandCustomizationSysprepText(d, k)
 	default:
 		obj = &types.CustomizationIdentitySettings{}
 	}
@@ -398,15 +450,15 @@ func expandBaseCustomizationIdentitySettings(d *schema.ResourceData, family stri
 
 // expandCustomizationIPSettingsIPV6AddressSpec reads certain ResourceData keys and
 // returns a CustomizationIPSettingsIpV6AddressSpec.
-func expandCustomizationIPSettingsIPV6AddressSpec(d *schema.ResourceData, n int, gwAdd bool) (*types.CustomizationIPSettingsIpV6AddressSpec, bool) {
-	v, ok := d.GetOk(netifKey("ipv6_address", n))
+func expandCustomizationIPSettingsIPV6AddressSpec(d *schema.ResourceData, k customizeKeys, n int, gwAdd bool) (*types.CustomizationIPSettingsIpV6AddressSpec, bool) {
+	v, ok := d.GetOk(k.netifKey("ipv6_address", n))
 	var gwFound bool
 	if !ok {
 		return nil, gwFound
 	}
 	addr := v.(string)
-	mask := d.Get(netifKey("ipv6_netmask", n)).(int)
-	gw, gwOk := d.Get(cKeyPrefix + "." + "ipv6_gateway").(string)
+	mask := d.Get(k.netifKey("ipv6_netmask", n)).(int)
+	gw, gwOk := d.Get(k.base + "." + "ipv6_gateway").(string)
 	obj := &types.CustomizationIPSettingsIpV6AddressSpec{
 		Ip: []types.BaseCustomizationIpV6Generator{
 			&types.CustomizationFixedIpV6{
@@ -424,11 +476,11 @@ func expandCustomizationIPSettingsIPV6AddressSpec(d *schema.ResourceData, n int,
 
 // expandCustomizationIPSettings reads certain ResourceData keys and
 // returns a CustomizationIPSettings.
-func expandCustomizationIPSettings(d *schema.ResourceData, n int, v4gwAdd, v6gwAdd bool) (types.CustomizationIPSettings, bool, bool) {
+func expandCustomizationIPSettings(d *schema.ResourceData, k customizeKeys, n int, v4gwAdd, v6gwAdd bool) (types.CustomizationIPSettings, bool, bool) {
 	var v4gwFound, v6gwFound bool
-	v4addr, v4addrOk := d.GetOk(netifKey("ipv4_address", n))
-	v4mask := d.Get(netifKey("ipv4_netmask", n)).(int)
-	v4gw, v4gwOk := d.Get(cKeyPrefix + "." + "ipv4_gateway").(string)
+	v4addr, v4addrOk := d.GetOk(k.netifKey("ipv4_address", n))
+	v4mask := d.Get(k.netifKey("ipv4_netmask", n)).(int)
+	v4gw, v4gwOk := d.Get(k.base + "." + "ipv4_gateway").(string)
 	var obj types.CustomizationIPSettings
 	switch {
 	case v4addrOk:
@@ -444,16 +496,16 @@ func expandCustomizationIPSettings(d *schema.ResourceData, n int, v4gwAdd, v6gwA
 	default:
 		obj.Ip = &types.CustomizationDhcpIpGenerator{}
 	}
-	obj.DnsServerList = structure.SliceInterfacesToStrings(d.Get(netifKey("dns_server_list", n)).([]interface{}))
-	obj.DnsDomain = d.Get(netifKey("dns_domain", n)).(string)
-	obj.IpV6Spec, v6gwFound = expandCustomizationIPSettingsIPV6AddressSpec(d, n, v6gwAdd)
+	obj.DnsServerList = structure.SliceInterfacesToStrings(d.Get(k.netifKey("dns_server_list", n)).([]interface{}))
+	obj.DnsDomain = d.Get(k.netifKey("dns_domain", n)).(string)
+	obj.IpV6Spec, v6gwFound = expandCustomizationIPSettingsIPV6AddressSpec(d, k, n, v6gwAdd)
 	return obj, v4gwFound, v6gwFound
 }
 
 // expandSliceOfCustomizationAdapterMapping reads certain ResourceData keys and
 // returns a CustomizationAdapterMapping slice.
-func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.CustomizationAdapterMapping {
-	s := d.Get(cKeyPrefix + "." + "network_interface").([]interface{})
+func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData, k customizeKeys) []types.CustomizationAdapterMapping {
+	s := d.Get(k.base + "." + "network_interface").([]interface{})
 	if len(s) < 1 {
 		return nil
 	}
@@ -461,7 +513,7 @@ func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.Cu
 	var v4gwFound, v6gwFound bool
 	for i := range s {
 		var adapter types.CustomizationIPSettings
-		adapter, v4gwFound, v6gwFound = expandCustomizationIPSettings(d, i, !v4gwFound, !v6gwFound)
+		adapter, v4gwFound, v6gwFound = expandCustomizationIPSettings(d, k, i, !v4gwFound, !v6gwFound)
 		obj := types.CustomizationAdapterMapping{
 			Adapter: adapter,
 		}
@@ -473,10 +525,22 @@ func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.Cu
 // ExpandCustomizationSpec reads certain ResourceData keys and
 // returns a CustomizationSpec.
 func ExpandCustomizationSpec(d *schema.ResourceData, family string) types.CustomizationSpec {
+	return expandCustomizationSpec(d, newCustomizeKeys(cKeyPrefix), family)
+}
+
+// ExpandGuestOSCustomizationSpec reads the spec block of
+// vsphere_guest_os_customization and returns a CustomizationSpec.
+func ExpandGuestOSCustomizationSpec(d *schema.ResourceData, family string) types.CustomizationSpec {
+	return expandCustomizationSpec(d, newCustomizeKeys(sKeyPrefix), family)
+}
+
+// expandCustomizationSpec reads the customization settings under the supplied
+// keys and returns a CustomizationSpec.
+func expandCustomizationSpec(d *schema.ResourceData, k customizeKeys, family string) types.CustomizationSpec {
 	obj := types.CustomizationSpec{
-		Identity:         expandBaseCustomizationIdentitySettings(d, family),
-		GlobalIPSettings: expandCustomizationGlobalIPSettings(d),
-		NicSettingMap:    expandSliceOfCustomizationAdapterMapping(d),
+		Identity:         expandBaseCustomizationIdentitySettings(d, k, family),
+		GlobalIPSettings: expandCustomizationGlobalIPSettings(d, k),
+		NicSettingMap:    expandSliceOfCustomizationAdapterMapping(d, k),
 	}
 	return obj
 }
@@ -484,10 +548,22 @@ func ExpandCustomizationSpec(d *schema.ResourceData, family string) types.Custom
 // ValidateCustomizationSpec checks the validity of the supplied customization
 // spec. It should be called during diff customization to veto invalid configs.
 func ValidateCustomizationSpec(d *schema.ResourceDiff, family string) error {
+	return validateCustomizationSpec(d, newCustomizeKeys(cKeyPrefix), family)
+}
+
+// ValidateGuestOSCustomizationSpec checks the validity of the spec block of
+// vsphere_guest_os_customization.
+func ValidateGuestOSCustomizationSpec(d *schema.ResourceDiff, family string) error {
+	return validateCustomizationSpec(d, newCustomizeKeys(sKeyPrefix), family)
+}
+
+// validateCustomizationSpec checks that the customization settings under the
+// supplied keys carry the options required by the OS family.
+func validateCustomizationSpec(d *schema.ResourceDiff, k customizeKeys, family string) error {
 	// Validate that the proper section exists for OS family suboptions.
-	linuxExists := len(d.Get(cKeyPrefix+"."+"linux_options").([]interface{})) > 0 || !structure.ValuesAvailable(cKeyPrefix+"."+"linux_options.", []string{"host_name", "domain"}, d)
-	windowsExists := len(d.Get(cKeyPrefix+"."+"windows_options").([]interface{})) > 0 || !structure.ValuesAvailable(cKeyPrefix+"."+"windows_options.", []string{"computer_name"}, d)
-	sysprepExists := d.Get(cKeyPrefix+"."+"windows_sysprep_text").(string) != "" || !structure.ValuesAvailable(cKeyPrefix+".", []string{"windows_sysprep_text"}, d)
+	linuxExists := len(d.Get(k.base+"."+"linux_options").([]interface{})) > 0 || !structure.ValuesAvailable(k.base+"."+"linux_options.", []string{"host_name", "domain"}, d)
+	windowsExists := len(d.Get(k.base+"."+"windows_options").([]interface{})) > 0 || !structure.ValuesAvailable(k.base+"."+"windows_options.", []string{"computer_name"}, d)
+	sysprepExists := d.Get(k.base+"."+"windows_sysprep_text").(string) != "" || !structure.ValuesAvailable(k.base+".", []string{"windows_sysprep_text"}, d)
 	switch {
 	case family == string(types.VirtualMachineGuestOsFamilyLinuxGuest) && !linuxExists:
 		return errors.New("linux_options must exist in VM customization options for Linux operating systems")
@@ -496,3 +572,126 @@ func ValidateCustomizationSpec(d *schema.ResourceDiff, family string) error {
 	}
 	return nil
 }
+
+// FlattenGuestOSCustomizationSpec saves a CustomizationSpec to the spec block
+// of vsphere_guest_os_customization.
+//
+// vCenter encrypts passwords when a spec is stored, so the administrator and
+// domain administrator passwords cannot be read back. The values in state are
+// carried over instead.
+func FlattenGuestOSCustomizationSpec(d *schema.ResourceData, spec types.CustomizationSpec) error {
+	return d.Set("spec", flattenCustomizationSpec(d, newCustomizeKeys(sKeyPrefix), spec))
+}
+
+// flattenCustomizationSpec returns the customization settings for a
+// CustomizationSpec, in the layout of customizeSchema.
+func flattenCustomizationSpec(d *schema.ResourceData, k customizeKeys, spec types.CustomizationSpec) []interface{} {
+	m := map[string]interface{}{
+		"dns_server_list": structure.SliceStringsToInterfaces(spec.GlobalIPSettings.DnsServerList),
+		"dns_suffix_list": structure.SliceStringsToInterfaces(spec.GlobalIPSettings.DnsSuffixList),
+	}
+	switch identity := spec.Identity.(type) {
+	case *types.CustomizationLinuxPrep:
+		m["linux_options"] = flattenCustomizationLinuxPrep(identity)
+	case *types.CustomizationSysprep:
+		m["windows_options"] = flattenCustomizationSysprep(d, k, identity)
+	case *types.CustomizationSysprepText:
+		m["windows_sysprep_text"] = identity.Value
+	}
+	var netifs []interface{}
+	for _, mapping := range spec.NicSettingMap {
+		netif, v4gw, v6gw := flattenCustomizationIPSettings(mapping.Adapter)
+		netifs = append(netifs, netif)
+		if _, ok := m["ipv4_gateway"]; !ok && v4gw != "" {
+			m["ipv4_gateway"] = v4gw
+		}
+		if _, ok := m["ipv6_gateway"]; !ok && v6gw != "" {
+			m["ipv6_gateway"] = v6gw
+		}
+	}
+	m["network_interface"] = netifs
+	return []interface{}{m}
+}
+
+// flattenBaseCustomizationName returns the fixed name out of a
+// BaseCustomizationName, or an empty string if the name is generated.
+func flattenBaseCustomizationName(name types.BaseCustomizationName) string {
+	if fixed, ok := name.(*types.CustomizationFixedName); ok {
+		return fixed.Name
+	}
+	return ""
+}
+
+// flattenCustomizationLinuxPrep returns the linux_options for a
+// CustomizationLinuxPrep.
+func flattenCustomizationLinuxPrep(obj *types.CustomizationLinuxPrep) []interface{} {
+	hwClockUTC := true
+	if obj.HwClockUTC != nil {
+		hwClockUTC = *obj.HwClockUTC
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"domain":       obj.Domain,
+			"host_name":    flattenBaseCustomizationName(obj.HostName),
+			"hw_clock_utc": hwClockUTC,
+			"time_zone":    obj.TimeZone,
+		},
+	}
+}
+
+// flattenCustomizationSysprep returns the windows_options for a
+// CustomizationSysprep.
+func flattenCustomizationSysprep(d *schema.ResourceData, k customizeKeys, obj *types.CustomizationSysprep) []interface{} {
+	m := map[string]interface{}{
+		"auto_logon":            obj.GuiUnattended.AutoLogon,
+		"auto_logon_count":      int(obj.GuiUnattended.AutoLogonCount),
+		"admin_password":        d.Get(k.windows + "." + "admin_password").(string),
+		"time_zone":             int(obj.GuiUnattended.TimeZone),
+		"domain_admin_user":     obj.Identification.DomainAdmin,
+		"domain_admin_password": d.Get(k.windows + "." + "domain_admin_password").(string),
+		"join_domain":           obj.Identification.JoinDomain,
+		"workgroup":             obj.Identification.JoinWorkgroup,
+		"computer_name":         flattenBaseCustomizationName(obj.UserData.ComputerName),
+		"full_name":             obj.UserData.FullName,
+		"organization_name":     obj.UserData.OrgName,
+		"product_key":           obj.UserData.ProductId,
+	}
+	if obj.GuiRunOnce != nil {
+		m["run_once_command_list"] = structure.SliceStringsToInterfaces(obj.GuiRunOnce.CommandList)
+	}
+	return []interface{}{m}
+}
+
+// flattenCustomizationIPSettings returns a network_interface entry for a
+// CustomizationIPSettings, along with any IPv4 and IPv6 gateways found on the
+// adapter.
+func flattenCustomizationIPSettings(obj types.CustomizationIPSettings) (map[string]interface{}, string, string) {
+	var v4gw, v6gw string
+	m := map[string]interface{}{
+		"dns_server_list": structure.SliceStringsToInterfaces(obj.DnsServerList),
+		"dns_domain":      obj.DnsDomain,
+	}
+	if ip, ok := obj.Ip.(*types.CustomizationFixedIp); ok {
+		m["ipv4_address"] = ip.IpAddress
+		if mask := net.ParseIP(obj.SubnetMask).To4(); mask != nil {
+			ones, _ := net.IPMask(mask).Size()
+			m["ipv4_netmask"] = ones
+		}
+		if len(obj.Gateway) > 0 {
+			v4gw = obj.Gateway[0]
+		}
+	}
+	if obj.IpV6Spec != nil {
+		for _, gen := range obj.IpV6Spec.Ip {
+			if ip, ok := gen.(*types.CustomizationFixedIpV6); ok {
+				m["ipv6_address"] = ip.IpAddress
+				m["ipv6_netmask"] = int(ip.SubnetMask)
+				break
+			}
+		}
+		if len(obj.IpV6Spec.Gateway) > 0 {
+			v6gw = obj.IpV6Spec.Gateway[0]
+		}
+	}
+	return m, v4gw, v6gw
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/preflight"
 )
 
 // defaultAPITimeout is a default timeout value that is passed to functions
@@ -19,18 +21,60 @@ func Provider() terraform.ResourceProvider {
 		Schema: map[string]*schema.Schema{
 			"user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_USER", nil),
 				Description: "The user name for vSphere API operations.",
 			},
 
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PASSWORD", nil),
 				Description: "The user password for vSphere API operations.",
 			},
 
+			"saml_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SAML_TOKEN", nil),
+				Description: "A SAML token issued by vCenter SSO to authenticate with instead of a user and password. This is a bearer token unless client_certificate_path and client_key_path are set, in which case it is a holder-of-key token signed with that certificate.",
+			},
+			"client_certificate_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_CERTIFICATE_PATH", nil),
+				Description: "The path to a PEM-encoded solution user certificate. Without saml_token, a holder-of-key token is requested from vCenter SSO with this certificate.",
+			},
+			"client_key_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_KEY_PATH", nil),
+				Description: "The path to the PEM-encoded private key for client_certificate_path.",
+			},
+			"session_cookie": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SESSION_COOKIE", nil),
+				Description: "The value of an existing, authenticated vmware_soap_session cookie to use for the SOAP API session.",
+			},
+			"clone_ticket": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLONE_TICKET", nil),
+				Description: "A clone ticket acquired from an existing SOAP API session, used to clone that session.",
+			},
+			"rest_session_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_REST_SESSION_ID", nil),
+				Description: "An existing, authenticated REST API session ID to use with session_cookie or clone_ticket authentication. Without it, tags and content library are not available with those methods.",
+			},
+
 			"vsphere_server": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -91,11 +135,46 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_VIM_KEEP_ALIVE", 10),
 				Description: "Keep alive interval for the VIM session in minutes",
 			},
+			"api_max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_MAX_RETRIES", 3),
+				Description:  "The maximum number of times an API call is retried after a transient fault, such as TaskInProgress, ConcurrentAccess, or HTTP 503. Set to 0 to disable retries.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"api_retry_min_delay": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_RETRY_MIN_DELAY", 1000),
+				Description:  "The delay before the first retry of an API call in milliseconds. The delay doubles with each further retry, with jitter.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"api_retry_max_delay": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_RETRY_MAX_DELAY", 30000),
+				Description:  "The maximum delay between retries of an API call in milliseconds.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"property_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PROPERTY_CACHE", false),
+				Description: "Bulk-load virtual machine, host, and datastore properties on first use, and serve later lookups from memory. Speeds up refreshes of large inventories. The cache is dropped for the rest of the run on the first API call that could change the inventory, so it only helps runs that do not make changes, such as plan and refresh.",
+			},
+			"preflight_checks": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_PREFLIGHT_CHECKS", preflight.ModeWarn),
+				Description:  "How to handle failed plan-time capacity and compatibility checks for virtual machines and virtual disks. Can be one of error, warn, or off. With warn, failed checks do not stop the plan and are only written to the log at the WARN level, so they are not shown unless TF_LOG is set to WARN or a more verbose level. Use error to have failed checks stop the plan.",
+				ValidateFunc: validation.StringInSlice(preflight.Modes, false),
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
 			"vsphere_compute_cluster":                         resourceVSphereComputeCluster(),
 			"vsphere_compute_cluster_host_group":              resourceVSphereComputeClusterHostGroup(),
+			"vsphere_compute_cluster_rolling_maintenance":     resourceVSphereComputeClusterRollingMaintenance(),
 			"vsphere_compute_cluster_vm_affinity_rule":        resourceVSphereComputeClusterVMAffinityRule(),
 			"vsphere_compute_cluster_vm_anti_affinity_rule":   resourceVSphereComputeClusterVMAntiAffinityRule(),
 			"vsphere_compute_cluster_vm_dependency_rule":      resourceVSphereComputeClusterVMDependencyRule(),
@@ -104,6 +183,7 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_content_library":                         resourceVSphereContentLibrary(),
 			"vsphere_content_library_item":                    resourceVSphereContentLibraryItem(),
 			"vsphere_custom_attribute":                        resourceVSphereCustomAttribute(),
+			"vsphere_custom_attribute_value":                  resourceVSphereCustomAttributeValue(),
 			"vsphere_datacenter":                              resourceVSphereDatacenter(),
 			"vsphere_datastore_cluster":                       resourceVSphereDatastoreCluster(),
 			"vsphere_datastore_cluster_vm_anti_affinity_rule": resourceVSphereDatastoreClusterVMAntiAffinityRule(),
@@ -113,16 +193,21 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_dpm_host_override":                       resourceVSphereDPMHostOverride(),
 			"vsphere_file":                                    resourceVSphereFile(),
 			"vsphere_folder":                                  resourceVSphereFolder(),
+			"vsphere_guest_os_customization":                  resourceVSphereGuestOSCustomization(),
 			"vsphere_ha_vm_override":                          resourceVSphereHAVMOverride(),
+			"vsphere_host_certificate":                        resourceVSphereHostCertificate(),
 			"vsphere_host_port_group":                         resourceVSphereHostPortGroup(),
+			"vsphere_host_profile":                            resourceVSphereHostProfile(),
 			"vsphere_host_virtual_switch":                     resourceVSphereHostVirtualSwitch(),
 			"vsphere_license":                                 resourceVSphereLicense(),
 			"vsphere_resource_pool":                           resourceVSphereResourcePool(),
 			"vsphere_tag":                                     resourceVSphereTag(),
 			"vsphere_tag_category":                            resourceVSphereTagCategory(),
+			"vsphere_tag_association":                         resourceVSphereTagAssociation(),
 			"vsphere_virtual_disk":                            resourceVSphereVirtualDisk(),
 			"vsphere_virtual_machine":                         resourceVSphereVirtualMachine(),
 			"vsphere_nas_datastore":                           resourceVSphereNasDatastore(),
+			"vsphere_network_protocol_profile":                resourceVSphereNetworkProtocolProfile(),
 			"vsphere_storage_drs_vm_override":                 resourceVSphereStorageDrsVMOverride(),
 			"vsphere_vapp_container":                          resourceVSphereVAppContainer(),
 			"vsphere_vapp_entity":                             resourceVSphereVAppEntity(),
@@ -137,17 +222,20 @@ func Provider() terraform.ResourceProvider {
 
 		DataSourcesMap: map[string]*schema.Resource{
 			"vsphere_compute_cluster":            dataSourceVSphereComputeCluster(),
+			"vsphere_compute_cluster_placement":  dataSourceVSphereComputeClusterPlacement(),
 			"vsphere_content_library":            dataSourceVSphereContentLibrary(),
 			"vsphere_content_library_item":       dataSourceVSphereContentLibraryItem(),
 			"vsphere_custom_attribute":           dataSourceVSphereCustomAttribute(),
 			"vsphere_datacenter":                 dataSourceVSphereDatacenter(),
 			"vsphere_datastore":                  dataSourceVSphereDatastore(),
 			"vsphere_datastore_cluster":          dataSourceVSphereDatastoreCluster(),
+			"vsphere_datastore_files":            dataSourceVSphereDatastoreFiles(),
 			"vsphere_distributed_virtual_switch": dataSourceVSphereDistributedVirtualSwitch(),
 			"vsphere_dynamic":                    dataSourceVSphereDynamic(),
 			"vsphere_folder":                     dataSourceVSphereFolder(),
 			"vsphere_host":                       dataSourceVSphereHost(),
 			"vsphere_host_pci_device":            dataSourceVSphereHostPciDevice(),
+			"vsphere_host_profile_compliance":    dataSourceVSphereHostProfileCompliance(),
 			"vsphere_host_thumbprint":            dataSourceVSphereHostThumbprint(),
 			"vsphere_network":                    dataSourceVSphereNetwork(),
 			"vsphere_resource_pool":              dataSourceVSphereResourcePool(),
@@ -156,6 +244,7 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
 			"vsphere_vapp_container":             dataSourceVSphereVAppContainer(),
 			"vsphere_virtual_machine":            dataSourceVSphereVirtualMachine(),
+			"vsphere_virtual_machines":           dataSourceVSphereVirtualMachines(),
 			"vsphere_vmfs_disks":                 dataSourceVSphereVmfsDisks(),
 			"vsphere_role":                       dataSourceVsphereRole(),
 		},
@@ -0,0 +1,351 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/utils"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	rollingMaintenanceAdmissionControlPending   = "admissionControlPending"
+	rollingMaintenanceAdmissionControlSatisfied = "admissionControlSatisfied"
+)
+
+func resourceVSphereComputeClusterRollingMaintenance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterRollingMaintenanceCreate,
+		Read:   resourceVSphereComputeClusterRollingMaintenanceRead,
+		Update: resourceVSphereComputeClusterRollingMaintenanceUpdate,
+		Delete: resourceVSphereComputeClusterRollingMaintenanceDelete,
+
+		Schema: map[string]*schema.Schema{
+			"compute_cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the cluster whose hosts are put into maintenance.",
+			},
+			"host_system_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The managed object IDs of the hosts to put into maintenance, in order. Defaults to all hosts in the cluster, ordered by name.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary values that start a rolling maintenance run of the cluster when changed.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"batch_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "The number of hosts put into maintenance at the same time.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"evacuate_powered_off_vms": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether or not powered off virtual machines are moved off each host as well.",
+			},
+			"maintenance_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				Description:  "The time, in seconds, to wait for each host to be evacuated and enter maintenance mode.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"host_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Command to be run for each host while it is in maintenance mode. The host's ID and name are passed in the HostSystemId and HostName environment variables.",
+			},
+			"admission_control_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      600,
+				Description:  "The time, in seconds, to wait for HA admission control to be satisfied after each batch of hosts leaves maintenance mode.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+func resourceVSphereComputeClusterRollingMaintenanceCreate(d *schema.ResourceData, meta interface{}) error {
+	// Nothing is put into maintenance on create. Runs only happen when the
+	// triggers change afterwards.
+	cluster, err := resourceVSphereComputeClusterRollingMaintenanceCluster(d, meta)
+	if err != nil {
+		return err
+	}
+	if _, err := resourceVSphereComputeClusterRollingMaintenanceHosts(d, meta, cluster); err != nil {
+		return err
+	}
+	d.SetId(cluster.Reference().Value)
+	return resourceVSphereComputeClusterRollingMaintenanceRead(d, meta)
+}
+
+func resourceVSphereComputeClusterRollingMaintenanceRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if _, err := clustercomputeresource.FromID(client, d.Get("compute_cluster_id").(string)); err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Cluster not found, marking resource as gone", resourceVSphereComputeClusterRollingMaintenanceIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereComputeClusterRollingMaintenanceUpdate(d *schema.ResourceData, meta interface{}) error {
+	if !d.HasChange("triggers") {
+		return resourceVSphereComputeClusterRollingMaintenanceRead(d, meta)
+	}
+	// If the run stops part way through, the triggers are kept at their old
+	// values so that the next apply starts the run again.
+	d.Partial(true)
+
+	cluster, err := resourceVSphereComputeClusterRollingMaintenanceCluster(d, meta)
+	if err != nil {
+		return err
+	}
+	hosts, err := resourceVSphereComputeClusterRollingMaintenanceHosts(d, meta, cluster)
+	if err != nil {
+		return err
+	}
+	resourceVSphereComputeClusterRollingMaintenanceCheckDrs(d, cluster)
+
+	batchSize := d.Get("batch_size").(int)
+	for i := 0; i < len(hosts); i += batchSize {
+		end := i + batchSize
+		if end > len(hosts) {
+			end = len(hosts)
+		}
+		if err := resourceVSphereComputeClusterRollingMaintenanceBatch(d, cluster, hosts[i:end]); err != nil {
+			return err
+		}
+	}
+
+	d.Partial(false)
+	return resourceVSphereComputeClusterRollingMaintenanceRead(d, meta)
+}
+
+func resourceVSphereComputeClusterRollingMaintenanceDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// resourceVSphereComputeClusterRollingMaintenanceIDString prints a friendly
+// string for the vsphere_compute_cluster_rolling_maintenance resource.
+func resourceVSphereComputeClusterRollingMaintenanceIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_compute_cluster_rolling_maintenance")
+}
+
+func resourceVSphereComputeClusterRollingMaintenanceCluster(d *schema.ResourceData, meta interface{}) (*object.ClusterComputeResource, error) {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	cluster, err := clustercomputeresource.FromID(client, d.Get("compute_cluster_id").(string))
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	return cluster, nil
+}
+
+// resourceVSphereComputeClusterRollingMaintenanceHosts returns the hosts to
+// put into maintenance, in order. If host_system_ids is set, the hosts are
+// checked for membership of the cluster, otherwise all hosts in the cluster
+// are returned, ordered by name.
+func resourceVSphereComputeClusterRollingMaintenanceHosts(d *schema.ResourceData, meta interface{}, cluster *object.ClusterComputeResource) ([]*object.HostSystem, error) {
+	ids := structure.SliceInterfacesToStrings(d.Get("host_system_ids").([]interface{}))
+	if len(ids) < 1 {
+		hosts, err := clustercomputeresource.Hosts(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("error listing hosts in cluster %q: %s", cluster.InventoryPath, err)
+		}
+		sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name() < hosts[j].Name() })
+		return hosts, nil
+	}
+
+	var hosts []*object.HostSystem
+	for _, id := range ids {
+		host, err := hostsystem.FromID(meta.(*VSphereClient).vimClient, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate host %q: %s", id, err)
+		}
+		member, err := clustercomputeresource.IsMember(cluster, host)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, fmt.Errorf("host %q is not a member of cluster %q", host.Name(), cluster.InventoryPath)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// resourceVSphereComputeClusterRollingMaintenanceCheckDrs logs a warning if
+// DRS will not evacuate hosts on its own. Maintenance mode still works in this
+// case, but each host waits until its virtual machines are moved off by hand.
+func resourceVSphereComputeClusterRollingMaintenanceCheckDrs(d *schema.ResourceData, cluster *object.ClusterComputeResource) {
+	props, err := clustercomputeresource.Properties(cluster)
+	if err != nil {
+		log.Printf("[WARN] %s: Could not check DRS configuration: %s", resourceVSphereComputeClusterRollingMaintenanceIDString(d), err)
+		return
+	}
+	cfg, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return
+	}
+	if cfg.DrsConfig.Enabled == nil || !*cfg.DrsConfig.Enabled || cfg.DrsConfig.DefaultVmBehavior != types.DrsBehaviorFullyAutomated {
+		log.Printf("[WARN] %s: DRS is not fully automated on cluster %q, hosts will not be evacuated automatically", resourceVSphereComputeClusterRollingMaintenanceIDString(d), cluster.InventoryPath)
+	}
+}
+
+// resourceVSphereComputeClusterRollingMaintenanceBatch runs maintenance on a
+// batch of hosts. All hosts in the batch enter maintenance mode, the host
+// command is run for each host, then all hosts exit maintenance mode and HA
+// admission control is checked before returning.
+//
+// If a host fails to enter maintenance mode, the hosts of the batch that have
+// already entered it are taken back out and the run stops.
+func resourceVSphereComputeClusterRollingMaintenanceBatch(d *schema.ResourceData, cluster *object.ClusterComputeResource, hosts []*object.HostSystem) error {
+	timeout := d.Get("maintenance_timeout").(int)
+	var entered []*object.HostSystem
+	for _, host := range hosts {
+		log.Printf("[DEBUG] %s: Entering maintenance mode on host %q", resourceVSphereComputeClusterRollingMaintenanceIDString(d), host.Name())
+		if err := hostsystem.EnterMaintenanceMode(host, timeout, d.Get("evacuate_powered_off_vms").(bool)); err != nil {
+			for _, e := range entered {
+				if exitErr := hostsystem.ExitMaintenanceMode(e, timeout); exitErr != nil {
+					log.Printf("[WARN] %s: Could not take host %q out of maintenance mode: %s", resourceVSphereComputeClusterRollingMaintenanceIDString(d), e.Name(), exitErr)
+				}
+			}
+			return fmt.Errorf("host %q failed to enter maintenance mode, stopping: %s", host.Name(), err)
+		}
+		entered = append(entered, host)
+	}
+
+	if cmd := d.Get("host_command").(string); cmd != "" {
+		for _, host := range hosts {
+			env := map[string]string{
+				"HostSystemId": host.Reference().Value,
+				"HostName":     host.Name(),
+				"StartTime":    time.Now().UTC().Format(time.RFC3339),
+			}
+			log.Printf("[DEBUG] %s: Running host command for host %q", resourceVSphereComputeClusterRollingMaintenanceIDString(d), host.Name())
+			stdout, stderr, err := utils.ExecutePowershellCmd(cmd, env)
+			log.Printf("[DEBUG] %s: Host command for host %q finished: [stdout]%s [stderr]%s", resourceVSphereComputeClusterRollingMaintenanceIDString(d), host.Name(), stdout, stderr)
+			if err != nil || stderr != "" {
+				return fmt.Errorf("host command failed for host %q, leaving it in maintenance mode: [err]%v [stderr]%s", host.Name(), err, stderr)
+			}
+		}
+	}
+
+	for _, host := range hosts {
+		log.Printf("[DEBUG] %s: Exiting maintenance mode on host %q", resourceVSphereComputeClusterRollingMaintenanceIDString(d), host.Name())
+		if err := hostsystem.ExitMaintenanceMode(host, timeout); err != nil {
+			return fmt.Errorf("host %q failed to exit maintenance mode: %s", host.Name(), err)
+		}
+	}
+
+	return resourceVSphereComputeClusterRollingMaintenanceWaitForAdmissionControl(d, cluster)
+}
+
+// resourceVSphereComputeClusterRollingMaintenanceWaitForAdmissionControl
+// waits for HA admission control on the cluster to be satisfied, so that the
+// next batch of hosts is not taken out while the cluster is still short on
+// failover capacity. Nothing is checked if HA or admission control is
+// disabled.
+func resourceVSphereComputeClusterRollingMaintenanceWaitForAdmissionControl(d *schema.ResourceData, cluster *object.ClusterComputeResource) error {
+	var reason string
+	refresh := func() (interface{}, string, error) {
+		props, err := clustercomputeresource.Properties(cluster)
+		if err != nil {
+			return nil, "", err
+		}
+		var ok bool
+		ok, reason = clusterAdmissionControlSatisfied(props)
+		if !ok {
+			log.Printf("[DEBUG] %s: Waiting for HA admission control: %s", resourceVSphereComputeClusterRollingMaintenanceIDString(d), reason)
+			return props, rollingMaintenanceAdmissionControlPending, nil
+		}
+		return props, rollingMaintenanceAdmissionControlSatisfied, nil
+	}
+
+	wait := &resource.StateChangeConf{
+		Pending:    []string{rollingMaintenanceAdmissionControlPending},
+		Target:     []string{rollingMaintenanceAdmissionControlSatisfied},
+		Refresh:    refresh,
+		Timeout:    time.Duration(d.Get("admission_control_timeout").(int)) * time.Second,
+		MinTimeout: 5 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	if _, err := wait.WaitForState(); err != nil {
+		return fmt.Errorf("HA admission control not satisfied on cluster %q, stopping: %s (%s)", cluster.InventoryPath, reason, err)
+	}
+	return nil
+}
+
+// clusterAdmissionControlSatisfied checks the current failover capacity of a
+// cluster against its HA admission control policy. If it is not satisfied, a
+// reason is returned.
+func clusterAdmissionControlSatisfied(props *mo.ClusterComputeResource) (bool, string) {
+	cfg, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok || cfg.DasConfig.Enabled == nil || !*cfg.DasConfig.Enabled {
+		return true, ""
+	}
+	if cfg.DasConfig.AdmissionControlEnabled == nil || !*cfg.DasConfig.AdmissionControlEnabled {
+		return true, ""
+	}
+	summary, ok := props.Summary.(*types.ClusterComputeResourceSummary)
+	if !ok {
+		return false, "cluster summary not available"
+	}
+	if summary.NumEffectiveHosts < summary.NumHosts {
+		return false, fmt.Sprintf("%d of %d hosts available", summary.NumEffectiveHosts, summary.NumHosts)
+	}
+
+	switch policy := cfg.DasConfig.AdmissionControlPolicy.(type) {
+	case *types.ClusterFailoverLevelAdmissionControlPolicy:
+		info, ok := summary.AdmissionControlInfo.(*types.ClusterFailoverLevelAdmissionControlInfo)
+		if ok && info.CurrentFailoverLevel < policy.FailoverLevel {
+			return false, fmt.Sprintf("current failover level %d is below %d", info.CurrentFailoverLevel, policy.FailoverLevel)
+		}
+	case *types.ClusterFailoverResourcesAdmissionControlPolicy:
+		info, ok := summary.AdmissionControlInfo.(*types.ClusterFailoverResourcesAdmissionControlInfo)
+		if ok && info.CurrentCpuFailoverResourcesPercent < policy.CpuFailoverResourcesPercent {
+			return false, fmt.Sprintf("current CPU failover capacity %d%% is below %d%%", info.CurrentCpuFailoverResourcesPercent, policy.CpuFailoverResourcesPercent)
+		}
+		if ok && info.CurrentMemoryFailoverResourcesPercent < policy.MemoryFailoverResourcesPercent {
+			return false, fmt.Sprintf("current memory failover capacity %d%% is below %d%%", info.CurrentMemoryFailoverResourcesPercent, policy.MemoryFailoverResourcesPercent)
+		}
+	case *types.ClusterFailoverHostAdmissionControlPolicy:
+		info, ok := summary.AdmissionControlInfo.(*types.ClusterFailoverHostAdmissionControlInfo)
+		if ok {
+			for _, status := range info.HostStatus {
+				if status.Status == types.ManagedEntityStatusRed {
+					return false, fmt.Sprintf("failover host %q is not available", status.Host.Value)
+				}
+			}
+		}
+	}
+	return true, ""
+}
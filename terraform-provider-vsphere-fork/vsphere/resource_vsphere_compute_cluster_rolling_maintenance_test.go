@@ -0,0 +1,103 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterRollingMaintenance_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccResourceVSphereComputeClusterRollingMaintenancePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereComputeClusterRollingMaintenanceConfig("one"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"vsphere_compute_cluster_rolling_maintenance.maintenance", "id",
+						"data.vsphere_compute_cluster.rootcompute_cluster1", "id",
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereComputeClusterRollingMaintenanceConfig("two"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_compute_cluster_rolling_maintenance.maintenance", "triggers.run", "two"),
+				),
+			},
+		},
+	})
+}
+
+func TestClusterAdmissionControlSatisfied(t *testing.T) {
+	props := func(current int32) *mo.ClusterComputeResource {
+		return &mo.ClusterComputeResource{
+			ComputeResource: mo.ComputeResource{
+				Summary: &types.ClusterComputeResourceSummary{
+					ComputeResourceSummary: types.ComputeResourceSummary{NumHosts: 3, NumEffectiveHosts: 3},
+					AdmissionControlInfo:   &types.ClusterFailoverLevelAdmissionControlInfo{CurrentFailoverLevel: current},
+				},
+				ConfigurationEx: &types.ClusterConfigInfoEx{
+					DasConfig: types.ClusterDasConfigInfo{
+						Enabled:                 structure.BoolPtr(true),
+						AdmissionControlEnabled: structure.BoolPtr(true),
+						AdmissionControlPolicy:  &types.ClusterFailoverLevelAdmissionControlPolicy{FailoverLevel: 1},
+					},
+				},
+			},
+		}
+	}
+
+	if ok, reason := clusterAdmissionControlSatisfied(props(1)); !ok {
+		t.Fatalf("expected admission control to be satisfied, got %q", reason)
+	}
+	if ok, _ := clusterAdmissionControlSatisfied(props(0)); ok {
+		t.Fatal("expected admission control not to be satisfied below the failover level")
+	}
+
+	p := props(1)
+	p.Summary.(*types.ClusterComputeResourceSummary).NumEffectiveHosts = 2
+	if ok, _ := clusterAdmissionControlSatisfied(p); ok {
+		t.Fatal("expected admission control not to be satisfied with a host unavailable")
+	}
+
+	p = props(0)
+	p.ConfigurationEx.(*types.ClusterConfigInfoEx).DasConfig.AdmissionControlEnabled = structure.BoolPtr(false)
+	if ok, reason := clusterAdmissionControlSatisfied(p); !ok {
+		t.Fatalf("expected disabled admission control to be satisfied, got %q", reason)
+	}
+}
+
+func testAccResourceVSphereComputeClusterRollingMaintenancePreCheck(t *testing.T) {
+	if os.Getenv("TF_VAR_VSPHERE_CLUSTER") == "" {
+		t.Skip("set TF_VAR_VSPHERE_CLUSTER to run vsphere_compute_cluster_rolling_maintenance acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterRollingMaintenanceConfig(run string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_compute_cluster_rolling_maintenance" "maintenance" {
+  compute_cluster_id = "${data.vsphere_compute_cluster.rootcompute_cluster1.id}"
+
+  triggers = {
+    run = "%s"
+  }
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootComputeCluster1()),
+		run,
+	)
+}
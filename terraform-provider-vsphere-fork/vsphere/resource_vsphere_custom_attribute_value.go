@@ -0,0 +1,200 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereCustomAttributeValue() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereCustomAttributeValueCreate,
+		Read:   resourceVSphereCustomAttributeValueRead,
+		Update: resourceVSphereCustomAttributeValueUpdate,
+		Delete: resourceVSphereCustomAttributeValueDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereCustomAttributeValueImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"object_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the entity to set custom attribute values on.",
+			},
+			"object_type": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object type of the entity to set custom attribute values on, such as VirtualMachine or HostSystem.",
+			},
+			customattribute.ConfigKey: {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Description: "A map of custom attribute IDs to values to set on the entity. Attributes not in this map are left alone.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereCustomAttributeValueCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereCustomAttributeValueIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := customattribute.VerifySupport(client); err != nil {
+		return err
+	}
+	ref := types.ManagedObjectReference{
+		Type:  d.Get("object_type").(string),
+		Value: d.Get("object_id").(string),
+	}
+	if _, err := customAttributeValueEntity(client, ref); err != nil {
+		return fmt.Errorf("error locating object %s %q: %s", ref.Type, ref.Value, err)
+	}
+	if err := customAttributeValueProcessDiff(client, d, ref); err != nil {
+		return err
+	}
+	d.SetId(ref.String())
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereCustomAttributeValueIDString(d))
+	return resourceVSphereCustomAttributeValueRead(d, meta)
+}
+
+func resourceVSphereCustomAttributeValueRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereCustomAttributeValueIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := customattribute.VerifySupport(client); err != nil {
+		return err
+	}
+	var ref types.ManagedObjectReference
+	if !ref.FromString(d.Id()) {
+		return fmt.Errorf("could not parse custom attribute value ID %q, expected Type:ID", d.Id())
+	}
+	entity, err := customAttributeValueEntity(client, ref)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Object has been deleted", resourceVSphereCustomAttributeValueIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	// An object's custom attributes are often shared by several tools, so
+	// values are only read back for keys already in custom_attributes. Empty
+	// values are treated as unset. An import starts with no keys, and adopts
+	// every value set on the object.
+	managed := d.Get(customattribute.ConfigKey).(map[string]interface{})
+	attrs := make(map[string]interface{})
+	for _, fv := range entity.CustomValue {
+		sv, ok := fv.(*types.CustomFieldStringValue)
+		if !ok || sv.Value == "" {
+			continue
+		}
+		key := fmt.Sprint(sv.Key)
+		if _, ok := managed[key]; ok || len(managed) < 1 {
+			attrs[key] = sv.Value
+		}
+	}
+
+	d.Set("object_id", ref.Value)
+	d.Set("object_type", ref.Type)
+	if err := d.Set(customattribute.ConfigKey, attrs); err != nil {
+		return fmt.Errorf("error setting %s: %s", customattribute.ConfigKey, err)
+	}
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereCustomAttributeValueIDString(d))
+	return nil
+}
+
+func resourceVSphereCustomAttributeValueUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereCustomAttributeValueIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := customattribute.VerifySupport(client); err != nil {
+		return err
+	}
+	var ref types.ManagedObjectReference
+	if !ref.FromString(d.Id()) {
+		return fmt.Errorf("could not parse custom attribute value ID %q, expected Type:ID", d.Id())
+	}
+	if err := customAttributeValueProcessDiff(client, d, ref); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereCustomAttributeValueIDString(d))
+	return resourceVSphereCustomAttributeValueRead(d, meta)
+}
+
+func resourceVSphereCustomAttributeValueDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereCustomAttributeValueIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := customattribute.VerifySupport(client); err != nil {
+		return err
+	}
+	var ref types.ManagedObjectReference
+	if !ref.FromString(d.Id()) {
+		return fmt.Errorf("could not parse custom attribute value ID %q, expected Type:ID", d.Id())
+	}
+	processor, err := customattribute.GetDiffProcessor(client, d.Get(customattribute.ConfigKey).(map[string]interface{}), nil)
+	if err != nil {
+		return err
+	}
+	if err := processor.ProcessDiff(object.NewReference(client.Client, ref)); err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereCustomAttributeValueIDString(d))
+	return nil
+}
+
+func resourceVSphereCustomAttributeValueImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	var ref types.ManagedObjectReference
+	if !ref.FromString(d.Id()) {
+		return nil, fmt.Errorf("could not parse custom attribute value ID %q, expected Type:ID", d.Id())
+	}
+	if _, err := customAttributeValueEntity(client, ref); err != nil {
+		return nil, fmt.Errorf("error locating object %s %q: %s", ref.Type, ref.Value, err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// customAttributeValueProcessDiff sets and clears custom attribute values on
+// the supplied object according to the diff of the custom attributes map.
+func customAttributeValueProcessDiff(client *govmomi.Client, d *schema.ResourceData, ref types.ManagedObjectReference) error {
+	old, new := d.GetChange(customattribute.ConfigKey)
+	processor, err := customattribute.GetDiffProcessor(client, old.(map[string]interface{}), new.(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	return processor.ProcessDiff(object.NewReference(client.Client, ref))
+}
+
+// customAttributeValueEntity fetches the custom values of a managed entity.
+func customAttributeValueEntity(client *govmomi.Client, ref types.ManagedObjectReference) (*mo.ManagedEntity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var entity mo.ManagedEntity
+	if err := property.DefaultCollector(client.Client).RetrieveOne(ctx, ref, []string{"customValue"}, &entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// resourceVSphereCustomAttributeValueIDString prints a friendly string for the
+// vsphere_custom_attribute_value resource.
+func resourceVSphereCustomAttributeValueIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_custom_attribute_value")
+}
@@ -0,0 +1,101 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereCustomAttributeValue_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereCustomAttributeValueHasValue("owner", ""),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereCustomAttributeValueConfig("team-a"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereCustomAttributeValueHasValue("owner", "team-a"),
+					resource.TestCheckResourceAttr("vsphere_custom_attribute_value.value", "custom_attributes.%", "1"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereCustomAttributeValueConfig("team-b"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereCustomAttributeValueHasValue("owner", "team-b"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_custom_attribute_value.value",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereCustomAttributeValueHasValue(attr, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		attrRS, ok := s.RootModule().Resources["vsphere_custom_attribute."+attr]
+		if !ok {
+			if expected == "" {
+				return nil
+			}
+			return fmt.Errorf("vsphere_custom_attribute.%s not found in state", attr)
+		}
+		hostRS, ok := s.RootModule().Resources["data.vsphere_host.roothost1"]
+		if !ok {
+			if expected == "" {
+				return nil
+			}
+			return errors.New("data.vsphere_host.roothost1 not found in state")
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		entity, err := customAttributeValueEntity(client, types.ManagedObjectReference{Type: "HostSystem", Value: hostRS.Primary.ID})
+		if err != nil {
+			return err
+		}
+		var actual string
+		for _, fv := range entity.CustomValue {
+			if fmt.Sprint(fv.GetCustomFieldValue().Key) == attrRS.Primary.ID {
+				actual = fv.(*types.CustomFieldStringValue).Value
+			}
+		}
+		if actual != expected {
+			return fmt.Errorf("expected custom attribute %q to be %q, got %q", attr, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereCustomAttributeValueConfig(owner string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_custom_attribute" "owner" {
+  name                = "testacc-owner"
+  managed_object_type = "HostSystem"
+}
+
+resource "vsphere_custom_attribute_value" "value" {
+  object_id   = "${data.vsphere_host.roothost1.id}"
+  object_type = "HostSystem"
+
+  custom_attributes = {
+    "${vsphere_custom_attribute.owner.id}" = "%s"
+  }
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootHost1()),
+		owner,
+	)
+}
@@ -377,6 +377,31 @@ func TestAccResourceVSphereDistributedVirtualSwitch_vlanRanges(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereDistributedVirtualSwitch_pvlanMapping(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedVirtualSwitchPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedVirtualSwitchConfigPvlanMapping(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedVirtualSwitchExists(true),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1000, types.VmwareDistributedVirtualSwitchPvlanPortTypePromiscuous),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1001, types.VmwareDistributedVirtualSwitchPvlanPortTypeIsolated),
+					testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(1000, 1002, types.VmwareDistributedVirtualSwitchPvlanPortTypeCommunity),
+					resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "pvlan_mapping.#", "3"),
+					resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "port_private_secondary_vlan_id", "1001"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereDistributedVirtualSwitch_singleCustomAttribute(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -599,6 +624,22 @@ func testAccResourceVSphereDistributedVirtualSwitchHasVlanRange(emin, emax int32
 	}
 }
 
+func testAccResourceVSphereDistributedVirtualSwitchHasPvlanMapping(primary, secondary int32, pvlanType types.VmwareDistributedVirtualSwitchPvlanPortType) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		entries := props.Config.(*types.VMwareDVSConfigInfo).PvlanConfig
+		for _, e := range entries {
+			if e.PrimaryVlanId == primary && e.SecondaryVlanId == secondary && e.PvlanType == string(pvlanType) {
+				return nil
+			}
+		}
+		return fmt.Errorf("could not find %s PVLAN mapping %d/%d in %#v", pvlanType, primary, secondary, entries)
+	}
+}
+
 func testAccResourceVSphereDistributedVirtualSwitchMatchInventoryPath(expected string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		dvs, err := testGetDVS(s, "dvs")
@@ -1089,6 +1130,43 @@ resource "vsphere_distributed_virtual_switch" "dvs" {
 	)
 }
 
+func testAccResourceVSphereDistributedVirtualSwitchConfigPvlanMapping() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "testacc-dvs"
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = 1000
+    pvlan_type        = "promiscuous"
+  }
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = 1001
+    pvlan_type        = "isolated"
+  }
+
+  pvlan_mapping {
+    primary_vlan_id   = 1000
+    secondary_vlan_id = 1002
+    pvlan_type        = "community"
+  }
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "testacc-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  port_private_secondary_vlan_id  = 1001
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootPortGroup1()),
+	)
+}
+
 func testAccResourceVSphereDistributedVirtualSwitchConfigSingleCustomAttribute() string {
 	return fmt.Sprintf(`
 %s
@@ -40,8 +40,24 @@ func resourceVsphereEntityPermissions() *schema.Resource {
 		Update:        resourceEntityPermissionsUpdate,
 		Delete:        resourceEntityPermissionsDelete,
 		CustomizeDiff: resourceVSphereEntityPermissionsCustomizeDiff,
-		Schema:        sch,
+		Importer: &schema.ResourceImporter{
+			State: resourceEntityPermissionsImport,
+		},
+		Schema: sch,
+	}
+}
+
+func resourceEntityPermissionsImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Permissions are imported by the entity they are set on, in the form
+	// Type:ID, such as VirtualMachine:vm-123.
+	var ref types.ManagedObjectReference
+	if !ref.FromString(d.Id()) {
+		return nil, fmt.Errorf("could not parse entity permissions ID %q, expected Type:ID", d.Id())
 	}
+	d.Set("entity_type", ref.Type)
+	d.Set("entity_id", ref.Value)
+	d.SetId(ref.Value)
+	return []*schema.ResourceData{d}, nil
 }
 
 func resourceEntityPermissionsCreate(d *schema.ResourceData, meta interface{}) error {
@@ -2,19 +2,41 @@ package vsphere
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
+const (
+	fileChecksumModeNone      = "none"
+	fileChecksumModeSHA256    = "sha256"
+	fileChecksumModeSizeMtime = "size_mtime"
+)
+
+var fileChecksumModeAllowedValues = []string{
+	fileChecksumModeNone,
+	fileChecksumModeSHA256,
+	fileChecksumModeSizeMtime,
+}
+
 type file struct {
 	sourceDatacenter  string
 	datacenter        string
@@ -24,6 +46,8 @@ type file struct {
 	destinationFile   string
 	createDirectories bool
 	copyFile          bool
+	directory         bool
+	deleteExtraFiles  bool
 }
 
 func resourceVSphereFile() *schema.Resource {
@@ -33,6 +57,8 @@ func resourceVSphereFile() *schema.Resource {
 		Update: resourceVSphereFileUpdate,
 		Delete: resourceVSphereFileDelete,
 
+		CustomizeDiff: resourceVSphereFileCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"datacenter": {
 				Type:     schema.TypeString,
@@ -71,6 +97,39 @@ func resourceVSphereFile() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+
+			"directory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Treat source_file as a local directory and destination_file as a datastore directory, and sync the contents recursively.",
+			},
+
+			"delete_extra_files": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "When syncing a directory, delete files in the destination directory that are not present in the source directory.",
+			},
+
+			"checksum_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      fileChecksumModeNone,
+				Description:  "The fingerprint used to detect changes to the source. Can be one of none, sha256, or size_mtime. Sources copied from a datastore, and the destination, are always tracked by size and modification time, and the destination is only hashed when those change.",
+				ValidateFunc: validation.StringInSlice(fileChecksumModeAllowedValues, false),
+			},
+
+			"checksum": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The fingerprint of the source as of the last upload or copy. Cleared when the destination has drifted.",
+			},
+
+			"remote_checksum": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The size and modification time fingerprint of the destination as of the last read.",
+			},
 		},
 	}
 }
@@ -118,6 +177,9 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 		f.createDirectories = v.(bool)
 	}
 
+	f.directory = d.Get("directory").(bool)
+	f.deleteExtraFiles = d.Get("delete_extra_files").(bool)
+
 	err := createFile(client, &f)
 	if err != nil {
 		return err
@@ -126,6 +188,10 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 	d.SetId(fmt.Sprintf("[%v] %v/%v", f.datastore, f.datacenter, f.destinationFile))
 	log.Printf("[INFO] Created file: %s", f.destinationFile)
 
+	if err := setFileSourceChecksum(d, client, &f); err != nil {
+		return err
+	}
+
 	return resourceVSphereFileRead(d, meta)
 }
 
@@ -171,6 +237,10 @@ func createFile(client *govmomi.Client, f *file) error {
 	}
 	dstDfm := dstDatastore.NewFileManager(dstDatacenter, false)
 
+	if f.directory {
+		return syncDirectory(client, dstDatacenter, dstDatastore, f)
+	}
+
 	if f.createDirectories {
 		err = createDirectory(dstDfm, f)
 		if err != nil {
@@ -190,7 +260,10 @@ func createFile(client *govmomi.Client, f *file) error {
 			return fmt.Errorf("error %s", err)
 		}
 
-		srcDfm := srcDatastore.NewFileManager(srcDatacenter, false)
+		// Overwrite the destination if it exists, as it does when the copy is
+		// being redone because the source has changed or the destination has
+		// drifted.
+		srcDfm := srcDatastore.NewFileManager(srcDatacenter, true)
 		srcDfm.DatacenterTarget = dstDatacenter
 
 		dstFilePath := dstDfm.Path(f.destinationFile)
@@ -215,6 +288,7 @@ func createFile(client *govmomi.Client, f *file) error {
 		// moving VMDK(s) and regular files e.g. ISO(s)
 		// If the source is a VMDK the Move method uses the correct MoveVirtualDisk_Task instead of
 		// MoveDatastoreFile_Task
+		dstDfm.Force = true
 		err = dstDfm.Move(context.TODO(), tempDstFile, f.destinationFile)
 		if err != nil {
 			return fmt.Errorf("error %s", err)
@@ -290,7 +364,41 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 		if !ok {
 			return err
 		}
+		return nil
+	}
+
+	// Check the destination for drift. The destination is tracked by size and
+	// modification time so that its contents are not downloaded on every
+	// refresh. If these have changed since the last read, the source checksum
+	// is cleared so that the next plan picks up the difference and the source
+	// is uploaded again. In sha256 mode, uploads are hashed first, so that a
+	// destination that was only touched is not uploaded again.
+	mode := d.Get("checksum_mode").(string)
+	if mode == fileChecksumModeNone {
+		d.Set("remote_checksum", "")
+		return nil
+	}
+	directory := d.Get("directory").(bool)
+	remote, err := remoteFileFingerprint(ds, f.destinationFile, directory)
+	if err != nil {
+		return fmt.Errorf("error computing fingerprint of %s: %s", ds.Path(f.destinationFile), err)
+	}
+	if old := d.Get("remote_checksum").(string); old != "" && old != remote {
+		drifted := true
+		copyFile := d.Get("source_datacenter").(string) != "" || d.Get("source_datastore").(string) != ""
+		if mode == fileChecksumModeSHA256 && !copyFile {
+			sum, err := remoteFileSHA256(ds, f.destinationFile, directory)
+			if err != nil {
+				return fmt.Errorf("error computing checksum of %s: %s", ds.Path(f.destinationFile), err)
+			}
+			drifted = sum != d.Get("checksum").(string)
+		}
+		if drifted {
+			log.Printf("[DEBUG] resourceVSphereFileRead - %s has drifted (fingerprint %q, expected %q)", ds.Path(f.destinationFile), remote, old)
+			d.Set("checksum", "")
+		}
 	}
+	d.Set("remote_checksum", remote)
 
 	return nil
 }
@@ -361,9 +469,36 @@ func resourceVSphereFileUpdate(d *schema.ResourceData, meta interface{}) error {
 		if err != nil {
 			return err
 		}
+		// Re-baseline the destination fingerprint in the new location.
+		d.Set("remote_checksum", "")
+	}
+
+	if d.HasChange("checksum") || d.HasChange("delete_extra_files") {
+		// The source has changed or the destination has drifted. Upload or copy
+		// the source again.
+		f := file{
+			sourceDatacenter:  d.Get("source_datacenter").(string),
+			datacenter:        d.Get("datacenter").(string),
+			sourceDatastore:   d.Get("source_datastore").(string),
+			datastore:         d.Get("datastore").(string),
+			sourceFile:        d.Get("source_file").(string),
+			destinationFile:   d.Get("destination_file").(string),
+			createDirectories: d.Get("create_directories").(bool),
+			directory:         d.Get("directory").(bool),
+			deleteExtraFiles:  d.Get("delete_extra_files").(bool),
+		}
+		f.copyFile = f.sourceDatacenter != "" || f.sourceDatastore != ""
+		client := meta.(*VSphereClient).vimClient
+		if err := createFile(client, &f); err != nil {
+			return err
+		}
+		if err := setFileSourceChecksum(d, client, &f); err != nil {
+			return err
+		}
+		d.Set("remote_checksum", "")
 	}
 
-	return nil
+	return resourceVSphereFileRead(d, meta)
 }
 
 func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
@@ -442,3 +577,305 @@ func getDatastore(f *find.Finder, ds string) (*object.Datastore, error) {
 	dso, err := f.DefaultDatastore(context.TODO())
 	return dso, err
 }
+
+func resourceVSphereFileCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	directory := d.Get("directory").(bool)
+	if directory && d.Get("source_datastore").(string) != "" {
+		return errors.New("directory cannot be used when copying from source_datastore")
+	}
+	if d.Get("delete_extra_files").(bool) && !directory {
+		return errors.New("delete_extra_files can only be used with directory")
+	}
+	mode := d.Get("checksum_mode").(string)
+	if mode == fileChecksumModeNone {
+		return nil
+	}
+	for _, k := range []string{"source_file", "source_datacenter", "source_datastore", "checksum_mode"} {
+		if !d.NewValueKnown(k) {
+			log.Printf("[DEBUG] resourceVSphereFileCustomizeDiff: %s is not known, deferring checksum", k)
+			return d.SetNewComputed("checksum")
+		}
+	}
+	f := file{
+		sourceDatacenter: d.Get("source_datacenter").(string),
+		sourceDatastore:  d.Get("source_datastore").(string),
+		sourceFile:       d.Get("source_file").(string),
+		directory:        directory,
+	}
+	f.copyFile = f.sourceDatacenter != "" || f.sourceDatastore != ""
+	fp, err := sourceFileFingerprint(meta.(*VSphereClient).vimClient, &f, mode)
+	if err != nil {
+		// The source may not exist yet if it is generated during the apply.
+		log.Printf("[DEBUG] resourceVSphereFileCustomizeDiff: cannot compute fingerprint of %s, deferring checksum: %s", f.sourceFile, err)
+		return d.SetNewComputed("checksum")
+	}
+	if fp != d.Get("checksum").(string) {
+		log.Printf("[DEBUG] resourceVSphereFileCustomizeDiff: source %s has changed or destination has drifted", f.sourceFile)
+		return d.SetNew("checksum", fp)
+	}
+	return nil
+}
+
+// setFileSourceChecksum computes the fingerprint of the source and saves it
+// to the checksum attribute.
+func setFileSourceChecksum(d *schema.ResourceData, client *govmomi.Client, f *file) error {
+	mode := d.Get("checksum_mode").(string)
+	if mode == fileChecksumModeNone {
+		d.Set("checksum", "")
+		return nil
+	}
+	fp, err := sourceFileFingerprint(client, f, mode)
+	if err != nil {
+		return fmt.Errorf("error computing fingerprint of %s: %s", f.sourceFile, err)
+	}
+	d.Set("checksum", fp)
+	return nil
+}
+
+// sourceFileFingerprint computes the fingerprint of the source of a file,
+// either on the local filesystem or on the source datastore. Sources on a
+// datastore are fingerprinted by size and modification time regardless of
+// mode, as hashing them would mean downloading them on every plan.
+func sourceFileFingerprint(client *govmomi.Client, f *file, mode string) (string, error) {
+	if !f.copyFile {
+		return localFileFingerprint(f.sourceFile, mode, f.directory)
+	}
+	finder := find.NewFinder(client.Client, true)
+	dc, err := finder.Datacenter(context.TODO(), f.sourceDatacenter)
+	if err != nil {
+		return "", err
+	}
+	finder = finder.SetDatacenter(dc)
+	ds, err := getDatastore(finder, f.sourceDatastore)
+	if err != nil {
+		return "", err
+	}
+	return remoteFileFingerprint(ds, f.sourceFile, false)
+}
+
+// fileEntry is a single file found during a directory walk, with its path
+// relative to the directory root.
+type fileEntry struct {
+	rel      string
+	size     int64
+	modified time.Time
+}
+
+// fileEntryFingerprint computes the fingerprint of a single file. For the
+// sha256 mode, the contents are read through open.
+func fileEntryFingerprint(e fileEntry, mode string, open func(rel string) (io.ReadCloser, error)) (string, error) {
+	if mode == fileChecksumModeSizeMtime {
+		return fmt.Sprintf("%d:%d", e.size, e.modified.Unix()), nil
+	}
+	r, err := open(e.rel)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	return sha256Reader(r)
+}
+
+// fingerprintEntries combines the fingerprints of the files in a directory
+// into a single fingerprint. The entries must be sorted by relative path.
+func fingerprintEntries(entries []fileEntry, mode string, open func(rel string) (io.ReadCloser, error)) (string, error) {
+	h := sha256.New()
+	for _, e := range entries {
+		fp, err := fileEntryFingerprint(e, mode, open)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s:%s\n", e.rel, fp)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// localFileFingerprint computes the fingerprint of a local file or directory.
+func localFileFingerprint(p, mode string, directory bool) (string, error) {
+	if !directory {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return "", err
+		}
+		e := fileEntry{size: fi.Size(), modified: fi.ModTime()}
+		return fileEntryFingerprint(e, mode, func(string) (io.ReadCloser, error) {
+			return os.Open(p)
+		})
+	}
+	entries, err := localDirectoryEntries(p)
+	if err != nil {
+		return "", err
+	}
+	return fingerprintEntries(entries, mode, func(rel string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(p, filepath.FromSlash(rel)))
+	})
+}
+
+// remoteFileFingerprint computes the size and modification time fingerprint
+// of a file or directory on a datastore. The contents are not read.
+func remoteFileFingerprint(ds *object.Datastore, p string, directory bool) (string, error) {
+	return remoteFileEntriesFingerprint(ds, p, fileChecksumModeSizeMtime, directory)
+}
+
+// remoteFileSHA256 computes the sha256 fingerprint of a file or directory on a
+// datastore. This downloads the contents, so it should only be used when the
+// size or modification time has changed.
+func remoteFileSHA256(ds *object.Datastore, p string, directory bool) (string, error) {
+	return remoteFileEntriesFingerprint(ds, p, fileChecksumModeSHA256, directory)
+}
+
+// remoteFileEntriesFingerprint computes the fingerprint of a file or directory
+// on a datastore in the supplied mode.
+func remoteFileEntriesFingerprint(ds *object.Datastore, p, mode string, directory bool) (string, error) {
+	download := func(p string) (io.ReadCloser, error) {
+		r, _, err := ds.Download(context.TODO(), p, &soap.DefaultDownload)
+		return r, err
+	}
+	if !directory {
+		fi, err := ds.Stat(context.TODO(), p)
+		if err != nil {
+			return "", err
+		}
+		info := fi.GetFileInfo()
+		e := fileEntry{size: info.FileSize}
+		if info.Modification != nil {
+			e.modified = *info.Modification
+		}
+		return fileEntryFingerprint(e, mode, func(string) (io.ReadCloser, error) {
+			return download(p)
+		})
+	}
+	entries, err := remoteDirectoryEntries(ds, p)
+	if err != nil {
+		return "", err
+	}
+	return fingerprintEntries(entries, mode, func(rel string) (io.ReadCloser, error) {
+		return download(path.Join(p, rel))
+	})
+}
+
+// sha256Reader returns the hex-encoded SHA-256 sum of everything read from r.
+func sha256Reader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// localDirectoryEntries returns all files under a local directory, sorted by
+// relative path. Relative paths always use forward slashes.
+func localDirectoryEntries(root string) ([]fileEntry, error) {
+	var entries []fileEntry
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fileEntry{rel: filepath.ToSlash(rel), size: fi.Size(), modified: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].rel < entries[j].rel })
+	return entries, nil
+}
+
+// remoteDirectoryEntries returns all files under a datastore directory,
+// sorted by relative path.
+func remoteDirectoryEntries(ds *object.Datastore, root string) ([]fileEntry, error) {
+	results, err := datastore.SearchFiles(ds, root, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSuffix(root, "/")
+	var entries []fileEntry
+	for _, r := range results {
+		var dp object.DatastorePath
+		if !dp.FromString(r.FolderPath) {
+			return nil, fmt.Errorf("could not parse datastore path %q", r.FolderPath)
+		}
+		for _, bfi := range r.File {
+			if _, ok := bfi.(*types.FolderFileInfo); ok {
+				continue
+			}
+			fi := bfi.GetFileInfo()
+			rel := strings.TrimPrefix(path.Join(dp.Path, fi.Path), root+"/")
+			e := fileEntry{rel: rel, size: fi.FileSize}
+			if fi.Modification != nil {
+				e.modified = *fi.Modification
+			}
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].rel < entries[j].rel })
+	return entries, nil
+}
+
+// syncDirectory uploads the contents of a local directory to a datastore
+// directory recursively. If deleteExtraFiles is set, files in the destination
+// that are not present in the source are deleted.
+func syncDirectory(client *govmomi.Client, dc *object.Datacenter, ds *object.Datastore, f *file) error {
+	fm := object.NewFileManager(client.Client)
+	mkdir := func(p string) error {
+		err := fm.MakeDirectory(context.TODO(), ds.Path(p), dc, true)
+		if err != nil && !viapi.IsFileAlreadyExistsError(err) {
+			return err
+		}
+		return nil
+	}
+	if err := mkdir(f.destinationFile); err != nil {
+		return fmt.Errorf("error creating directory %s: %s", ds.Path(f.destinationFile), err)
+	}
+	entries, err := localDirectoryEntries(f.sourceFile)
+	if err != nil {
+		return fmt.Errorf("error reading directory %s: %s", f.sourceFile, err)
+	}
+	local := make(map[string]struct{})
+	created := make(map[string]struct{})
+	for _, e := range entries {
+		local[e.rel] = struct{}{}
+		if dir := path.Dir(e.rel); dir != "." {
+			if _, ok := created[dir]; !ok {
+				if err := mkdir(path.Join(f.destinationFile, dir)); err != nil {
+					return fmt.Errorf("error creating directory %s: %s", ds.Path(path.Join(f.destinationFile, dir)), err)
+				}
+				created[dir] = struct{}{}
+			}
+		}
+		src := filepath.Join(f.sourceFile, filepath.FromSlash(e.rel))
+		dst := path.Join(f.destinationFile, e.rel)
+		log.Printf("[DEBUG] syncDirectory - uploading %s to %s", src, ds.Path(dst))
+		if err := fileUpload(client, dc, ds, src, dst); err != nil {
+			return fmt.Errorf("error uploading %s: %s", src, err)
+		}
+	}
+	if !f.deleteExtraFiles {
+		return nil
+	}
+	remote, err := remoteDirectoryEntries(ds, f.destinationFile)
+	if err != nil {
+		return fmt.Errorf("error listing directory %s: %s", ds.Path(f.destinationFile), err)
+	}
+	for _, e := range remote {
+		if _, ok := local[e.rel]; ok {
+			continue
+		}
+		dst := path.Join(f.destinationFile, e.rel)
+		log.Printf("[DEBUG] syncDirectory - deleting extra file %s", ds.Path(dst))
+		task, err := fm.DeleteDatastoreFile(context.TODO(), ds.Path(dst), dc)
+		if err != nil {
+			return err
+		}
+		if _, err := task.WaitForResult(context.TODO(), nil); err != nil {
+			return fmt.Errorf("error deleting %s: %s", ds.Path(dst), err)
+		}
+	}
+	return nil
+}
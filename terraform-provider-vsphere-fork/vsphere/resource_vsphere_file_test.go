@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -259,6 +260,65 @@ func TestAccResourceVSphereFile_uploadAndCopyAndUpdate(t *testing.T) {
 	os.Remove(sourceFile)
 }
 
+// Directory sync with checksums, including re-upload after a local change and
+// deletion of extra remote files.
+func TestAccResourceVSphereFile_directorySync(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "tf_file_test")
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	defer os.RemoveAll(sourceDir)
+	if err := os.MkdirAll(filepath.Join(sourceDir, "sub"), 0755); err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+	writeFile := func(name, data string) {
+		if err := ioutil.WriteFile(filepath.Join(sourceDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("error %s", err)
+		}
+	}
+	writeFile("ks.cfg", "install\n")
+	writeFile(filepath.Join("sub", "extra.cfg"), "extra\n")
+
+	datacenter := os.Getenv("TF_VAR_VSPHERE_DATACENTER")
+	datastore := os.Getenv("TF_VAR_VSPHERE_NFS_DS_NAME")
+	resourceName := "vsphere_file.dir"
+	destinationDir := "tf_file_test_dir"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccCheckEnvVariables(t, []string{"TF_VAR_VSPHERE_DATACENTER", "TF_VAR_VSPHERE_NFS_DS_NAME"})
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccCheckVSphereFileDirectoryConfig, datacenter, datastore, sourceDir, destinationDir),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationDir+"/ks.cfg", true),
+					testAccCheckVSphereFileExists(resourceName, destinationDir+"/sub/extra.cfg", true),
+					resource.TestCheckResourceAttrSet(resourceName, "checksum"),
+					resource.TestCheckResourceAttrSet(resourceName, "remote_checksum"),
+				),
+			},
+			{
+				PreConfig: func() {
+					writeFile("ks.cfg", "install\nreboot\n")
+					os.Remove(filepath.Join(sourceDir, "sub", "extra.cfg"))
+				},
+				Config: fmt.Sprintf(testAccCheckVSphereFileDirectoryConfig, datacenter, datastore, sourceDir, destinationDir),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationDir+"/ks.cfg", true),
+					testAccCheckVSphereFileExists(resourceName, destinationDir+"/sub/extra.cfg", false),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckVSphereFileDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*VSphereClient).vimClient
 	finder := find.NewFinder(client.Client, true)
@@ -361,3 +421,14 @@ resource "vsphere_file" "%s" {
 	destination_file  = "%s"
 }
 `
+const testAccCheckVSphereFileDirectoryConfig = `
+resource "vsphere_file" "dir" {
+	datacenter         = "%s"
+	datastore          = "%s"
+	source_file        = "%s"
+	destination_file   = "%s"
+	directory          = true
+	delete_extra_files = true
+	checksum_mode      = "sha256"
+}
+`
@@ -0,0 +1,170 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customizationspec"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/vmworkflow"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereGuestOSCustomization() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereGuestOSCustomizationCreate,
+		Read:          resourceVSphereGuestOSCustomizationRead,
+		Update:        resourceVSphereGuestOSCustomizationUpdate,
+		Delete:        resourceVSphereGuestOSCustomizationDelete,
+		CustomizeDiff: resourceVSphereGuestOSCustomizationCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereGuestOSCustomizationImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the customization specification. This is the name used to reference the specification from the clone block of a virtual machine.",
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The guest operating system type the specification applies to. Can be one of Linux or Windows.",
+				ValidateFunc: validation.StringInSlice(customizationspec.TypeAllowedValues, false),
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description for the customization specification.",
+			},
+			"change_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The number of last changed version to the customization specification.",
+			},
+			"last_update_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The time of last modification to the customization specification.",
+			},
+			"spec": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "The guest customization settings stored in the specification.",
+				Elem:        &schema.Resource{Schema: vmworkflow.GuestOSCustomizationSpecSchema()},
+			},
+		},
+	}
+}
+
+func resourceVSphereGuestOSCustomizationCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereGuestOSCustomizationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	item := expandCustomizationSpecItem(d)
+	if err := customizationspec.Create(client, item); err != nil {
+		return fmt.Errorf("error creating customization specification: %s", err)
+	}
+	d.SetId(item.Info.Name)
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereGuestOSCustomizationIDString(d))
+	return resourceVSphereGuestOSCustomizationRead(d, meta)
+}
+
+func resourceVSphereGuestOSCustomizationRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereGuestOSCustomizationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	item, err := customizationspec.FromName(client, d.Id())
+	if err != nil {
+		if customizationspec.IsNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Resource has been deleted", resourceVSphereGuestOSCustomizationIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", item.Info.Name)
+	d.Set("type", item.Info.Type)
+	d.Set("description", item.Info.Description)
+	d.Set("change_version", item.Info.ChangeVersion)
+	if item.Info.LastUpdateTime != nil {
+		d.Set("last_update_time", item.Info.LastUpdateTime.Format(time.RFC3339))
+	}
+	if err := vmworkflow.FlattenGuestOSCustomizationSpec(d, item.Spec); err != nil {
+		return fmt.Errorf("error setting customization specification: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereGuestOSCustomizationIDString(d))
+	return nil
+}
+
+func resourceVSphereGuestOSCustomizationUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereGuestOSCustomizationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if d.HasChange("name") {
+		o, n := d.GetChange("name")
+		if err := customizationspec.Rename(client, o.(string), n.(string)); err != nil {
+			return fmt.Errorf("error renaming customization specification: %s", err)
+		}
+		d.SetId(n.(string))
+	}
+	if d.HasChange("description") || d.HasChange("spec") {
+		if err := customizationspec.Overwrite(client, expandCustomizationSpecItem(d)); err != nil {
+			return fmt.Errorf("error updating customization specification: %s", err)
+		}
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereGuestOSCustomizationIDString(d))
+	return resourceVSphereGuestOSCustomizationRead(d, meta)
+}
+
+func resourceVSphereGuestOSCustomizationDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereGuestOSCustomizationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := customizationspec.Delete(client, d.Id()); err != nil {
+		return fmt.Errorf("error deleting customization specification: %s", err)
+	}
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereGuestOSCustomizationIDString(d))
+	return nil
+}
+
+func resourceVSphereGuestOSCustomizationCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("type") {
+		return nil
+	}
+	return vmworkflow.ValidateGuestOSCustomizationSpec(d, customizationspec.OSFamily(d.Get("type").(string)))
+}
+
+func resourceVSphereGuestOSCustomizationImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	item, err := customizationspec.FromName(client, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(item.Info.Name)
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandCustomizationSpecItem reads the resource configuration and returns a
+// CustomizationSpecItem.
+func expandCustomizationSpecItem(d *schema.ResourceData) types.CustomizationSpecItem {
+	specType := d.Get("type").(string)
+	return types.CustomizationSpecItem{
+		Info: types.CustomizationSpecInfo{
+			Name:        d.Get("name").(string),
+			Description: d.Get("description").(string),
+			Type:        specType,
+		},
+		Spec: vmworkflow.ExpandGuestOSCustomizationSpec(d, customizationspec.OSFamily(specType)),
+	}
+}
+
+// resourceVSphereGuestOSCustomizationIDString prints a friendly string for the
+// vsphere_guest_os_customization resource.
+func resourceVSphereGuestOSCustomizationIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_guest_os_customization")
+}
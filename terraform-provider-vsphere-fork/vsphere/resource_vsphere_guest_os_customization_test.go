@@ -0,0 +1,149 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customizationspec"
+)
+
+func TestAccResourceVSphereGuestOSCustomization_linux(t *testing.T) {
+	name := "testacc-spec-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereGuestOSCustomizationExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereGuestOSCustomizationConfigLinux(name, "example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereGuestOSCustomizationExists(true),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "type", "Linux"),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "spec.0.linux_options.0.domain", "example.com"),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "spec.0.network_interface.0.ipv4_netmask", "24"),
+					resource.TestCheckResourceAttrSet("vsphere_guest_os_customization.spec", "change_version"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereGuestOSCustomizationConfigLinux(name, "example.org"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereGuestOSCustomizationExists(true),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "spec.0.linux_options.0.domain", "example.org"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_guest_os_customization.spec",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     name,
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereGuestOSCustomization_windowsRename(t *testing.T) {
+	name := "testacc-spec-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereGuestOSCustomizationExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereGuestOSCustomizationConfigWindows(name),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereGuestOSCustomizationExists(true),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "type", "Windows"),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "spec.0.windows_options.0.join_domain", "example.com"),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "spec.0.windows_options.0.computer_name", ""),
+				),
+			},
+			{
+				Config: testAccResourceVSphereGuestOSCustomizationConfigWindows(name + "-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereGuestOSCustomizationExists(true),
+					resource.TestCheckResourceAttr("vsphere_guest_os_customization.spec", "name", name+"-renamed"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereGuestOSCustomizationExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_guest_os_customization.spec"]
+		if !ok {
+			if expected {
+				return errors.New("vsphere_guest_os_customization.spec not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		_, err := customizationspec.FromName(client, rs.Primary.ID)
+		if err != nil {
+			if customizationspec.IsNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected customization specification %q to be missing", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereGuestOSCustomizationConfigLinux(name, domain string) string {
+	return fmt.Sprintf(`
+resource "vsphere_guest_os_customization" "spec" {
+  name        = "%s"
+  type        = "Linux"
+  description = "terraform test"
+
+  spec {
+    linux_options {
+      domain    = "%s"
+      time_zone = "UTC"
+    }
+
+    network_interface {
+      ipv4_address = "10.0.0.10"
+      ipv4_netmask = 24
+    }
+
+    ipv4_gateway    = "10.0.0.1"
+    dns_server_list = ["10.0.0.2"]
+  }
+}
+`, name, domain)
+}
+
+func testAccResourceVSphereGuestOSCustomizationConfigWindows(name string) string {
+	return fmt.Sprintf(`
+resource "vsphere_guest_os_customization" "spec" {
+  name = "%s"
+  type = "Windows"
+
+  spec {
+    windows_options {
+      join_domain           = "example.com"
+      domain_admin_user     = "Administrator"
+      domain_admin_password = "VMw4re!"
+    }
+
+    network_interface {}
+  }
+}
+`, name)
+}
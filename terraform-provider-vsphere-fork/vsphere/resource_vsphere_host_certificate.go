@@ -0,0 +1,337 @@
+package vsphere
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+)
+
+func resourceVSphereHostCertificate() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereHostCertificateCreate,
+		Read:          resourceVSphereHostCertificateRead,
+		Update:        resourceVSphereHostCertificateUpdate,
+		Delete:        resourceVSphereHostCertificateDelete,
+		CustomizeDiff: resourceVSphereHostCertificateCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereHostCertificateImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the host.",
+			},
+			"distinguished_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "The distinguished name to use in the certificate signing request. Defaults to the host's name as the common name.",
+				ConflictsWith: []string{"use_ip_address_as_common_name"},
+			},
+			"use_ip_address_as_common_name": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Use the host's IP address instead of its name as the common name of the certificate signing request.",
+			},
+			"csr": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The certificate signing request generated on the host, in PEM format. A new key pair, and with it a new request, is generated when the resource is created, so certificates are installed in two steps: apply without certificate, sign this request with your CA, then set certificate to the result and apply again. Imported resources have no request until the first update, which generates one.",
+			},
+			"certificate": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The signed server certificate to install on the host, in PEM format. It must be issued for the key of csr. A certificate issued for another key, such as the key of a request from before the resource was created, is not installed.",
+				ValidateFunc: validateHostCertificatePEM,
+			},
+			"ca_certificates": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The trusted CA certificates of the host, in PEM format. These replace the host's existing trusted CA certificates.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateHostCertificatePEM,
+				},
+			},
+			"expiry_warning_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				Description:  "Show a change to not_after in plans when the installed certificate expires within this many days. Set to 0 to disable.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"subject": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The subject of the installed certificate.",
+			},
+			"issuer": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The issuer of the installed certificate.",
+			},
+			"not_before": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The start of the validity period of the installed certificate, in RFC3339 format.",
+			},
+			"not_after": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The expiry of the installed certificate, in RFC3339 format.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the installed certificate as reported by the host.",
+			},
+		},
+	}
+}
+
+func resourceVSphereHostCertificateCreate(d *schema.ResourceData, meta interface{}) error {
+	host, err := resourceVSphereHostCertificateHost(d, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(host.Reference().Value)
+	if err := resourceVSphereHostCertificateGenerateCSR(d, host); err != nil {
+		return err
+	}
+	if err := resourceVSphereHostCertificateApply(d, host); err != nil {
+		return err
+	}
+	return resourceVSphereHostCertificateRead(d, meta)
+}
+
+func resourceVSphereHostCertificateRead(d *schema.ResourceData, meta interface{}) error {
+	host, err := resourceVSphereHostCertificateHost(d, meta)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Host not found, marking resource as gone", resourceVSphereHostCertificateIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	info, err := hostsystem.CertificateInfo(host)
+	if err != nil {
+		return fmt.Errorf("error reading certificate info: %s", err)
+	}
+	props, err := hostsystem.Properties(host)
+	if err != nil {
+		return fmt.Errorf("error reading host properties: %s", err)
+	}
+	d.Set("subject", info.Subject)
+	d.Set("issuer", info.Issuer)
+	d.Set("status", info.Status)
+	d.Set("not_before", "")
+	d.Set("not_after", "")
+	if info.NotBefore != nil {
+		d.Set("not_before", info.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if info.NotAfter != nil {
+		d.Set("not_after", info.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	// If the certificate in state is no longer the one installed, clear it so
+	// that it gets installed again.
+	var installed []byte
+	if props.Config != nil {
+		installed = props.Config.Certificate
+	}
+	if cert := d.Get("certificate").(string); cert != "" && !hostCertificateMatches(cert, installed) {
+		log.Printf("[DEBUG] %s: Installed certificate differs from the one in state", resourceVSphereHostCertificateIDString(d))
+		d.Set("certificate", "")
+	}
+
+	if len(d.Get("ca_certificates").([]interface{})) > 0 {
+		certs, err := hostsystem.CACertificates(host)
+		if err != nil {
+			return fmt.Errorf("error listing CA certificates: %s", err)
+		}
+		if !hostCertificatesEqual(structure.SliceInterfacesToStrings(d.Get("ca_certificates").([]interface{})), certs) {
+			d.Set("ca_certificates", certs)
+		}
+	}
+	return nil
+}
+
+func resourceVSphereHostCertificateUpdate(d *schema.ResourceData, meta interface{}) error {
+	host, err := resourceVSphereHostCertificateHost(d, meta)
+	if err != nil {
+		return err
+	}
+	// Imported resources have no request yet, so one is generated on the
+	// first update.
+	if d.Get("csr").(string) == "" {
+		if err := resourceVSphereHostCertificateGenerateCSR(d, host); err != nil {
+			return err
+		}
+	}
+	if err := resourceVSphereHostCertificateApply(d, host); err != nil {
+		return err
+	}
+	return resourceVSphereHostCertificateRead(d, meta)
+}
+
+func resourceVSphereHostCertificateDelete(d *schema.ResourceData, meta interface{}) error {
+	// Certificates cannot be removed from a host, so the installed certificate
+	// is left in place.
+	log.Printf("[DEBUG] %s: Removing from state, installed certificate is left on the host", resourceVSphereHostCertificateIDString(d))
+	d.SetId("")
+	return nil
+}
+
+func resourceVSphereHostCertificateImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("host_system_id", d.Id())
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceVSphereHostCertificateCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	days := d.Get("expiry_warning_days").(int)
+	if d.Id() == "" || days == 0 || d.HasChange("certificate") {
+		return nil
+	}
+	notAfter, err := time.Parse(time.RFC3339, d.Get("not_after").(string))
+	if err != nil {
+		return nil
+	}
+	if time.Until(notAfter) < time.Duration(days)*24*time.Hour {
+		log.Printf("[WARN] %s: Installed certificate expires at %s", resourceVSphereHostCertificateIDString(d), notAfter.Format(time.RFC3339))
+		return d.SetNewComputed("not_after")
+	}
+	return nil
+}
+
+// resourceVSphereHostCertificateIDString prints a friendly string for the
+// vsphere_host_certificate resource.
+func resourceVSphereHostCertificateIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_host_certificate")
+}
+
+func resourceVSphereHostCertificateHost(d *schema.ResourceData, meta interface{}) (*object.HostSystem, error) {
+	client := meta.(*VSphereClient).vimClient
+	return hostsystem.FromID(client, d.Get("host_system_id").(string))
+}
+
+// resourceVSphereHostCertificateGenerateCSR generates a new certificate
+// signing request on the host and saves it to csr. Any certificate in the
+// configuration was issued for an older key and cannot be installed until the
+// new request is signed, so it is cleared from state.
+func resourceVSphereHostCertificateGenerateCSR(d *schema.ResourceData, host *object.HostSystem) error {
+	csr, err := hostsystem.GenerateCertificateSigningRequest(host, d.Get("distinguished_name").(string), d.Get("use_ip_address_as_common_name").(bool))
+	if err != nil {
+		return fmt.Errorf("error generating certificate signing request: %s", err)
+	}
+	d.Set("csr", csr)
+	if cert := d.Get("certificate").(string); cert != "" && !hostCertificateMatchesCSR(cert, csr) {
+		log.Printf("[WARN] %s: certificate was not issued for the key of the new certificate signing request, sign csr and update certificate to install it", resourceVSphereHostCertificateIDString(d))
+		d.Set("certificate", "")
+	}
+	return nil
+}
+
+// resourceVSphereHostCertificateApply installs the CA certificates and the
+// server certificate, if they are new or have changed. CA certificates go
+// first so that the host trusts the chain of the server certificate.
+func resourceVSphereHostCertificateApply(d *schema.ResourceData, host *object.HostSystem) error {
+	if d.HasChange("ca_certificates") {
+		certs := structure.SliceInterfacesToStrings(d.Get("ca_certificates").([]interface{}))
+		if len(certs) > 0 {
+			if err := hostsystem.ReplaceCACertificates(host, certs); err != nil {
+				return fmt.Errorf("error replacing CA certificates: %s", err)
+			}
+		}
+	}
+	if d.HasChange("certificate") {
+		if cert := d.Get("certificate").(string); cert != "" {
+			if !hostCertificateMatchesCSR(cert, d.Get("csr").(string)) {
+				return errors.New("certificate was not issued for the key of csr, sign csr with your CA and set certificate to the result")
+			}
+			if err := hostsystem.InstallServerCertificate(host, cert); err != nil {
+				return fmt.Errorf("error installing certificate: %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseHostCertificatePEM decodes a single PEM encoded certificate.
+func parseHostCertificatePEM(s string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func validateHostCertificatePEM(v interface{}, k string) ([]string, []error) {
+	if _, err := parseHostCertificatePEM(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// hostCertificateMatches returns true if a PEM encoded certificate has the
+// same SHA-256 fingerprint as the certificate installed on the host, which is
+// either PEM or DER encoded.
+func hostCertificateMatches(cert string, installed []byte) bool {
+	c, err := parseHostCertificatePEM(cert)
+	if err != nil || len(installed) == 0 {
+		return false
+	}
+	if block, _ := pem.Decode(installed); block != nil {
+		installed = block.Bytes
+	}
+	return sha256.Sum256(c.Raw) == sha256.Sum256(installed)
+}
+
+// hostCertificateMatchesCSR returns true if a PEM encoded certificate was
+// issued for the public key of a PEM encoded certificate signing request.
+func hostCertificateMatchesCSR(cert string, csr string) bool {
+	c, err := parseHostCertificatePEM(cert)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode([]byte(csr))
+	if block == nil {
+		return false
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(c.RawSubjectPublicKeyInfo, req.RawSubjectPublicKeyInfo)
+}
+
+// hostCertificatesEqual compares two lists of PEM encoded certificates,
+// ignoring surrounding whitespace.
+func hostCertificatesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSpace(a[i]) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
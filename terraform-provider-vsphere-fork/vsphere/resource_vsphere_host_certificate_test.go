@@ -0,0 +1,123 @@
+package vsphere
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+)
+
+func TestAccResourceVSphereHostCertificate_csr(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostCertificateConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("vsphere_host_certificate.cert", "csr", regexp.MustCompile("BEGIN CERTIFICATE REQUEST")),
+					resource.TestCheckResourceAttrSet("vsphere_host_certificate.cert", "subject"),
+					resource.TestCheckResourceAttrSet("vsphere_host_certificate.cert", "not_after"),
+				),
+			},
+		},
+	})
+}
+
+func TestHostCertificateMatches(t *testing.T) {
+	key := testHostCertificateKey(t)
+	cert, der := testHostCertificatePEM(t, key, 1)
+	_, other := testHostCertificatePEM(t, key, 2)
+
+	if _, errs := validateHostCertificatePEM(cert, "certificate"); len(errs) > 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+	if _, errs := validateHostCertificatePEM("not a certificate", "certificate"); len(errs) == 0 {
+		t.Fatal("expected validation error for invalid certificate")
+	}
+	if !hostCertificateMatches(cert, der) {
+		t.Fatal("expected certificate to match installed DER certificate")
+	}
+	if !hostCertificateMatches(cert, []byte(cert)) {
+		t.Fatal("expected certificate to match installed PEM certificate")
+	}
+	if hostCertificateMatches(cert, other) {
+		t.Fatal("expected certificate not to match a certificate with another serial number")
+	}
+	if hostCertificateMatches(cert, nil) {
+		t.Fatal("expected certificate not to match missing certificate")
+	}
+	if !hostCertificatesEqual([]string{cert}, []string{cert + "\n"}) {
+		t.Fatal("expected certificates differing in whitespace to be equal")
+	}
+}
+
+func TestHostCertificateMatchesCSR(t *testing.T) {
+	key := testHostCertificateKey(t)
+	cert, _ := testHostCertificatePEM(t, key, 1)
+	otherCert, _ := testHostCertificatePEM(t, testHostCertificateKey(t), 1)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "esxi.example.com"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+
+	if !hostCertificateMatchesCSR(cert, csr) {
+		t.Fatal("expected certificate to match the key of the request")
+	}
+	if hostCertificateMatchesCSR(otherCert, csr) {
+		t.Fatal("expected certificate for another key not to match the request")
+	}
+	if hostCertificateMatchesCSR(cert, "") {
+		t.Fatal("expected certificate not to match missing request")
+	}
+}
+
+func testHostCertificateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// testHostCertificatePEM returns a self-signed certificate for key, both PEM
+// and DER encoded.
+func testHostCertificatePEM(t *testing.T, key *ecdsa.PrivateKey, serial int64) (string, []byte) {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "esxi.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), der
+}
+
+func testAccResourceVSphereHostCertificateConfig() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_certificate" "cert" {
+  host_system_id = "${data.vsphere_host.roothost1.id}"
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootHost1()),
+	)
+}
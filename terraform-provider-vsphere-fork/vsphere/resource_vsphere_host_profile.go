@@ -0,0 +1,212 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostprofile"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostProfile() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostProfileCreate,
+		Read:   resourceVSphereHostProfileRead,
+		Update: resourceVSphereHostProfileUpdate,
+		Delete: resourceVSphereHostProfileDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the host profile.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The description of the host profile.",
+			},
+			"reference_host_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The managed object ID of the host to extract the profile from. Changing this re-extracts the profile from the new host.",
+			},
+			"host_system_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the hosts to attach to the profile.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"compute_cluster_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the clusters to attach to the profile.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"compliance_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The overall compliance status of the entities attached to the profile, as of the last compliance check.",
+			},
+		},
+	}
+}
+
+func resourceVSphereHostProfileCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereHostProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	host, err := hostsystem.FromID(client, d.Get("reference_host_id").(string))
+	if err != nil {
+		return fmt.Errorf("error locating reference host: %s", err)
+	}
+	id, err := hostprofile.Create(client, d.Get("name").(string), d.Get("description").(string), host)
+	if err != nil {
+		return fmt.Errorf("error creating host profile: %s", err)
+	}
+	d.SetId(id)
+	if err := hostprofile.Associate(client, id, expandHostProfileEntities(d)); err != nil {
+		return fmt.Errorf("error attaching entities to host profile: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereHostProfileIDString(d))
+	return resourceVSphereHostProfileRead(d, meta)
+}
+
+func resourceVSphereHostProfileRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereHostProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	props, err := hostprofile.Properties(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Resource has been deleted", resourceVSphereHostProfileIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", props.Name)
+	if props.Config != nil {
+		d.Set("description", props.Config.GetProfileConfigInfo().Annotation)
+	}
+	if props.ReferenceHost != nil {
+		d.Set("reference_host_id", props.ReferenceHost.Value)
+	}
+	d.Set("compliance_status", props.ComplianceStatus)
+
+	var hosts, clusters []string
+	for _, ref := range props.Entity {
+		switch ref.Type {
+		case "HostSystem":
+			hosts = append(hosts, ref.Value)
+		case "ClusterComputeResource":
+			clusters = append(clusters, ref.Value)
+		}
+	}
+	if err := d.Set("host_system_ids", hosts); err != nil {
+		return fmt.Errorf("error setting host_system_ids: %s", err)
+	}
+	if err := d.Set("compute_cluster_ids", clusters); err != nil {
+		return fmt.Errorf("error setting compute_cluster_ids: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereHostProfileIDString(d))
+	return nil
+}
+
+func resourceVSphereHostProfileUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereHostProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+
+	switch {
+	case d.HasChange("reference_host_id"):
+		host, err := hostsystem.FromID(client, d.Get("reference_host_id").(string))
+		if err != nil {
+			return fmt.Errorf("error locating reference host: %s", err)
+		}
+		if err := hostprofile.UpdateFromHost(client, d.Id(), name, description, host); err != nil {
+			return fmt.Errorf("error updating host profile from reference host: %s", err)
+		}
+	case d.HasChange("name") || d.HasChange("description"):
+		if err := hostprofile.UpdateInfo(client, d.Id(), name, description); err != nil {
+			return fmt.Errorf("error updating host profile: %s", err)
+		}
+	}
+
+	if d.HasChange("host_system_ids") || d.HasChange("compute_cluster_ids") {
+		removed, added := diffHostProfileEntities(d)
+		if err := hostprofile.Dissociate(client, d.Id(), removed); err != nil {
+			return fmt.Errorf("error detaching entities from host profile: %s", err)
+		}
+		if err := hostprofile.Associate(client, d.Id(), added); err != nil {
+			return fmt.Errorf("error attaching entities to host profile: %s", err)
+		}
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereHostProfileIDString(d))
+	return resourceVSphereHostProfileRead(d, meta)
+}
+
+func resourceVSphereHostProfileDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereHostProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := hostprofile.Dissociate(client, d.Id(), expandHostProfileEntities(d)); err != nil {
+		return fmt.Errorf("error detaching entities from host profile: %s", err)
+	}
+	if err := hostprofile.Delete(client, d.Id()); err != nil {
+		return fmt.Errorf("error deleting host profile: %s", err)
+	}
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereHostProfileIDString(d))
+	return nil
+}
+
+// hostProfileEntityRefs converts a list of host and cluster IDs into managed
+// object references.
+func hostProfileEntityRefs(hosts, clusters []interface{}) []types.ManagedObjectReference {
+	var refs []types.ManagedObjectReference
+	for _, id := range structure.SliceInterfacesToStrings(hosts) {
+		refs = append(refs, types.ManagedObjectReference{Type: "HostSystem", Value: id})
+	}
+	for _, id := range structure.SliceInterfacesToStrings(clusters) {
+		refs = append(refs, types.ManagedObjectReference{Type: "ClusterComputeResource", Value: id})
+	}
+	return refs
+}
+
+// expandHostProfileEntities returns the managed object references of all
+// hosts and clusters in the configuration.
+func expandHostProfileEntities(d *schema.ResourceData) []types.ManagedObjectReference {
+	return hostProfileEntityRefs(
+		d.Get("host_system_ids").(*schema.Set).List(),
+		d.Get("compute_cluster_ids").(*schema.Set).List(),
+	)
+}
+
+// diffHostProfileEntities returns the managed object references of hosts and
+// clusters that have been removed from and added to the configuration.
+func diffHostProfileEntities(d *schema.ResourceData) ([]types.ManagedObjectReference, []types.ManagedObjectReference) {
+	oh, nh := d.GetChange("host_system_ids")
+	oc, nc := d.GetChange("compute_cluster_ids")
+	removed := hostProfileEntityRefs(
+		oh.(*schema.Set).Difference(nh.(*schema.Set)).List(),
+		oc.(*schema.Set).Difference(nc.(*schema.Set)).List(),
+	)
+	added := hostProfileEntityRefs(
+		nh.(*schema.Set).Difference(oh.(*schema.Set)).List(),
+		nc.(*schema.Set).Difference(oc.(*schema.Set)).List(),
+	)
+	return removed, added
+}
+
+// resourceVSphereHostProfileIDString prints a friendly string for the
+// vsphere_host_profile resource.
+func resourceVSphereHostProfileIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_host_profile")
+}
@@ -0,0 +1,116 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostprofile"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+)
+
+func TestAccResourceVSphereHostProfile_basic(t *testing.T) {
+	name := "testacc-profile-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereHostProfileExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostProfileConfig(name, "reference profile", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostProfileExists(true),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "name", name),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "description", "reference profile"),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_host_profile.profile", "reference_host_id",
+						"data.vsphere_host.roothost1", "id",
+					),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "host_system_ids.#", "0"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostProfileConfig(name+"-renamed", "attached profile", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostProfileExists(true),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "name", name+"-renamed"),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "description", "attached profile"),
+					resource.TestCheckResourceAttr("vsphere_host_profile.profile", "host_system_ids.#", "1"),
+					resource.TestCheckResourceAttr("data.vsphere_host_profile_compliance.compliance", "results.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_host_profile_compliance.compliance", "results.0.entity_id",
+						"data.vsphere_host.roothost1", "id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_host_profile_compliance.compliance", "compliance_status", "compliant"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_host_profile.profile",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostProfileExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_host_profile.profile"]
+		if !ok {
+			if expected {
+				return errors.New("vsphere_host_profile.profile not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		_, err := hostprofile.Properties(client, rs.Primary.ID)
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected host profile %q to be missing", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostProfileConfig(name, description string, attach bool) string {
+	var hostIDs, compliance string
+	if attach {
+		hostIDs = `host_system_ids = ["${data.vsphere_host.roothost1.id}"]`
+		compliance = `
+data "vsphere_host_profile_compliance" "compliance" {
+  host_profile_id = "${vsphere_host_profile.profile.id}"
+  host_system_ids = ["${data.vsphere_host.roothost1.id}"]
+}
+`
+	}
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_host_profile" "profile" {
+  name              = "%s"
+  description       = "%s"
+  reference_host_id = "${data.vsphere_host.roothost1.id}"
+  %s
+}
+%s
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootHost1()),
+		name,
+		description,
+		hostIDs,
+		compliance,
+	)
+}
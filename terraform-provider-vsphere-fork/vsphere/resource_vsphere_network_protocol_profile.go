@@ -0,0 +1,402 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/ippool"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ipPoolRangeRegexp matches a single IP pool range in start_address#count
+// format.
+var ipPoolRangeRegexp = regexp.MustCompile(`^[0-9a-fA-F.:]+#[0-9]+$`)
+
+func resourceVSphereNetworkProtocolProfile() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereNetworkProtocolProfileCreate,
+		Read:   resourceVSphereNetworkProtocolProfileRead,
+		Update: resourceVSphereNetworkProtocolProfileUpdate,
+		Delete: resourceVSphereNetworkProtocolProfileDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourceVSphereNetworkProtocolProfileCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the network protocol profile.",
+			},
+			"datacenter_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the datacenter to create the network protocol profile in.",
+			},
+			"ipv4": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The IPv4 configuration of the network protocol profile.",
+				Elem:        &schema.Resource{Schema: resourceVSphereNetworkProtocolProfileIPConfigSchema()},
+			},
+			"ipv6": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The IPv6 configuration of the network protocol profile.",
+				Elem:        &schema.Resource{Schema: resourceVSphereNetworkProtocolProfileIPConfigSchema()},
+			},
+			"dns_domain": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The DNS domain of the network protocol profile.",
+			},
+			"dns_search_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The DNS search path of the network protocol profile.",
+			},
+			"host_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The prefix for host names assigned from the network protocol profile.",
+			},
+			"http_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The HTTP proxy to use on the networks of the network protocol profile, in host:port format.",
+			},
+			"network_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the networks associated with the network protocol profile. All networks cannot be removed once set.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"allocated_ipv4_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv4 addresses currently allocated from the network protocol profile.",
+			},
+			"allocated_ipv6_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv6 addresses currently allocated from the network protocol profile.",
+			},
+			"force_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Destroy the network protocol profile even if addresses from it are still allocated.",
+			},
+		},
+	}
+}
+
+// resourceVSphereNetworkProtocolProfileIPConfigSchema returns the schema for
+// the ipv4 and ipv6 blocks of the vsphere_network_protocol_profile resource.
+func resourceVSphereNetworkProtocolProfileIPConfigSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"subnet_address": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The address of the subnet.",
+			ValidateFunc: validation.IsIPAddress,
+		},
+		"netmask": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The netmask of the subnet.",
+		},
+		"gateway": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The gateway of the subnet.",
+			ValidateFunc: validation.IsIPAddress,
+		},
+		"ip_pool_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Allocate addresses to virtual machines and vApps from the ranges of this profile.",
+		},
+		"ranges": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "The address ranges to allocate from, in start_address#count format, such as 10.0.0.10#20.",
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(ipPoolRangeRegexp, "must be in start_address#count format"),
+			},
+		},
+		"dns_servers": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "The DNS servers for the subnet. All DNS servers cannot be removed once set.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"dhcp_server_available": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether a DHCP server is available on the subnet.",
+		},
+	}
+}
+
+func resourceVSphereNetworkProtocolProfileCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereNetworkProtocolProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dcID := d.Get("datacenter_id").(string)
+	dc, err := datacenterFromID(client, dcID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	pool, err := expandIPPool(d, meta)
+	if err != nil {
+		return err
+	}
+	id, err := ippool.Create(client, dc, *pool)
+	if err != nil {
+		return fmt.Errorf("error creating network protocol profile: %s", err)
+	}
+	d.SetId(resourceVSphereNetworkProtocolProfileFlattenID(dcID, id))
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereNetworkProtocolProfileIDString(d))
+	return resourceVSphereNetworkProtocolProfileRead(d, meta)
+}
+
+func resourceVSphereNetworkProtocolProfileRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereNetworkProtocolProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dcID, id, err := resourceVSphereNetworkProtocolProfileParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	dc, err := datacenterFromID(client, dcID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	pool, err := ippool.FromID(client, dc, id)
+	if err != nil {
+		if ippool.IsNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Resource has been deleted", resourceVSphereNetworkProtocolProfileIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	d.Set("datacenter_id", dcID)
+	if err := flattenIPPool(d, pool); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereNetworkProtocolProfileIDString(d))
+	return nil
+}
+
+func resourceVSphereNetworkProtocolProfileUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereNetworkProtocolProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dcID, id, err := resourceVSphereNetworkProtocolProfileParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	dc, err := datacenterFromID(client, dcID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	pool, err := expandIPPool(d, meta)
+	if err != nil {
+		return err
+	}
+	pool.Id = id
+	if err := ippool.Update(client, dc, *pool); err != nil {
+		return fmt.Errorf("error updating network protocol profile: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereNetworkProtocolProfileIDString(d))
+	return resourceVSphereNetworkProtocolProfileRead(d, meta)
+}
+
+// resourceVSphereNetworkProtocolProfileCustomizeDiff rejects the removal of
+// all networks or all DNS servers. These are lists, and UpdateIpPool leaves
+// lists that are empty in the request as they are, so they cannot be cleared
+// in place. Replacing the profile instead would fail for profiles with
+// allocated addresses, so the plan fails.
+func resourceVSphereNetworkProtocolProfileCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if o, n := d.GetChange("network_ids"); d.NewValueKnown("network_ids") && o.(*schema.Set).Len() > 0 && n.(*schema.Set).Len() < 1 {
+		return errors.New("network_ids: all networks cannot be removed from a network protocol profile, recreate the profile instead")
+	}
+	for _, k := range []string{"ipv4", "ipv6"} {
+		o, n := d.GetChange(k)
+		if d.NewValueKnown(k) && ipPoolDNSServersRemoved(o.([]interface{}), n.([]interface{})) {
+			return fmt.Errorf("%s.0.dns_servers: all DNS servers cannot be removed from a network protocol profile, recreate the profile instead", k)
+		}
+	}
+	return nil
+}
+
+// ipPoolDNSServersRemoved returns true if all of the DNS servers of an ipv4
+// or ipv6 block that is kept have been removed.
+func ipPoolDNSServersRemoved(o, n []interface{}) bool {
+	if len(o) < 1 || o[0] == nil || len(n) < 1 || n[0] == nil {
+		return false
+	}
+	om := o[0].(map[string]interface{})
+	nm := n[0].(map[string]interface{})
+	return len(om["dns_servers"].([]interface{})) > 0 && len(nm["dns_servers"].([]interface{})) < 1
+}
+
+func resourceVSphereNetworkProtocolProfileDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereNetworkProtocolProfileIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dcID, id, err := resourceVSphereNetworkProtocolProfileParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	dc, err := datacenterFromID(client, dcID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	if err := ippool.Destroy(client, dc, id, d.Get("force_destroy").(bool)); err != nil {
+		return fmt.Errorf("error destroying network protocol profile: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereNetworkProtocolProfileIDString(d))
+	return nil
+}
+
+// resourceVSphereNetworkProtocolProfileIDString prints a friendly string for
+// the vsphere_network_protocol_profile resource.
+func resourceVSphereNetworkProtocolProfileIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_network_protocol_profile")
+}
+
+// resourceVSphereNetworkProtocolProfileFlattenID makes an ID for the
+// vsphere_network_protocol_profile resource. IP pool IDs are only unique
+// within a datacenter, so the datacenter ID is included.
+func resourceVSphereNetworkProtocolProfileFlattenID(dcID string, id int32) string {
+	return strings.Join([]string{dcID, strconv.Itoa(int(id))}, ":")
+}
+
+// resourceVSphereNetworkProtocolProfileParseID parses an ID for the
+// vsphere_network_protocol_profile resource and outputs its parts.
+func resourceVSphereNetworkProtocolProfileParseID(id string) (string, int32, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) < 2 {
+		return "", 0, fmt.Errorf("bad ID %q, expected datacenter_id:pool_id", id)
+	}
+	poolID, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad pool ID in %q: %s", id, err)
+	}
+	return parts[0], int32(poolID), nil
+}
+
+// expandIPPool reads the vsphere_network_protocol_profile resource data into
+// an IpPool.
+func expandIPPool(d *schema.ResourceData, meta interface{}) (*types.IpPool, error) {
+	client := meta.(*VSphereClient).vimClient
+	pool := &types.IpPool{
+		Name:          d.Get("name").(string),
+		Ipv4Config:    expandIPPoolConfigInfo(d.Get("ipv4").([]interface{})),
+		Ipv6Config:    expandIPPoolConfigInfo(d.Get("ipv6").([]interface{})),
+		DnsDomain:     d.Get("dns_domain").(string),
+		DnsSearchPath: d.Get("dns_search_path").(string),
+		HostPrefix:    d.Get("host_prefix").(string),
+		HttpProxy:     d.Get("http_proxy").(string),
+	}
+	for _, id := range structure.SliceInterfacesToStrings(d.Get("network_ids").(*schema.Set).List()) {
+		net, err := network.FromID(client, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate network %q: %s", id, err)
+		}
+		ref := net.Reference()
+		pool.NetworkAssociation = append(pool.NetworkAssociation, types.IpPoolAssociation{
+			Network: &ref,
+		})
+	}
+	return pool, nil
+}
+
+// expandIPPoolConfigInfo reads an ipv4 or ipv6 block into an
+// IpPoolIpPoolConfigInfo.
+func expandIPPoolConfigInfo(l []interface{}) *types.IpPoolIpPoolConfigInfo {
+	if len(l) < 1 || l[0] == nil {
+		return nil
+	}
+	m := l[0].(map[string]interface{})
+	return &types.IpPoolIpPoolConfigInfo{
+		SubnetAddress:       m["subnet_address"].(string),
+		Netmask:             m["netmask"].(string),
+		Gateway:             m["gateway"].(string),
+		Range:               strings.Join(structure.SliceInterfacesToStrings(m["ranges"].([]interface{})), ", "),
+		Dns:                 structure.SliceInterfacesToStrings(m["dns_servers"].([]interface{})),
+		DhcpServerAvailable: structure.BoolPtr(m["dhcp_server_available"].(bool)),
+		IpPoolEnabled:       structure.BoolPtr(m["ip_pool_enabled"].(bool)),
+	}
+}
+
+// flattenIPPool saves an IpPool into the vsphere_network_protocol_profile
+// resource data.
+func flattenIPPool(d *schema.ResourceData, pool *types.IpPool) error {
+	d.Set("name", pool.Name)
+	d.Set("dns_domain", pool.DnsDomain)
+	d.Set("dns_search_path", pool.DnsSearchPath)
+	d.Set("host_prefix", pool.HostPrefix)
+	d.Set("http_proxy", pool.HttpProxy)
+	d.Set("allocated_ipv4_addresses", pool.AllocatedIpv4Addresses)
+	d.Set("allocated_ipv6_addresses", pool.AllocatedIpv6Addresses)
+	if err := d.Set("ipv4", flattenIPPoolConfigInfo(pool.Ipv4Config)); err != nil {
+		return fmt.Errorf("error setting ipv4: %s", err)
+	}
+	if err := d.Set("ipv6", flattenIPPoolConfigInfo(pool.Ipv6Config)); err != nil {
+		return fmt.Errorf("error setting ipv6: %s", err)
+	}
+	var networkIDs []string
+	for _, assoc := range pool.NetworkAssociation {
+		if assoc.Network != nil {
+			networkIDs = append(networkIDs, assoc.Network.Value)
+		}
+	}
+	if err := d.Set("network_ids", networkIDs); err != nil {
+		return fmt.Errorf("error setting network_ids: %s", err)
+	}
+	return nil
+}
+
+// flattenIPPoolConfigInfo converts an IpPoolIpPoolConfigInfo into an ipv4 or
+// ipv6 block. vSphere returns an empty configuration for protocols that have
+// not been configured, which is flattened to an empty block list.
+func flattenIPPoolConfigInfo(info *types.IpPoolIpPoolConfigInfo) []interface{} {
+	if info == nil || info.SubnetAddress == "" {
+		return nil
+	}
+	var ranges []string
+	for _, r := range strings.Split(info.Range, ",") {
+		if r = strings.Join(strings.Fields(r), ""); r != "" {
+			ranges = append(ranges, r)
+		}
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"subnet_address":        info.SubnetAddress,
+			"netmask":               info.Netmask,
+			"gateway":               info.Gateway,
+			"ip_pool_enabled":       info.IpPoolEnabled != nil && *info.IpPoolEnabled,
+			"ranges":                ranges,
+			"dns_servers":           info.Dns,
+			"dhcp_server_available": info.DhcpServerAvailable != nil && *info.DhcpServerAvailable,
+		},
+	}
+}
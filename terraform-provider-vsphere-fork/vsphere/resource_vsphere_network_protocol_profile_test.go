@@ -0,0 +1,251 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/ippool"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereNetworkProtocolProfile_basic(t *testing.T) {
+	name := "testacc-npp-" + acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereNetworkProtocolProfileExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereNetworkProtocolProfileConfig(name, `["10.10.0.10#10"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereNetworkProtocolProfileExists(true),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "name", name),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "ipv4.0.ranges.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "network_ids.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "allocated_ipv4_addresses", "0"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereNetworkProtocolProfileConfig(name, `["10.10.0.10#10", "10.10.0.50#5"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereNetworkProtocolProfileExists(true),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "ipv4.0.ranges.#", "2"),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "ipv4.0.ranges.1", "10.10.0.50#5"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereNetworkProtocolProfileConfigNoIPv4(name),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereNetworkProtocolProfileExists(true),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "ipv4.#", "0"),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "dns_domain", ""),
+					resource.TestCheckResourceAttr("vsphere_network_protocol_profile.profile", "network_ids.#", "1"),
+				),
+			},
+			{
+				Config:   testAccResourceVSphereNetworkProtocolProfileConfigNoIPv4(name),
+				PlanOnly: true,
+			},
+			{
+				Config:      testAccResourceVSphereNetworkProtocolProfileConfigNoNetworks(name),
+				ExpectError: regexp.MustCompile("all networks cannot be removed"),
+			},
+			{
+				ResourceName:            "vsphere_network_protocol_profile.profile",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"force_destroy"},
+			},
+		},
+	})
+}
+
+func TestIPPoolDNSServersRemoved(t *testing.T) {
+	block := func(dns ...interface{}) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"dns_servers": dns,
+			},
+		}
+	}
+	cases := []struct {
+		name     string
+		old      []interface{}
+		new      []interface{}
+		expected bool
+	}{
+		{
+			name: "added",
+			new:  block("10.10.0.2"),
+		},
+		{
+			name: "unchanged",
+			old:  block("10.10.0.2"),
+			new:  block("10.10.0.2"),
+		},
+		{
+			name: "block removed",
+			old:  block("10.10.0.2"),
+		},
+		{
+			name: "changed",
+			old:  block("10.10.0.2"),
+			new:  block("10.10.0.3"),
+		},
+		{
+			name:     "all removed",
+			old:      block("10.10.0.2"),
+			new:      block(),
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := ipPoolDNSServersRemoved(tc.old, tc.new); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenIPPoolConfigInfo(t *testing.T) {
+	info := &types.IpPoolIpPoolConfigInfo{
+		SubnetAddress: "10.10.0.0",
+		Netmask:       "255.255.255.0",
+		Gateway:       "10.10.0.1",
+		Range:         "10.10.0.10 # 10, 10.10.0.50#5",
+		Dns:           []string{"10.10.0.2"},
+		IpPoolEnabled: structure.BoolPtr(true),
+	}
+	expected := []interface{}{
+		map[string]interface{}{
+			"subnet_address":        "10.10.0.0",
+			"netmask":               "255.255.255.0",
+			"gateway":               "10.10.0.1",
+			"ip_pool_enabled":       true,
+			"ranges":                []string{"10.10.0.10#10", "10.10.0.50#5"},
+			"dns_servers":           []string{"10.10.0.2"},
+			"dhcp_server_available": false,
+		},
+	}
+	if actual := flattenIPPoolConfigInfo(info); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+	if actual := flattenIPPoolConfigInfo(&types.IpPoolIpPoolConfigInfo{}); actual != nil {
+		t.Fatalf("expected empty configuration to flatten to nil, got %#v", actual)
+	}
+}
+
+func TestResourceVSphereNetworkProtocolProfileParseID(t *testing.T) {
+	dcID, id, err := resourceVSphereNetworkProtocolProfileParseID(resourceVSphereNetworkProtocolProfileFlattenID("datacenter-21", 3))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if dcID != "datacenter-21" || id != 3 {
+		t.Fatalf("expected datacenter-21 and 3, got %s and %d", dcID, id)
+	}
+	for _, bad := range []string{"datacenter-21", "datacenter-21:pool"} {
+		if _, _, err := resourceVSphereNetworkProtocolProfileParseID(bad); err == nil {
+			t.Fatalf("expected error for ID %q", bad)
+		}
+	}
+}
+
+func testAccResourceVSphereNetworkProtocolProfileExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_network_protocol_profile.profile"]
+		if !ok {
+			if expected {
+				return errors.New("vsphere_network_protocol_profile.profile not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dcID, id, err := resourceVSphereNetworkProtocolProfileParseID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		dc, err := datacenterFromID(client, dcID)
+		if err != nil {
+			return err
+		}
+		_, err = ippool.FromID(client, dc, id)
+		if err != nil {
+			if ippool.IsNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected network protocol profile %q to be missing", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereNetworkProtocolProfileConfig(name, ranges string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_network_protocol_profile" "profile" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+  dns_domain    = "example.com"
+  network_ids   = ["${data.vsphere_network.network1.id}"]
+
+  ipv4 {
+    subnet_address  = "10.10.0.0"
+    netmask         = "255.255.255.0"
+    gateway         = "10.10.0.1"
+    dns_servers     = ["10.10.0.2"]
+    ip_pool_enabled = true
+    ranges          = %s
+  }
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootPortGroup1()),
+		name,
+		ranges,
+	)
+}
+
+func testAccResourceVSphereNetworkProtocolProfileConfigNoIPv4(name string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_network_protocol_profile" "profile" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+  network_ids   = ["${data.vsphere_network.network1.id}"]
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootPortGroup1()),
+		name,
+	)
+}
+
+func testAccResourceVSphereNetworkProtocolProfileConfigNoNetworks(name string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_network_protocol_profile" "profile" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+}
+`,
+		testhelper.ConfigDataRootDC1(),
+		name,
+	)
+}
@@ -0,0 +1,277 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereTagAssociation() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereTagAssociationCreate,
+		Read:   resourceVSphereTagAssociationRead,
+		Update: resourceVSphereTagAssociationUpdate,
+		Delete: resourceVSphereTagAssociationDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereTagAssociationImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"object_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				Description:   "The managed object ID of the object to tag. Requires object_type.",
+				ConflictsWith: []string{"inventory_path"},
+			},
+			"object_type": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				Description:   "The managed object type of the object to tag, such as HostSystem, Datastore, or Network. Requires object_id.",
+				ConflictsWith: []string{"inventory_path"},
+			},
+			"inventory_path": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "The inventory path of the object to tag, such as /dc1/host/cluster1/esxi1.",
+				ConflictsWith: []string{"object_id", "object_type"},
+			},
+			"tag_ids": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "The IDs of the tags to attach to the object. Tags attached to the object outside of this resource are left alone.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereTagAssociationCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereTagAssociationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return err
+	}
+	obj, err := resourceVSphereTagAssociationObject(client, d)
+	if err != nil {
+		return err
+	}
+	tdp := &tagDiffProcessor{
+		manager:   tm,
+		subject:   obj,
+		newTagIDs: structure.SliceInterfacesToStrings(d.Get("tag_ids").(*schema.Set).List()),
+	}
+	if err := tdp.processAttachOperations(); err != nil {
+		return fmt.Errorf("error attaching tags to object ID %q: %s", obj.Reference().Value, err)
+	}
+	d.SetId(obj.Reference().String())
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereTagAssociationIDString(d))
+	return resourceVSphereTagAssociationRead(d, meta)
+}
+
+func resourceVSphereTagAssociationRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereTagAssociationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return err
+	}
+	ref, err := parseTagAssociationID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := tagAssociationObjectExists(client, ref); err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Tagged object has been deleted", resourceVSphereTagAssociationIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	attached, err := tm.ListAttachedTags(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("error reading tags for object ID %q: %s", ref.Value, err)
+	}
+	// Only track the tags that this resource manages, so that tags attached by
+	// other means do not show up as drift. When nothing is tracked yet, such as
+	// on import, all attached tags are taken.
+	managed := d.Get("tag_ids").(*schema.Set)
+	var ids []string
+	for _, id := range attached {
+		if managed.Len() < 1 || managed.Contains(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	d.Set("object_id", ref.Value)
+	d.Set("object_type", ref.Type)
+	if err := d.Set("tag_ids", ids); err != nil {
+		return fmt.Errorf("error setting tag_ids: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereTagAssociationIDString(d))
+	return nil
+}
+
+func resourceVSphereTagAssociationUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereTagAssociationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return err
+	}
+	ref, err := parseTagAssociationID(d.Id())
+	if err != nil {
+		return err
+	}
+	obj := object.NewReference(client.Client, ref)
+	old, new := d.GetChange("tag_ids")
+	tdp := &tagDiffProcessor{
+		manager:   tm,
+		subject:   obj,
+		oldTagIDs: structure.SliceInterfacesToStrings(old.(*schema.Set).List()),
+		newTagIDs: structure.SliceInterfacesToStrings(new.(*schema.Set).List()),
+	}
+	if err := tdp.processDetachOperations(); err != nil {
+		return fmt.Errorf("error detaching tags from object ID %q: %s", ref.Value, err)
+	}
+	if err := tdp.processAttachOperations(); err != nil {
+		return fmt.Errorf("error attaching tags to object ID %q: %s", ref.Value, err)
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereTagAssociationIDString(d))
+	return resourceVSphereTagAssociationRead(d, meta)
+}
+
+func resourceVSphereTagAssociationDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereTagAssociationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return err
+	}
+	ref, err := parseTagAssociationID(d.Id())
+	if err != nil {
+		return err
+	}
+	tdp := &tagDiffProcessor{
+		manager:   tm,
+		subject:   object.NewReference(client.Client, ref),
+		oldTagIDs: structure.SliceInterfacesToStrings(d.Get("tag_ids").(*schema.Set).List()),
+	}
+	if err := tdp.processDetachOperations(); err != nil {
+		return fmt.Errorf("error detaching tags from object ID %q: %s", ref.Value, err)
+	}
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereTagAssociationIDString(d))
+	return nil
+}
+
+func resourceVSphereTagAssociationImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	id := d.Id()
+	if strings.HasPrefix(id, "/") {
+		obj, err := tagAssociationObjectFromPath(client, id)
+		if err != nil {
+			return nil, err
+		}
+		d.Set("inventory_path", id)
+		d.SetId(obj.Reference().String())
+		return []*schema.ResourceData{d}, nil
+	}
+	ref, err := parseTagAssociationID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tagTypeForObject(object.NewReference(client.Client, ref)); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereTagAssociationObject locates the object to tag from either
+// the inventory path or the object ID and type in the configuration, and
+// validates that it can be tagged.
+func resourceVSphereTagAssociationObject(client *govmomi.Client, d *schema.ResourceData) (object.Reference, error) {
+	if p, ok := d.GetOk("inventory_path"); ok {
+		return tagAssociationObjectFromPath(client, p.(string))
+	}
+	id, idOk := d.GetOk("object_id")
+	t, tOk := d.GetOk("object_type")
+	if !idOk || !tOk {
+		return nil, errors.New("one of inventory_path, or object_id and object_type, must be set")
+	}
+	ref := types.ManagedObjectReference{
+		Type:  t.(string),
+		Value: id.(string),
+	}
+	obj := object.NewReference(client.Client, ref)
+	if _, err := tagTypeForObject(obj); err != nil {
+		return nil, err
+	}
+	if err := tagAssociationObjectExists(client, ref); err != nil {
+		return nil, fmt.Errorf("error locating object %s %q: %s", ref.Type, ref.Value, err)
+	}
+	return obj, nil
+}
+
+// tagAssociationObjectFromPath locates an object by its inventory path and
+// validates that it can be tagged.
+func tagAssociationObjectFromPath(client *govmomi.Client, p string) (object.Reference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := object.NewSearchIndex(client.Client).FindByInventoryPath(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("error locating object at inventory path %q: %s", p, err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("no object found at inventory path %q", p)
+	}
+	if _, err := tagTypeForObject(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// tagAssociationObjectExists checks that a managed object exists by fetching
+// its name. A ManagedObjectNotFound fault is returned if it does not.
+func tagAssociationObjectExists(client *govmomi.Client, ref types.ManagedObjectReference) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var me mo.ManagedEntity
+	return property.DefaultCollector(client.Client).RetrieveOne(ctx, ref, []string{"name"}, &me)
+}
+
+// parseTagAssociationID parses a resource ID in the form Type:ID back into a
+// managed object reference.
+func parseTagAssociationID(id string) (types.ManagedObjectReference, error) {
+	var ref types.ManagedObjectReference
+	if !ref.FromString(id) {
+		return ref, fmt.Errorf("could not parse tag association ID %q, expected Type:ID", id)
+	}
+	return ref, nil
+}
+
+// resourceVSphereTagAssociationIDString prints a friendly string for the
+// vsphere_tag_association resource.
+func resourceVSphereTagAssociationIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_tag_association")
+}
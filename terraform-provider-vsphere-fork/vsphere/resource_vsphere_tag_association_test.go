@@ -0,0 +1,110 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
+)
+
+func TestAccResourceVSphereTagAssociation_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereTagAssociationHasTags(0),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereTagAssociationConfig(`["${vsphere_tag.tag1.id}"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereTagAssociationHasTags(1),
+					resource.TestCheckResourceAttr("vsphere_tag_association.association", "object_type", "HostSystem"),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_tag_association.association", "object_id",
+						"data.vsphere_host.roothost1", "id",
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereTagAssociationConfig(`["${vsphere_tag.tag1.id}", "${vsphere_tag.tag2.id}"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereTagAssociationHasTags(2),
+					resource.TestCheckResourceAttr("vsphere_tag_association.association", "tag_ids.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_tag_association.association",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereTagAssociationHasTags(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["data.vsphere_host.roothost1"]
+		if !ok {
+			if expected == 0 {
+				return nil
+			}
+			return errors.New("data.vsphere_host.roothost1 not found in state")
+		}
+		ref, err := parseTagAssociationID("HostSystem:" + rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		tm, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		ids, err := tm.ListAttachedTags(context.TODO(), ref)
+		if err != nil {
+			return err
+		}
+		if len(ids) != expected {
+			return fmt.Errorf("expected %d tags on host %q, got %d", expected, ref.Value, len(ids))
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereTagAssociationConfig(tagIDs string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_tag_category" "category" {
+  name        = "testacc-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "HostSystem",
+  ]
+}
+
+resource "vsphere_tag" "tag1" {
+  name        = "testacc-tag1"
+  category_id = "${vsphere_tag_category.category.id}"
+}
+
+resource "vsphere_tag" "tag2" {
+  name        = "testacc-tag2"
+  category_id = "${vsphere_tag_category.category.id}"
+}
+
+resource "vsphere_tag_association" "association" {
+  object_id   = "${data.vsphere_host.roothost1.id}"
+  object_type = "HostSystem"
+  tag_ids     = %s
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootHost1()),
+		tagIDs,
+	)
+}
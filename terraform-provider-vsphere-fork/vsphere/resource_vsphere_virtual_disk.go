@@ -11,6 +11,9 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/preflight"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/virtualdisk"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -33,17 +36,19 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualDiskCreate,
 		Read:   resourceVSphereVirtualDiskRead,
+		Update: resourceVSphereVirtualDiskUpdate,
 		Delete: resourceVSphereVirtualDiskDelete,
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereVirtualDiskImport,
 		},
+		CustomizeDiff: resourceVSphereVirtualDiskCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
-			// Size in GB
+			// Size in GB. Disks can be grown in place, but not shrunk.
 			"size": {
-				Type:     schema.TypeInt,
-				Required: true,
-				ForceNew: true, //TODO Can this be optional (resize)?
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "The size of the disk, in GB. The disk can be grown in place, but not shrunk. Disks that are attached to a powered on virtual machine are locked and cannot be grown or converted to another type until the virtual machine is powered off.",
 			},
 
 			// TODO:
@@ -69,10 +74,12 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				ForceNew: true,
 			},
 
+			// thin and lazy disks can be converted to eagerZeroedThick in place. Any
+			// other change forces a new disk, see
+			// resourceVSphereVirtualDiskCustomizeDiff.
 			"type": {
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "eagerZeroedThick",
 				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
 					value := v.(string)
@@ -112,6 +119,39 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+
+			"source_disk": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Description: "An existing virtual disk to copy to the new disk, instead of creating an empty one. The source can be in another datastore or datacenter.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"datacenter": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The datacenter of the source disk. Defaults to the datacenter of the new disk.",
+						},
+						"datastore": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The datastore of the source disk.",
+						},
+						"vmdk_path": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The path of the source disk on its datastore.",
+							ValidateFunc: func(v interface{}, k string) (warns []string, errors []error) {
+								if !strings.HasSuffix(v.(string), ".vmdk") {
+									errors = append(errors, fmt.Errorf("vmdk_path must end with '.vmdk'"))
+								}
+								return
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -182,7 +222,11 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	if src, ok := d.GetOk("source_disk"); ok {
+		err = copyHardDisk(client, src.([]interface{})[0].(map[string]interface{}), dc, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType)
+	} else {
+		err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	}
 	if err != nil {
 		return err
 	}
@@ -193,6 +237,17 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 	return resourceVSphereVirtualDiskRead(d, meta)
 }
 
+// virtualDiskUpdateError wraps an error from an in-place change to a disk.
+// vSphere cannot change a disk that is attached to a powered on virtual
+// machine, as the virtual machine holds a lock on the disk file, so the error
+// says so when that is the cause.
+func virtualDiskUpdateError(op string, diskPath string, err error) error {
+	if viapi.IsFileLockedError(err) {
+		return fmt.Errorf("error %s virtual disk %q: the disk is locked, most likely because it is attached to a powered on virtual machine. Power off the virtual machine and try again: %s", op, diskPath, err)
+	}
+	return fmt.Errorf("error %s virtual disk %q: %s", op, diskPath, err)
+}
+
 func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] Reading virtual disk.")
 	client := meta.(*VSphereClient).vimClient
@@ -234,66 +289,18 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		return err
 	}
 
-	ctx := context.TODO()
-	b, err := ds.Browser(ctx)
+	fileInfo, err := searchForVirtualDisk(ds, vDisk.vmdkPath)
 	if err != nil {
-		return err
-	}
-
-	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
-	// search the datastore manually.
-	spec := types.HostDatastoreBrowserSearchSpec{
-		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
-			CapacityKb: true,
-			DiskType:   true,
-		}}},
-		Details: &types.FileQueryFlags{
-			FileSize:     true,
-			FileType:     true,
-			Modification: true,
-			FileOwner:    types.NewBool(true),
-		},
-		MatchPattern: []string{path.Base(vDisk.vmdkPath)},
-	}
-
-	dsPath := ds.Path(path.Dir(vDisk.vmdkPath))
-	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
-
-	if err != nil {
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
-		return err
-	}
-
-	info, err := task.WaitForResult(context.TODO(), nil)
-	if err != nil {
-		if info == nil || info.Error != nil {
-			_, ok := info.Error.Fault.(*types.FileNotFound)
-			if ok {
-				log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
-				d.SetId("")
-				return nil
-			}
-		}
-
 		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
 		return err
 	}
-
-	res := info.Result.(types.HostDatastoreBrowserSearchResults)
-	log.Printf("[DEBUG] num results: %d", len(res.File))
-	if len(res.File) == 0 {
-		d.SetId("")
+	if fileInfo == nil {
 		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
+		d.SetId("")
 		return nil
 	}
-
-	if len(res.File) != 1 {
-		return errors.New("Datastore search did not return exactly one result")
-	}
-
-	fileInfo := res.File[0]
 	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
-	size := fileInfo.(*types.VmDiskFileInfo).CapacityKb / 1024 / 1024
+	size := fileInfo.CapacityKb / 1024 / 1024
 
 	dp := object.DatastorePath{
 		Datastore: vDisk.datastore,
@@ -311,7 +318,7 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	d.SetId(vDisk.vmdkPath)
 
 	d.Set("size", size)
-	d.Set("type", diskType)
+	d.Set("type", virtualDiskTypeFromProvisioningType(string(diskType)))
 	d.Set("vmdk_path", vDisk.vmdkPath)
 	d.Set("datacenter", d.Get("datacenter"))
 	d.Set("datastore", d.Get("datastore"))
@@ -320,6 +327,48 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 
 }
 
+func resourceVSphereVirtualDiskUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[INFO] Updating Virtual Disk")
+	client := meta.(*VSphereClient).vimClient
+
+	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	if err != nil {
+		return err
+	}
+	finder := find.NewFinder(client.Client, true).SetDatacenter(dc)
+	ds, err := getDatastore(finder, d.Get("datastore").(string))
+	if err != nil {
+		return err
+	}
+	diskPath := ds.Path(d.Get("vmdk_path").(string))
+
+	// Convert the disk type first, so that any added space is provisioned in
+	// the new format.
+	if d.HasChange("type") {
+		o, n := d.GetChange("type")
+		switch {
+		case o == "thin" && n == "eagerZeroedThick":
+			err = virtualdisk.Inflate(client, diskPath, dc)
+		case o == "lazy" && n == "eagerZeroedThick":
+			err = virtualdisk.EagerZero(client, diskPath, dc)
+		default:
+			err = fmt.Errorf("cannot convert virtual disk from %s to %s", o, n)
+		}
+		if err != nil {
+			return virtualDiskUpdateError("converting", diskPath, err)
+		}
+	}
+
+	if d.HasChange("size") {
+		capacityKb := int64(1024 * 1024 * d.Get("size").(int))
+		if err := virtualdisk.Extend(client, diskPath, dc, capacityKb, d.Get("type").(string) == "eagerZeroedThick"); err != nil {
+			return virtualDiskUpdateError("extending", diskPath, err)
+		}
+	}
+
+	return resourceVSphereVirtualDiskRead(d, meta)
+}
+
 func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 
@@ -370,17 +419,32 @@ func isAlreadyExists(err error) bool {
 		strings.HasSuffix(err.Error(), "already exists")
 }
 
-// createHardDisk creates a new Hard Disk.
-func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
-	var vDiskType string
+// virtualDiskProvisioningType converts a type value to a vSphere virtual disk
+// type.
+func virtualDiskProvisioningType(diskType string) string {
 	switch diskType {
 	case "thin":
-		vDiskType = "thin"
+		return "thin"
 	case "eagerZeroedThick":
-		vDiskType = "eagerZeroedThick"
+		return "eagerZeroedThick"
 	case "lazy":
-		vDiskType = "preallocated"
+		return "preallocated"
+	}
+	return ""
+}
+
+// virtualDiskTypeFromProvisioningType converts a vSphere virtual disk type
+// back to a type value. This is the reverse of virtualDiskProvisioningType.
+func virtualDiskTypeFromProvisioningType(diskType string) string {
+	if diskType == "preallocated" {
+		return "lazy"
 	}
+	return diskType
+}
+
+// createHardDisk creates a new Hard Disk.
+func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
+	vDiskType := virtualDiskProvisioningType(diskType)
 
 	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
 	spec := &types.FileBackedVirtualDiskSpec{
@@ -411,6 +475,102 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 	return nil
 }
 
+// copyHardDisk creates a new Hard Disk by copying the disk described by a
+// source_disk block. The copy is extended to size if the source disk is
+// smaller.
+func copyHardDisk(client *govmomi.Client, src map[string]interface{}, dc *object.Datacenter, size int, diskPath string, diskType string, adapterType string) error {
+	srcDC := dc
+	if name := src["datacenter"].(string); name != "" {
+		var err error
+		if srcDC, err = getDatacenter(client, name); err != nil {
+			return fmt.Errorf("Error finding source Datacenter: %s: %s", name, err)
+		}
+	}
+	srcDS, err := getDatastore(find.NewFinder(client.Client, true).SetDatacenter(srcDC), src["datastore"].(string))
+	if err != nil {
+		return fmt.Errorf("Error finding source Datastore: %s: %s", src["datastore"].(string), err)
+	}
+	srcPath := srcDS.Path(src["vmdk_path"].(string))
+
+	info, err := searchForVirtualDisk(srcDS, src["vmdk_path"].(string))
+	if err != nil {
+		return fmt.Errorf("error querying source disk %q: %s", srcPath, err)
+	}
+	if info == nil {
+		return fmt.Errorf("source disk %q not found", srcPath)
+	}
+	capacityKb := int64(1024 * 1024 * size)
+	if info.CapacityKb > capacityKb {
+		return fmt.Errorf("source disk %q is larger than the requested size of %d GB", srcPath, size)
+	}
+
+	spec := &types.VirtualDiskSpec{
+		AdapterType: adapterType,
+		DiskType:    virtualDiskProvisioningType(diskType),
+	}
+	if err := virtualdisk.Copy(client, srcPath, srcDC, diskPath, dc, spec); err != nil {
+		return fmt.Errorf("error copying disk %q: %s", srcPath, err)
+	}
+	if info.CapacityKb < capacityKb {
+		return virtualdisk.Extend(client, diskPath, dc, capacityKb, diskType == "eagerZeroedThick")
+	}
+	return nil
+}
+
+// searchForVirtualDisk searches a datastore for the virtual disk at vmdkPath.
+// nil is returned if the disk does not exist.
+func searchForVirtualDisk(ds *object.Datastore, vmdkPath string) (*types.VmDiskFileInfo, error) {
+	ctx := context.TODO()
+	b, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
+	// search the datastore manually.
+	spec := types.HostDatastoreBrowserSearchSpec{
+		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
+			CapacityKb: true,
+			DiskType:   true,
+		}}},
+		Details: &types.FileQueryFlags{
+			FileSize:     true,
+			FileType:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(true),
+		},
+		MatchPattern: []string{path.Base(vmdkPath)},
+	}
+
+	dsPath := ds.Path(path.Dir(vmdkPath))
+	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := task.WaitForResult(context.TODO(), nil)
+	if err != nil {
+		if info != nil && info.Error != nil {
+			if _, ok := info.Error.Fault.(*types.FileNotFound); ok {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	res := info.Result.(types.HostDatastoreBrowserSearchResults)
+	log.Printf("[DEBUG] num results: %d", len(res.File))
+	if len(res.File) == 0 {
+		return nil, nil
+	}
+
+	if len(res.File) != 1 {
+		return nil, errors.New("Datastore search did not return exactly one result")
+	}
+
+	return res.File[0].(*types.VmDiskFileInfo), nil
+}
+
 // Searches for the presence of a directory path.
 func searchForDirectory(client *govmomi.Client, datacenter string, datastore string, directoryPath string) error {
 	log.Printf("[DEBUG] Searching for Directory")
@@ -483,6 +643,79 @@ func searchForDirectory(client *govmomi.Client, datacenter string, datastore str
 	return nil
 }
 
+// resourceVSphereVirtualDiskCustomizeDiff validates in-place changes to an
+// existing disk, and checks that the datastore has room for a new or grown
+// thick provisioned disk. Failed space checks are handled according to the
+// preflight_checks provider setting.
+func resourceVSphereVirtualDiskCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	required := d.Get("size").(int)
+	replaced, err := resourceVSphereVirtualDiskReplaced(d)
+	if err != nil {
+		return err
+	}
+	if d.Id() != "" && !replaced {
+		o, n := d.GetChange("size")
+		if n.(int) < o.(int) {
+			return fmt.Errorf("virtual disks cannot be shrunk (from %d GB to %d GB)", o.(int), n.(int))
+		}
+		required = n.(int) - o.(int)
+		// A thin disk only uses the space it has written to, so inflating it
+		// allocates the full size of the disk.
+		if o, _ := d.GetChange("type"); d.HasChange("type") && o == "thin" {
+			required = d.Get("size").(int)
+		}
+	}
+
+	mode := meta.(*VSphereClient).preflightChecks
+	if mode == preflight.ModeOff || required <= 0 || d.Get("type").(string) == "thin" {
+		return nil
+	}
+	if !structure.ValuesAvailable("", []string{"datacenter", "datastore", "size"}, d) {
+		log.Printf("[DEBUG] Virtual disk datastore or size depends on a computed value, skipping preflight checks")
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+
+	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	if err != nil {
+		return fmt.Errorf("Error finding Datacenter: %s: %s", d.Get("datacenter").(string), err)
+	}
+	finder := find.NewFinder(client.Client, true).SetDatacenter(dc)
+	ds, err := getDatastore(finder, d.Get("datastore").(string))
+	if err != nil {
+		return fmt.Errorf("Error finding Datastore: %s: %s", d.Get("datastore").(string), err)
+	}
+
+	var errs []error
+	if err := preflight.DatastoreSpace(client, ds.Reference().Value, structure.GiBToByte(required)); err != nil {
+		errs = append(errs, err)
+	}
+	return preflight.Report(mode, d.Get("vmdk_path").(string), errs)
+}
+
+// resourceVSphereVirtualDiskReplaced returns true if the disk is replaced by
+// the diff, either through a change to a ForceNew attribute, or through a type
+// change that cannot be done in place, in which case type is marked as
+// ForceNew. A replaced disk can have any size.
+func resourceVSphereVirtualDiskReplaced(d *schema.ResourceDiff) (bool, error) {
+	if d.Id() == "" {
+		return false, nil
+	}
+	for k, s := range resourceVSphereVirtualDisk().Schema {
+		if s.ForceNew && d.HasChange(k) {
+			return true, nil
+		}
+	}
+	if !d.HasChange("type") {
+		return false, nil
+	}
+	o, n := d.GetChange("type")
+	if n == "eagerZeroedThick" && (o == "thin" || o == "lazy") {
+		return false, nil
+	}
+	return true, d.ForceNew("type")
+}
+
 func resourceVSphereVirtualDiskImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	client := meta.(*VSphereClient).vimClient
 	p := d.Id()
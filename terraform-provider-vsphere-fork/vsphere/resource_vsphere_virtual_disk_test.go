@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/testhelper"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -115,6 +116,84 @@ func TestAccResourceVSphereVirtualDisk_withParent(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualDisk_growAndInflate(t *testing.T) {
+	rString := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualDiskPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_sizeType(rString, 1, "thin"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "1"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_sizeType(rString, 2, "eagerZeroedThick"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "type", "eagerZeroedThick"),
+				),
+			},
+			{
+				Config:      testAccCheckVSphereVirtuaDiskConfig_sizeType(rString, 1, "eagerZeroedThick"),
+				ExpectError: regexp.MustCompile("virtual disks cannot be shrunk"),
+			},
+			{
+				// Converting to lazy replaces the disk, so it can be smaller.
+				Config: testAccCheckVSphereVirtuaDiskConfig_sizeType(rString, 1, "lazy"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "1"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "type", "lazy"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_sizeType(rString, 2, "eagerZeroedThick"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "type", "eagerZeroedThick"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualDisk_sourceDisk(t *testing.T) {
+	rString := acctest.RandString(5)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualDiskPreCheck(t)
+		},
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo", false),
+			testAccVSphereVirtualDiskExists("vsphere_virtual_disk.copy", false),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_sourceDisk(rString),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.copy", true),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.copy", "size", "2"),
+				),
+			},
+		},
+	})
+}
+
 func testAccResourceVSphereVirtualDiskPreCheck(t *testing.T) {
 	if os.Getenv("TF_VAR_VSPHERE_DATACENTER") == "" {
 		t.Skip("set TF_VAR_VSPHERE_DATACENTER to run vsphere_virtual_disk acceptance tests")
@@ -281,3 +360,52 @@ resource "vsphere_virtual_disk" "foo" {
 		rName,
 	)
 }
+
+func testAccCheckVSphereVirtuaDiskConfig_sizeType(rName string, size int, diskType string) string {
+	return fmt.Sprintf(`
+%s
+
+variable "rstring" {
+  default = "%s"
+}
+
+data "vsphere_datastore" "ds" {
+  name          = vsphere_nas_datastore.ds1.name
+  datacenter_id = "${data.vsphere_datacenter.rootdc1.id}"
+}
+
+resource "vsphere_virtual_disk" "foo" {
+  size       = %d
+  vmdk_path  = "tfTestDisk-${var.rstring}.vmdk"
+  type       = "%s"
+  datacenter = "${data.vsphere_datacenter.rootdc1.name}"
+  datastore  = "${data.vsphere_datastore.ds.name}"
+}
+`,
+		testhelper.CombineConfigs(testhelper.ConfigDataRootDC1(), testhelper.ConfigDataRootHost1(), testhelper.ConfigDataRootHost2(), testhelper.ConfigResDS1()),
+		rName,
+		size,
+		diskType,
+	)
+}
+
+func testAccCheckVSphereVirtuaDiskConfig_sourceDisk(rName string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_disk" "copy" {
+  size       = 2
+  vmdk_path  = "tfTestDisk-${var.rstring}-copy.vmdk"
+  type       = "thin"
+  datacenter = "${data.vsphere_datacenter.rootdc1.name}"
+  datastore  = "${data.vsphere_datastore.ds.name}"
+
+  source_disk {
+    datastore = "${vsphere_virtual_disk.foo.datastore}"
+    vmdk_path = "${vsphere_virtual_disk.foo.vmdk_path}"
+  }
+}
+`,
+		testAccCheckVSphereVirtuaDiskConfig_sizeType(rName, 1, "thin"),
+	)
+}
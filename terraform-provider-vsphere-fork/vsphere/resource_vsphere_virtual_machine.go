@@ -7,17 +7,20 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/ovfdeploy"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/preflight"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/utils"
 	//"github.com/roshankarande/utils/helper"
 	"log"
 	"net"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/customizationspec"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
@@ -33,6 +36,7 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -256,8 +260,7 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 		"cdrom": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			Description: "A specification for a CDROM device on this virtual machine.",
-			MaxItems:    1,
+			Description: "A list of CDROM devices on this virtual machine.",
 			Elem:        &schema.Resource{Schema: virtualdevice.CdromSubresourceSchema()},
 			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 				if len(d.Get("ovf_deploy").([]interface{})) > 0 {
@@ -266,6 +269,30 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				return false
 			},
 		},
+		"serial_port": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "A specification for a serial port on this virtual machine. When no serial ports are in configuration, the serial ports of the virtual machine are left as they are.",
+			MaxItems:    32,
+			Elem:        &schema.Resource{Schema: virtualdevice.SerialPortSubresourceSchema()},
+		},
+		"usb_controller": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "A specification for a USB controller on this virtual machine. When no USB controllers are in configuration, the USB controllers of the virtual machine are left as they are.",
+			MaxItems:    2,
+			Elem:        &schema.Resource{Schema: virtualdevice.USBControllerSubresourceSchema()},
+		},
+		"video_card": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "A specification for the video card on this virtual machine. Every virtual machine has a video card, so removing this block leaves the card and its settings as they are.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: virtualdevice.VideoCardSubresourceSchema()},
+		},
 		"pci_device_id": {
 			Type:        schema.TypeSet,
 			Optional:    true,
@@ -286,6 +313,20 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			MaxItems:    1,
 			Elem:        &schema.Resource{Schema: vmworkflow.VirtualMachineOvfDeploySchema()},
 		},
+		"cloud_init": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			MaxItems:      1,
+			ConflictsWith: []string{"clone.0.customize", "clone.0.customization_spec"},
+			Description:   "cloud-init data for the virtual machine, delivered through the guestinfo extraConfig keys read by the VMware guestinfo cloud-init datasource.",
+			Elem:          &schema.Resource{Schema: vmworkflow.VirtualMachineCloudInitSchema()},
+		},
+		"template": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Set to true to mark the virtual machine as a template. Setting this back to false converts the template back to a virtual machine in resource_pool_id. Changes to a template are applied by temporarily converting it to a virtual machine in resource_pool_id. Power management and guest waiters are skipped while the virtual machine is a template.",
+		},
 		"reboot_required": {
 			Type:        schema.TypeBool,
 			Computed:    true,
@@ -312,10 +353,24 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Description: "The machine object ID from VMWare",
 		},
 		"instance_state": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Description: "Instance state of the VM",
-			Default:     "poweredOn",
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "Instance state of the VM",
+			Default:       "poweredOn",
+			Deprecated:    "use power_state instead",
+			ConflictsWith: []string{"power_state"},
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+				// The default only applies when power_state does not keep the
+				// virtual machine powered off or suspended.
+				return new == "poweredOn" && d.Get("power_state").(string) != "" && d.Get("power_state").(string) != virtualMachinePowerStateOn
+			},
+		},
+		"power_state": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The power state of the virtual machine. Can be one of on, off, or suspended. When set, the virtual machine is powered on, shut down, or suspended to match. A virtual machine created with off is not powered on, unless it is cloned with guest customization, which needs it to run once.",
+			ValidateFunc: validation.StringInSlice(resourceVSphereVirtualMachinePowerStateAllowedValues, false),
 		},
 		vSphereTagAttributeKey:    tagsSchema(),
 		customattribute.ConfigKey: customattribute.ConfigSchema(),
@@ -424,37 +479,53 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		}
 	}
 
-	// Wait for guest IP address if we have been set to wait for one
-	err = virtualmachine.WaitForGuestIP(
-		client,
-		vm,
-		d.Get("wait_for_guest_ip_timeout").(int),
-		d.Get("ignored_guest_ips").([]interface{}),
-	)
-	if err != nil {
-		return err
-	}
-
-	// Wait for a routable address if we have been set to wait for one
-	err = virtualmachine.WaitForGuestNet(
-		client,
-		vm,
-		d.Get("wait_for_guest_net_routable").(bool),
-		d.Get("wait_for_guest_net_timeout").(int),
-		d.Get("ignored_guest_ips").([]interface{}),
-	)
-	if err != nil {
-		return err
-	}
+	// If the VM is to be a template, there is no point waiting on the guest.
+	// Convert it now and skip straight to the end.
+	if d.Get("template").(bool) {
+		if err := resourceVSphereVirtualMachineMarkAsTemplate(d, meta, vm); err != nil {
+			return err
+		}
+	} else {
+		// A virtual machine that is meant to be powered off is not powered on
+		// at creation, so there is no guest to wait for.
+		if resourceVSphereVirtualMachinePowerOnAtCreate(d) {
+			// Wait for guest IP address if we have been set to wait for one
+			err = virtualmachine.WaitForGuestIP(
+				client,
+				vm,
+				d.Get("wait_for_guest_ip_timeout").(int),
+				d.Get("ignored_guest_ips").([]interface{}),
+			)
+			if err != nil {
+				return err
+			}
 
-	// <_custom_>
-	if d.Get("instance_state").(string) == "poweredOff" {
-		if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
-			err = virtualmachine.ShutdownGuest(client, vm, 10) // 10 minutes
+			// Wait for a routable address if we have been set to wait for one
+			err = virtualmachine.WaitForGuestNet(
+				client,
+				vm,
+				d.Get("wait_for_guest_net_routable").(bool),
+				d.Get("wait_for_guest_net_timeout").(int),
+				d.Get("ignored_guest_ips").([]interface{}),
+			)
 			if err != nil {
 				return err
 			}
 		}
+
+		// <_custom_>
+		if d.Get("instance_state").(string) == "poweredOff" {
+			if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+				err = virtualmachine.ShutdownGuest(client, vm, 10) // 10 minutes
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := resourceVSphereVirtualMachineConvergePowerState(d, meta, vm); err != nil {
+			return err
+		}
 	}
 
 	// All done!
@@ -511,20 +582,25 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 
 	// <_custom_>
 	d.Set("instance_state", vprops.Runtime.PowerState)
+	d.Set("power_state", flattenVirtualMachinePowerState(vprops.Runtime.PowerState))
 
-	// Resource pool
+	d.Set("template", vprops.Config.Template)
+
+	// Resource pool. Templates do not belong to a resource pool, so the pool in
+	// state is retained for when the template is converted back.
 	if vprops.ResourcePool != nil {
 		d.Set("resource_pool_id", vprops.ResourcePool.Value)
 	}
 	// If the VM is part of a vApp, InventoryPath will point to a host path
 	// rather than a VM path, so this step must be skipped.
 	var vmContainer string
-	if vprops.ParentVApp != nil {
+	switch {
+	case vprops.ParentVApp != nil:
 		vmContainer = vprops.ParentVApp.Value
-	} else {
+	case vprops.ResourcePool != nil:
 		vmContainer = vprops.ResourcePool.Value
 	}
-	if !vappcontainer.IsVApp(client, vmContainer) {
+	if vmContainer == "" || !vappcontainer.IsVApp(client, vmContainer) {
 		f, err := folder.RootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
 		if err != nil {
 			return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
@@ -608,6 +684,18 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	if err := virtualdevice.CdromRefreshOperation(d, client, devices); err != nil {
 		return err
 	}
+	// Serial ports
+	if err := virtualdevice.SerialPortRefreshOperation(d, client, devices); err != nil {
+		return err
+	}
+	// USB controllers
+	if err := virtualdevice.USBControllerRefreshOperation(d, client, devices); err != nil {
+		return err
+	}
+	// Video card
+	if err := virtualdevice.VideoCardRefreshOperation(d, client, devices); err != nil {
+		return err
+	}
 
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsManager(); tagsClient != nil {
@@ -665,7 +753,17 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
 	}
 
-	if d.HasChange("resource_pool_id") {
+	// If the VM is a template and is being converted back to a virtual machine,
+	// do this first so that the rest of the update can proceed as normal. The
+	// VM is placed in resource_pool_id during the conversion.
+	isTemplate := d.Get("template").(bool)
+	if o, _ := d.GetChange("template"); o.(bool) && !isTemplate {
+		if err := resourceVSphereVirtualMachineMarkAsVirtualMachine(d, meta, vm); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("resource_pool_id") && !isTemplate {
 		var rp *object.ResourcePool
 		rp, err = resourcepool.FromID(client, d.Get("resource_pool_id").(string))
 		if err != nil {
@@ -752,15 +850,35 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	}
 
 	devices := object.VirtualDeviceList(vprops.Config.Hardware.Device)
-	if spec.DeviceChange, err = applyVirtualDevices(d, client, devices); err != nil {
+	if spec.DeviceChange, err = applyVirtualDevices(d, client, meta.(*VSphereClient).restClient, devices); err != nil {
 		return err
 	}
+
+	// Templates cannot be reconfigured or migrated. If the VM is staying a
+	// template, stop here if there is nothing else to do. Otherwise, convert it
+	// to a virtual machine for the rest of the update. It is marked as a
+	// template again once the update is done.
+	if o, _ := d.GetChange("template"); o.(bool) && isTemplate {
+		if !changed && len(spec.DeviceChange) < 1 && !d.HasChange("datastore_id") {
+			log.Printf("[DEBUG] %s: Virtual machine is a template with no changes to apply, skipping reconfiguration", resourceVSphereVirtualMachineIDString(d))
+			d.Partial(false)
+			return resourceVSphereVirtualMachineUpdateComplete(d, meta)
+		}
+		log.Printf("[DEBUG] %s: Converting template to virtual machine to apply changes", resourceVSphereVirtualMachineIDString(d))
+		if err := resourceVSphereVirtualMachineMarkAsVirtualMachine(d, meta, vm); err != nil {
+			return err
+		}
+	}
 	// Only carry out the reconfigure if we actually have a change to process.
 	cv := virtualmachine.GetHardwareVersionNumber(vprops.Config.Version)
 	tv := d.Get("hardware_version").(int)
 	if tv > cv {
 		d.Set("reboot_required", true)
 	}
+	if vmworkflow.CloudInitRebootRequired(d) {
+		log.Printf("[DEBUG] %s: cloud-init data has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
 	if changed || len(spec.DeviceChange) > 0 {
 		//Check to see if we need to shutdown the VM for this process.
 		if d.Get("reboot_required").(bool) && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
@@ -839,8 +957,10 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		if err != nil {
 			return fmt.Errorf("error re-fetching VM properties after update: %s", err)
 		}
-		// Power back on the VM, and wait for network if necessary.
-		if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		// Power back on the VM, and wait for network if necessary. This is
+		// skipped if the VM is meant to be powered off, or is to be marked as a
+		// template.
+		if !isTemplate && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn && d.Get("power_state").(string) != virtualMachinePowerStateOff {
 			pTimeoutStr := fmt.Sprintf("%ds", d.Get("poweron_timeout").(int))
 			pTimeout, err := time.ParseDuration(pTimeoutStr)
 			if err != nil {
@@ -893,7 +1013,13 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
-	if d.HasChange("instance_state") {
+	// If the VM is being converted to a template, do so now that all other
+	// changes have been applied.
+	if isTemplate {
+		if err := resourceVSphereVirtualMachineMarkAsTemplate(d, meta, vm); err != nil {
+			return err
+		}
+	} else if d.HasChange("instance_state") {
 		if d.Get("instance_state").(string) == "poweredOff" && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
 			err = virtualmachine.ShutdownGuest(client, vm, 5) // 10 minutes
 		}
@@ -933,6 +1059,18 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		//}
 	}
 
+	if !isTemplate {
+		if err := resourceVSphereVirtualMachineConvergePowerState(d, meta, vm); err != nil {
+			return err
+		}
+	}
+
+	return resourceVSphereVirtualMachineUpdateComplete(d, meta)
+}
+
+// resourceVSphereVirtualMachineUpdateComplete finishes an update, reading
+// back the state of the virtual machine and running any post-update command.
+func resourceVSphereVirtualMachineUpdateComplete(d *schema.ResourceData, meta interface{}) error {
 	// All done with updates.
 	log.Printf("[DEBUG] %s: Update complete", resourceVSphereVirtualMachineIDString(d))
 	//return resourceVSphereVirtualMachineRead(d, meta)
@@ -1019,7 +1157,14 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 		return err
 	}
 	// Only run the reconfigure operation if there's actually disks in the spec.
+	// Templates cannot be reconfigured, so convert back to a virtual machine
+	// first if necessary.
 	if len(spec.DeviceChange) > 0 {
+		if vprops.Config.Template {
+			if err := resourceVSphereVirtualMachineMarkAsVirtualMachine(d, meta, vm); err != nil {
+				return err
+			}
+		}
 		if err := virtualmachine.Reconfigure(vm, spec); err != nil {
 			return fmt.Errorf("error detaching virtual disks: %s", err)
 		}
@@ -1058,6 +1203,14 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		}
 	}
 
+	// Validate serial port and USB controller sub-resources
+	if err := virtualdevice.SerialPortDiffOperation(d, client); err != nil {
+		return err
+	}
+	if err := virtualdevice.USBControllerDiffOperation(d); err != nil {
+		return err
+	}
+
 	if len(d.Get("ovf_deploy").([]interface{})) == 0 && len(d.Get("network_interface").([]interface{})) == 0 {
 		return fmt.Errorf("network_interface parameter is required when not deploying from ovf template")
 
@@ -1155,6 +1308,12 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		}
 	}
 
+	// Validate cloud-init data and flag a replacement if required by the
+	// reapply policy.
+	if err := vmworkflow.CloudInitDiffOperation(d); err != nil {
+		return err
+	}
+
 	// Validate hardware version changes.
 	cv, tv := d.GetChange("hardware_version")
 	virtualmachine.ValidateHardwareVersion(cv.(int), tv.(int))
@@ -1166,6 +1325,12 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		return err
 	}
 
+	// Check the requested capacity and hardware version against the target
+	// inventory.
+	if err := resourceVSphereVirtualMachineCustomizeDiffPreflight(d, meta); err != nil {
+		return err
+	}
+
 	log.Printf("[DEBUG] %s: Diff customization and validation complete", resourceVSphereVirtualMachineIDString(d))
 	return nil
 }
@@ -1183,6 +1348,97 @@ func resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d *schema.R
 	return nil
 }
 
+// resourceVSphereVirtualMachineCustomizeDiffPreflight checks disk sizes
+// against datastore or datastore cluster free space, CPU and memory
+// reservations against the resource pool, and the hardware version against
+// what the target compute resource supports. Failed checks are handled
+// according to the preflight_checks provider setting.
+func resourceVSphereVirtualMachineCustomizeDiffPreflight(d *schema.ResourceDiff, meta interface{}) error {
+	mode := meta.(*VSphereClient).preflightChecks
+	if mode == preflight.ModeOff {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Running preflight checks", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	var errs []error
+
+	if len(d.Get("ovf_deploy").([]interface{})) == 0 && resourceVSphereVirtualMachineDiskValuesAvailable(d) {
+		podID := d.Get("datastore_cluster_id").(string)
+		defaultDatastoreID := podID
+		if podID == "" {
+			defaultDatastoreID = d.Get("datastore_id").(string)
+		}
+		var deltaDisks int
+		if d.Id() == "" && len(d.Get("clone").([]interface{})) > 0 && structure.ValuesAvailable("clone.0.", []string{"template_uuid", "snapshot_name", "snapshot_id"}, d) {
+			var err error
+			if deltaDisks, err = vmworkflow.CloneDeltaDiskCount(d, client); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		o, n := d.GetChange("disk")
+		required := virtualdevice.DiskSpaceRequirements(o.([]interface{}), n.([]interface{}), defaultDatastoreID, deltaDisks)
+		var ids []string
+		for id := range required {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			var err error
+			if id == podID {
+				err = preflight.DatastoreClusterSpace(client, id, required[id])
+			} else {
+				err = preflight.DatastoreSpace(client, id, required[id])
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	poolID := d.Get("resource_pool_id").(string)
+	if poolID != "" && structure.ValuesAvailable("", []string{"resource_pool_id", "cpu_reservation", "memory_reservation"}, d) {
+		ocpu, ncpu := d.GetChange("cpu_reservation")
+		omem, nmem := d.GetChange("memory_reservation")
+		cpu := int64(ncpu.(int))
+		mem := int64(nmem.(int))
+		if d.Id() != "" && !d.HasChange("resource_pool_id") {
+			// The current reservations are already accounted for in the pool.
+			cpu -= int64(ocpu.(int))
+			mem -= int64(omem.(int))
+		}
+		if err := preflight.ResourcePoolReservation(client, poolID, cpu, mem); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	version := d.Get("hardware_version").(int)
+	if poolID != "" && version > 0 && d.HasChange("hardware_version") && structure.ValuesAvailable("", []string{"resource_pool_id", "host_system_id", "hardware_version"}, d) {
+		if err := preflight.HardwareVersion(client, poolID, d.Get("host_system_id").(string), version, d.Id() != ""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return preflight.Report(mode, resourceVSphereVirtualMachineIDString(d), errs)
+}
+
+// resourceVSphereVirtualMachineDiskValuesAvailable returns true if the
+// datastores and sizes of the virtual machine and all of its disks are known.
+// The datastore ID is not needed when a datastore cluster is in use.
+func resourceVSphereVirtualMachineDiskValuesAvailable(d *schema.ResourceDiff) bool {
+	if !d.NewValueKnown("datastore_cluster_id") {
+		return false
+	}
+	if d.Get("datastore_cluster_id").(string) == "" && !d.NewValueKnown("datastore_id") {
+		return false
+	}
+	for i := range d.Get("disk").([]interface{}) {
+		if !structure.ValuesAvailable(fmt.Sprintf("disk.%d.", i), []string{"datastore_id", "size"}, d) {
+			return false
+		}
+	}
+	return true
+}
+
 func datastoreClusterDiffOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
 	if !structure.ValuesAvailable("", []string{"datastore_cluster_id", "datastore_id"}, d) {
 		log.Printf("[DEBUG] DatastoreClusterDiffOperation: datastore_id or datastore_cluster_id value depends on a computed value from another resource. Skipping validation.")
@@ -1379,7 +1635,7 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	}
 	log.Printf("[DEBUG] Default devices: %s", virtualdevice.DeviceListString(devices))
 
-	if spec.DeviceChange, err = applyVirtualDevices(d, client, devices); err != nil {
+	if spec.DeviceChange, err = applyVirtualDevices(d, client, meta.(*VSphereClient).restClient, devices); err != nil {
 		return nil, err
 	}
 
@@ -1409,9 +1665,11 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse poweron_timeout as a valid duration: %s", err)
 	}
-	// Start the virtual machine
-	if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
-		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+	// Start the virtual machine, unless it is meant to be powered off
+	if resourceVSphereVirtualMachinePowerOnAtCreate(d) {
+		if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
+			return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+		}
 	}
 	return vm, nil
 }
@@ -1608,9 +1866,11 @@ func resourceVsphereMachineDeployOvfAndOva(d *schema.ResourceData, meta interfac
 			return nil, fmt.Errorf("error while applying vapp config %s", err)
 		}
 	}
-	// Start the virtual machine
-	if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
-		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+	// Start the virtual machine, unless it is meant to be powered off
+	if resourceVSphereVirtualMachinePowerOnAtCreate(d) {
+		if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
+			return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+		}
 	}
 	return vm, nil
 }
@@ -1638,6 +1898,7 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	// Start the clone
 	name := d.Get("name").(string)
 	timeout := d.Get("clone.0.timeout").(int)
+	instant := d.Get("clone.0.instant_clone").(bool)
 	var vm *object.VirtualMachine
 	if contentlibrary.IsContentLibraryItem(meta.(*VSphereClient).restClient, d.Get("clone.0.template_uuid").(string)) {
 		// Clone source is an item from a Content Library. Prepare required resources.
@@ -1684,6 +1945,17 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		// There is not currently a way to pull config values from Content Library items. If we do not send the values,
 		// the defaults from the template will be used.
 		d.Set("guest_id", "")
+	} else if instant {
+		// Expand the instant clone spec. The source VM must be running, and the
+		// new VM is powered on when the clone completes.
+		cloneSpec, srcVM, err := vmworkflow.ExpandVirtualMachineInstantCloneSpec(d, client, fo)
+		if err != nil {
+			return nil, err
+		}
+		vm, err = virtualmachine.InstantClone(client, srcVM, cloneSpec, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("error instant cloning virtual machine: %s", err)
+		}
 	} else {
 		// Expand the clone spec. We get the source VM here too.
 		cloneSpec, srcVM, err := vmworkflow.ExpandVirtualMachineCloneSpec(d, client)
@@ -1722,7 +1994,17 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	// configuration of the newly cloned VM. This is basically a subset of update
 	// with the stipulation that there is currently no state to help move this
 	// along.
-	cfgSpec, err := expandVirtualMachineConfigSpec(d, client)
+	//
+	// Instant clones are already running, so the configuration is compared
+	// against the inherited configuration of the source and only sent if it
+	// differs.
+	var cfgSpec types.VirtualMachineConfigSpec
+	cfgChanged := true
+	if instant {
+		cfgSpec, cfgChanged, err = expandVirtualMachineConfigSpecChanged(d, client, vprops.Config)
+	} else {
+		cfgSpec, err = expandVirtualMachineConfigSpec(d, client)
+	}
 	if err != nil {
 		return nil, resourceVSphereVirtualMachineRollbackCreate(
 			d,
@@ -1771,7 +2053,7 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// CDROM
-	devices, delta, err = virtualdevice.CdromPostCloneOperation(d, client, devices)
+	devices, delta, err = virtualdevice.CdromPostCloneOperation(d, client, meta.(*VSphereClient).restClient, devices)
 	if err != nil {
 		return nil, resourceVSphereVirtualMachineRollbackCreate(
 			d,
@@ -1781,13 +2063,49 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Serial ports
+	devices, delta, err = virtualdevice.SerialPortPostCloneOperation(d, client, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing serial port changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// USB controllers
+	devices, delta, err = virtualdevice.USBControllerPostCloneOperation(d, client, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing USB controller changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Video card
+	devices, delta, err = virtualdevice.VideoCardPostCloneOperation(d, client, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing video card changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(devices))
 	log.Printf("[DEBUG] %s: Final device change cfgSpec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(cfgSpec.DeviceChange))
 
 	// Perform updates
-	if _, ok := d.GetOk("datastore_cluster_id"); ok {
+	switch {
+	case !cfgChanged && len(cfgSpec.DeviceChange) < 1:
+		log.Printf("[DEBUG] %s: No post-clone configuration changes, skipping reconfigure", resourceVSphereVirtualMachineIDString(d))
+	case d.Get("datastore_cluster_id").(string) != "":
 		err = resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(d, meta, vm, cfgSpec)
-	} else {
+	default:
 		err = virtualmachine.Reconfigure(vm, cfgSpec)
 	}
 	if err != nil {
@@ -1807,22 +2125,46 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	// This should only change if deploying from a Content Library item.
 	d.Set("guest_id", vmprops.Config.GuestId)
 
-	// Upgrade the VM's hardware version if needed.
-	err = virtualmachine.SetHardwareVersion(vm, d.Get("hardware_version").(int))
-	if err != nil {
-		return nil, err
+	// Upgrade the VM's hardware version if needed. This cannot be done on a
+	// running instant clone.
+	if !instant {
+		err = virtualmachine.SetHardwareVersion(vm, d.Get("hardware_version").(int))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var cw *virtualMachineCustomizationWaiter
-	// Send customization spec if any has been defined.
-	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
+	// Send customization spec if any has been defined, either inline or as a
+	// reference to a spec stored in vCenter.
+	var custSpec *types.CustomizationSpec
+	var custTimeout int
+	switch {
+	case len(d.Get("clone.0.customize").([]interface{})) > 0:
 		family, err := resourcepool.OSFamily(client, pool, d.Get("guest_id").(string))
 		if err != nil {
 			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
 		}
-		custSpec := vmworkflow.ExpandCustomizationSpec(d, family)
-		cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get("clone.0.customize.0.timeout").(int))
-		if err := virtualmachine.Customize(vm, custSpec); err != nil {
+		spec := vmworkflow.ExpandCustomizationSpec(d, family)
+		custSpec = &spec
+		custTimeout = d.Get("clone.0.customize.0.timeout").(int)
+	case len(d.Get("clone.0.customization_spec").([]interface{})) > 0:
+		name := d.Get("clone.0.customization_spec.0.name").(string)
+		item, err := customizationspec.FromName(client, name)
+		if err != nil {
+			return nil, resourceVSphereVirtualMachineRollbackCreate(
+				d,
+				meta,
+				vm,
+				fmt.Errorf("error fetching customization specification %q: %s", name, err),
+			)
+		}
+		custSpec = &item.Spec
+		custTimeout = d.Get("clone.0.customization_spec.0.timeout").(int)
+	}
+	if custSpec != nil {
+		cw = newVirtualMachineCustomizationWaiter(client, vm, custTimeout)
+		if err := virtualmachine.Customize(vm, *custSpec); err != nil {
 			// Roll back the VMs as per the error handling in reconfigure.
 			if derr := resourceVSphereVirtualMachineDelete(d, meta); derr != nil {
 				return nil, fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, err, derr)
@@ -1831,10 +2173,14 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 			return nil, fmt.Errorf("error sending customization spec: %s", err)
 		}
 	}
-	// Finally time to power on the virtual machine!
-	pTimeout := time.Duration(d.Get("poweron_timeout").(int)) * time.Second
-	if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
-		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+	// Finally time to power on the virtual machine! Instant clones are already
+	// running. Virtual machines that are meant to be powered off are left off,
+	// unless they need to run guest customization first.
+	if !instant && (resourceVSphereVirtualMachinePowerOnAtCreate(d) || cw != nil) {
+		pTimeout := time.Duration(d.Get("poweron_timeout").(int)) * time.Second
+		if err := virtualmachine.PowerOn(vm, pTimeout); err != nil {
+			return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+		}
 	}
 	// If we customized, wait on customization.
 	if cw != nil {
@@ -1905,6 +2251,57 @@ func resourceVSphereVirtualMachineRollbackCreate(
 	return fmt.Errorf("error reconfiguring virtual machine: %s", origErr)
 }
 
+// resourceVSphereVirtualMachineMarkAsTemplate powers off a virtual machine,
+// gracefully if possible, and converts it to a template.
+func resourceVSphereVirtualMachineMarkAsTemplate(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] %s: Converting virtual machine to template", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	if vprops.Config.Template {
+		return nil
+	}
+	if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		timeout := d.Get("shutdown_wait_timeout").(int)
+		force := d.Get("force_power_off").(bool)
+		if err := virtualmachine.GracefulPowerOff(client, vm, timeout, force); err != nil {
+			return fmt.Errorf("error shutting down virtual machine: %s", err)
+		}
+	}
+	if err := virtualmachine.MarkAsTemplate(vm); err != nil {
+		return fmt.Errorf("error marking virtual machine as template: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineMarkAsVirtualMachine converts a template back
+// to a virtual machine, placing it in resource_pool_id and on host_system_id
+// if one is set.
+func resourceVSphereVirtualMachineMarkAsVirtualMachine(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] %s: Converting template to virtual machine", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	var hs *object.HostSystem
+	if hsID := d.Get("host_system_id").(string); hsID != "" {
+		if hs, err = hostsystem.FromID(client, hsID); err != nil {
+			return fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+		}
+		if err := resourcepool.ValidateHost(client, pool, hs); err != nil {
+			return err
+		}
+	}
+	if err := virtualmachine.MarkAsVirtualMachine(vm, pool, hs); err != nil {
+		return fmt.Errorf("error marking template as virtual machine: %s", err)
+	}
+	return nil
+}
+
 // resourceVSphereVirtualMachineUpdateLocation manages vMotion. This includes
 // the migration of a VM from one host to another, or from one datastore to
 // another (storage vMotion).
@@ -2022,7 +2419,7 @@ func resourceVSphereVirtualMachineUpdateLocationRelocateWithSDRS(
 
 // applyVirtualDevices is used by Create and Update to build a list of virtual
 // device changes.
-func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, rc *rest.Client, l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
 	// We filter this device list through each major device class' apply
 	// operation. This will give us a final set of changes that will be our
 	// deviceChange attribute.
@@ -2051,7 +2448,25 @@ func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 	}
 	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
 	// CDROM
-	l, delta, err = virtualdevice.CdromApplyOperation(d, c, l)
+	l, delta, err = virtualdevice.CdromApplyOperation(d, c, rc, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Serial ports
+	l, delta, err = virtualdevice.SerialPortApplyOperation(d, c, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// USB controllers
+	l, delta, err = virtualdevice.USBControllerApplyOperation(d, c, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Video card
+	l, delta, err = virtualdevice.VideoCardApplyOperation(d, c, l)
 	if err != nil {
 		return nil, err
 	}
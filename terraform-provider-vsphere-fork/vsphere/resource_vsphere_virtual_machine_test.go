@@ -918,6 +918,53 @@ func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_templateLifecycle(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			RunSweepers()
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigTemplate(false, 2, 2048),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTemplate(false),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigTemplate(true, 2, 2048),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckTemplate(true),
+				),
+			},
+			{
+				// Editing a template converts it to a virtual machine for the
+				// reconfiguration and back again.
+				Config: testAccResourceVSphereVirtualMachineConfigTemplate(true, 4, 4096),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckTemplate(true),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 4096),
+				),
+			},
+			{
+				Config:   testAccResourceVSphereVirtualMachineConfigTemplate(true, 4, 4096),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigTemplate(false, 4, 4096),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckTemplate(false),
+					testAccResourceVSphereVirtualMachineCheckPowerState(types.VirtualMachinePowerStatePoweredOn),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -2856,6 +2903,21 @@ func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem in
 	}
 }
 
+// testAccResourceVSphereVirtualMachineCheckTemplate checks to make sure a
+// virtual machine is, or is not, marked as a template.
+func testAccResourceVSphereVirtualMachineCheckTemplate(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if actual := props.Config.Template; actual != expected {
+			return fmt.Errorf("expected template to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
 // testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
 // machine's primary NIC has the given IP address and netmask assigned to it,
 // and that the appropriate gateway is present.
@@ -4541,6 +4603,42 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigTemplate(template bool, cpus, memory int) string {
+	return fmt.Sprintf(`
+
+
+%s  // Mix and match config
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "testacc-test"
+  resource_pool_id = vsphere_resource_pool.pool1.id
+  datastore_id     = vsphere_nas_datastore.ds1.id
+  template         = %t
+
+  num_cpus = %d
+  memory   = %d
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = 0
+
+  network_interface {
+    network_id = data.vsphere_network.network1.id
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+
+		testAccResourceVSphereVirtualMachineConfigBase(),
+		template,
+		cpus,
+		memory,
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigMaxNIC() string {
 	return fmt.Sprintf(`
 
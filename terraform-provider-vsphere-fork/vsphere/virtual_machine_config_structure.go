@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/vmworkflow"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -825,6 +826,10 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 	if err != nil {
 		return types.VirtualMachineConfigSpec{}, err
 	}
+	cloudInitConfig, err := vmworkflow.ExpandCloudInitExtraConfig(d)
+	if err != nil {
+		return types.VirtualMachineConfigSpec{}, err
+	}
 
 	obj := types.VirtualMachineConfigSpec{
 		Name:                         d.Get("name").(string),
@@ -842,7 +847,7 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 		CpuAllocation:                expandVirtualMachineResourceAllocation(d, "cpu"),
 		MemoryAllocation:             expandVirtualMachineResourceAllocation(d, "memory"),
 		MemoryReservationLockedToMax: getMemoryReservationLockedToMax(d),
-		ExtraConfig:                  expandExtraConfig(d),
+		ExtraConfig:                  append(expandExtraConfig(d), cloudInitConfig...),
 		SwapPlacement:                getWithRestart(d, "swap_placement_policy").(string),
 		BootOptions:                  expandVirtualMachineBootOptions(d, client),
 		VAppConfig:                   vappConfig,
@@ -895,6 +900,9 @@ func flattenVirtualMachineConfigInfo(d *schema.ResourceData, obj *types.VirtualM
 	if err := flattenExtraConfig(d, obj.ExtraConfig); err != nil {
 		return err
 	}
+	if err := vmworkflow.FlattenCloudInit(d, obj.ExtraConfig); err != nil {
+		return err
+	}
 	if err := flattenVAppConfig(d, obj.VAppConfig); err != nil {
 		return err
 	}
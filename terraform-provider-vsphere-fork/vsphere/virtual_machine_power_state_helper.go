@@ -0,0 +1,237 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/vappcontainer"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	virtualMachinePowerStateOn        = "on"
+	virtualMachinePowerStateOff       = "off"
+	virtualMachinePowerStateSuspended = "suspended"
+)
+
+// resourceVSphereVirtualMachinePowerStateAllowedValues are the valid values
+// for the power_state attribute of the vsphere_virtual_machine resource.
+var resourceVSphereVirtualMachinePowerStateAllowedValues = []string{
+	virtualMachinePowerStateOn,
+	virtualMachinePowerStateOff,
+	virtualMachinePowerStateSuspended,
+}
+
+// flattenVirtualMachinePowerState converts a vSphere power state into a
+// power_state value.
+func flattenVirtualMachinePowerState(state types.VirtualMachinePowerState) string {
+	switch state {
+	case types.VirtualMachinePowerStatePoweredOn:
+		return virtualMachinePowerStateOn
+	case types.VirtualMachinePowerStateSuspended:
+		return virtualMachinePowerStateSuspended
+	}
+	return virtualMachinePowerStateOff
+}
+
+// The actions that virtualMachinePowerStateActions can return.
+const (
+	virtualMachinePowerActionPowerOn      = "power on"
+	virtualMachinePowerActionShutdown     = "shut down"
+	virtualMachinePowerActionPowerOff     = "power off"
+	virtualMachinePowerActionSuspend      = "suspend"
+	virtualMachinePowerActionWaitForGuest = "wait for guest"
+)
+
+// virtualMachinePowerStateActions returns the actions needed to take a
+// virtual machine from the current power_state to the desired one, in the
+// order they need to be carried out. Nothing needs to be done if desired is
+// empty.
+//
+// A suspended virtual machine is powered on to resume it, and a suspended
+// virtual machine cannot be shut down from the guest, so it is powered off.
+func virtualMachinePowerStateActions(current, desired string) []string {
+	if desired == "" || current == desired {
+		return nil
+	}
+	switch desired {
+	case virtualMachinePowerStateOff:
+		if current == virtualMachinePowerStateSuspended {
+			return []string{virtualMachinePowerActionPowerOff}
+		}
+		return []string{virtualMachinePowerActionShutdown}
+	case virtualMachinePowerStateSuspended:
+		if current == virtualMachinePowerStateOn {
+			return []string{virtualMachinePowerActionSuspend}
+		}
+		return []string{virtualMachinePowerActionPowerOn, virtualMachinePowerActionSuspend}
+	}
+	return []string{virtualMachinePowerActionPowerOn, virtualMachinePowerActionWaitForGuest}
+}
+
+// resourceVSphereVirtualMachinePowerOnAtCreate returns false if the virtual
+// machine is meant to be powered off, in which case it is not powered on when
+// it is created.
+func resourceVSphereVirtualMachinePowerOnAtCreate(d *schema.ResourceData) bool {
+	return d.Get("power_state").(string) != virtualMachinePowerStateOff
+}
+
+// resourceVSphereVirtualMachineConvergePowerState brings the power state of
+// the virtual machine in line with the power_state attribute. Nothing is done
+// if power_state is not set.
+//
+// Power-offs attempt a graceful guest shutdown first, falling back to a hard
+// power-off if force_power_off is set. If the virtual machine is a member of
+// a vApp, the stop action of its vApp entity is used instead, and the start
+// order of the vApp is respected by waiting for the entities that start
+// before (or stop after) this virtual machine.
+func resourceVSphereVirtualMachineConvergePowerState(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine) error {
+	desired := d.Get("power_state").(string)
+	if desired == "" {
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	current := flattenVirtualMachinePowerState(vprops.Runtime.PowerState)
+	actions := virtualMachinePowerStateActions(current, desired)
+	if len(actions) < 1 {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Changing power state from %q to %q: %s", resourceVSphereVirtualMachineIDString(d), current, desired, strings.Join(actions, ", "))
+	entity, peers, err := resourceVSphereVirtualMachineVAppEntities(client, vprops)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		switch action {
+		case virtualMachinePowerActionShutdown:
+			resourceVSphereVirtualMachineWaitForVAppPeers(d, client, entity, peers, false)
+			if entity != nil && entity.StopAction == string(types.VAppAutoStartActionPowerOff) {
+				err = virtualmachine.PowerOff(vm)
+			} else {
+				err = virtualmachine.GracefulPowerOff(client, vm, d.Get("shutdown_wait_timeout").(int), d.Get("force_power_off").(bool))
+			}
+			if err != nil {
+				return fmt.Errorf("error powering off virtual machine: %s", err)
+			}
+		case virtualMachinePowerActionPowerOff:
+			resourceVSphereVirtualMachineWaitForVAppPeers(d, client, entity, peers, false)
+			if err := virtualmachine.PowerOff(vm); err != nil {
+				return fmt.Errorf("error powering off virtual machine: %s", err)
+			}
+		case virtualMachinePowerActionPowerOn:
+			resourceVSphereVirtualMachineWaitForVAppPeers(d, client, entity, peers, true)
+			if err := virtualmachine.PowerOn(vm, time.Duration(d.Get("poweron_timeout").(int))*time.Second); err != nil {
+				return fmt.Errorf("error powering on virtual machine: %s", err)
+			}
+		case virtualMachinePowerActionSuspend:
+			if err := virtualmachine.Suspend(vm); err != nil {
+				return fmt.Errorf("error suspending virtual machine: %s", err)
+			}
+		case virtualMachinePowerActionWaitForGuest:
+			err = virtualmachine.WaitForGuestIP(
+				client,
+				vm,
+				d.Get("wait_for_guest_ip_timeout").(int),
+				d.Get("ignored_guest_ips").([]interface{}),
+			)
+			if err != nil {
+				return err
+			}
+			err = virtualmachine.WaitForGuestNet(
+				client,
+				vm,
+				d.Get("wait_for_guest_net_routable").(bool),
+				d.Get("wait_for_guest_net_timeout").(int),
+				d.Get("ignored_guest_ips").([]interface{}),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineVAppEntities returns the vApp entity
+// configuration for a virtual machine, along with the configuration of the
+// other virtual machines in the same vApp. nil is returned if the virtual
+// machine is not in a vApp, or has no entity configuration.
+func resourceVSphereVirtualMachineVAppEntities(client *govmomi.Client, vprops *mo.VirtualMachine) (*types.VAppEntityConfigInfo, []types.VAppEntityConfigInfo, error) {
+	if vprops.ParentVApp == nil || vprops.ParentVApp.Type != "VirtualApp" {
+		return nil, nil, nil
+	}
+	vc, err := vappcontainer.FromID(client, vprops.ParentVApp.Value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error locating vApp container: %s", err)
+	}
+	props, err := vappcontainer.Properties(vc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching vApp container properties: %s", err)
+	}
+	if props.VAppConfig == nil {
+		return nil, nil, nil
+	}
+	var entity *types.VAppEntityConfigInfo
+	var peers []types.VAppEntityConfigInfo
+	for _, e := range props.VAppConfig.EntityConfig {
+		if e.Key == nil || e.Key.Type != "VirtualMachine" {
+			continue
+		}
+		if e.Key.Value == vprops.Reference().Value {
+			entity = &types.VAppEntityConfigInfo{}
+			*entity = e
+			continue
+		}
+		peers = append(peers, e)
+	}
+	return entity, peers, nil
+}
+
+// resourceVSphereVirtualMachineWaitForVAppPeers waits for the vApp entities
+// that are ordered before a virtual machine to reach their target power
+// state. When starting, this is the entities with a lower start order that
+// participate in auto-start. When stopping, this is the entities with a
+// higher start order that participate in auto-stop, as vApps stop in reverse
+// order.
+//
+// As with the vApp itself, each peer is waited on for at most its start or
+// stop delay. Peers that do not reach their power state in time are logged and
+// otherwise ignored, as they may not be managed in the same run.
+func resourceVSphereVirtualMachineWaitForVAppPeers(d *schema.ResourceData, client *govmomi.Client, entity *types.VAppEntityConfigInfo, peers []types.VAppEntityConfigInfo, start bool) {
+	if entity == nil {
+		return
+	}
+	for _, peer := range peers {
+		var states []types.VirtualMachinePowerState
+		var timeout time.Duration
+		switch {
+		case start && peer.StartOrder < entity.StartOrder && peer.StartAction == string(types.VAppAutoStartActionPowerOn):
+			states = []types.VirtualMachinePowerState{types.VirtualMachinePowerStatePoweredOn}
+			timeout = time.Duration(peer.StartDelay) * time.Second
+		case !start && peer.StartOrder > entity.StartOrder && peer.StopAction != string(types.VAppAutoStartActionNone):
+			states = []types.VirtualMachinePowerState{types.VirtualMachinePowerStatePoweredOff, types.VirtualMachinePowerStateSuspended}
+			timeout = time.Duration(peer.StopDelay) * time.Second
+		default:
+			continue
+		}
+		if timeout <= 0 {
+			continue
+		}
+		log.Printf("[DEBUG] %s: Waiting for vApp entity %q (start order %d) before continuing", resourceVSphereVirtualMachineIDString(d), peer.Key.Value, peer.StartOrder)
+		if err := virtualmachine.WaitForPowerState(client, *peer.Key, states, timeout); err != nil {
+			log.Printf("[WARN] %s: Continuing without vApp entity %q: %s", resourceVSphereVirtualMachineIDString(d), peer.Key.Value, err)
+		}
+	}
+}
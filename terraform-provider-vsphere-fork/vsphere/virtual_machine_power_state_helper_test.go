@@ -0,0 +1,89 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestFlattenVirtualMachinePowerState(t *testing.T) {
+	cases := []struct {
+		state    types.VirtualMachinePowerState
+		expected string
+	}{
+		{state: types.VirtualMachinePowerStatePoweredOn, expected: virtualMachinePowerStateOn},
+		{state: types.VirtualMachinePowerStatePoweredOff, expected: virtualMachinePowerStateOff},
+		{state: types.VirtualMachinePowerStateSuspended, expected: virtualMachinePowerStateSuspended},
+		{state: "", expected: virtualMachinePowerStateOff},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.state), func(t *testing.T) {
+			if actual := flattenVirtualMachinePowerState(tc.state); actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVirtualMachinePowerStateActions(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  string
+		desired  string
+		expected []string
+	}{
+		{
+			name:    "not set",
+			current: virtualMachinePowerStateOff,
+		},
+		{
+			name:    "already on",
+			current: virtualMachinePowerStateOn,
+			desired: virtualMachinePowerStateOn,
+		},
+		{
+			name:     "off to on",
+			current:  virtualMachinePowerStateOff,
+			desired:  virtualMachinePowerStateOn,
+			expected: []string{virtualMachinePowerActionPowerOn, virtualMachinePowerActionWaitForGuest},
+		},
+		{
+			name:     "suspended to on",
+			current:  virtualMachinePowerStateSuspended,
+			desired:  virtualMachinePowerStateOn,
+			expected: []string{virtualMachinePowerActionPowerOn, virtualMachinePowerActionWaitForGuest},
+		},
+		{
+			name:     "on to off",
+			current:  virtualMachinePowerStateOn,
+			desired:  virtualMachinePowerStateOff,
+			expected: []string{virtualMachinePowerActionShutdown},
+		},
+		{
+			name:     "suspended to off",
+			current:  virtualMachinePowerStateSuspended,
+			desired:  virtualMachinePowerStateOff,
+			expected: []string{virtualMachinePowerActionPowerOff},
+		},
+		{
+			name:     "on to suspended",
+			current:  virtualMachinePowerStateOn,
+			desired:  virtualMachinePowerStateSuspended,
+			expected: []string{virtualMachinePowerActionSuspend},
+		},
+		{
+			name:     "off to suspended",
+			current:  virtualMachinePowerStateOff,
+			desired:  virtualMachinePowerStateSuspended,
+			expected: []string{virtualMachinePowerActionPowerOn, virtualMachinePowerActionSuspend},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := virtualMachinePowerStateActions(tc.current, tc.desired); !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}